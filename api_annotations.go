@@ -299,16 +299,23 @@ func UserSchema() *OpenAPISchema {
 	}, "id", "name", "email")
 }
 
-// PaginationMetaSchema creates pagination metadata schema
+// PaginationMetaSchema creates pagination metadata schema, matching the meta
+// written by Paginator.Respond and CursorPaginator.Respond
 func PaginationMetaSchema() *OpenAPISchema {
 	return ObjectSchema(map[string]*OpenAPISchema{
-		"current_page": IntSchema(),
-		"per_page":     IntSchema(),
-		"total":        IntSchema(),
-		"total_pages":  IntSchema(),
-		"has_next":     BoolSchema(),
-		"has_prev":     BoolSchema(),
-	})
+		"current_page":   IntSchema(),
+		"per_page":       IntSchema(),
+		"total":          IntSchema(),
+		"total_pages":    IntSchema(),
+		"has_next":       BoolSchema(),
+		"has_prev":       BoolSchema(),
+		"next_cursor":    StringSchema(),
+		"prev_cursor":    StringSchema(),
+		"first_page_url": StringSchema(),
+		"last_page_url":  StringSchema(),
+		"next_page_url":  StringSchema(),
+		"prev_page_url":  StringSchema(),
+	}, "per_page", "has_next", "has_prev")
 }
 
 // ListResponseSchema creates a paginated list response schema
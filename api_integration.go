@@ -265,7 +265,10 @@ func APIResponseMiddleware() MiddlewareFunc {
 		}
 		
 		// Add request ID for tracing
-		requestID := c.Header("X-Request-ID")
+		requestID := c.RequestID()
+		if requestID == "" {
+			requestID = c.Header("X-Request-ID")
+		}
 		if requestID == "" {
 			requestID = fmt.Sprintf("%d", time.Now().UnixNano())
 		}
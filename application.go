@@ -1,16 +1,21 @@
 package onyx
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/onyx-go/framework/internal/health"
 	httpInternal "github.com/onyx-go/framework/internal/http"
 	contextImpl "github.com/onyx-go/framework/internal/http/context"
+	"github.com/onyx-go/framework/internal/http/middleware/maintenance"
+	"github.com/onyx-go/framework/internal/http/middleware/tracecontext"
 	routerImpl "github.com/onyx-go/framework/internal/http/router"
 )
 
@@ -25,36 +30,36 @@ type Router struct {
 }
 
 // Router wrapper methods for backward compatibility
-func (r *Router) Get(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.GET(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Get(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.GET(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Post(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.POST(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Post(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.POST(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Put(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.PUT(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Put(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.PUT(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Delete(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.DELETE(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Delete(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.DELETE(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Patch(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.PATCH(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Patch(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.PATCH(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Options(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.OPTIONS(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Options(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.OPTIONS(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Head(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.HEAD(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Head(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.HEAD(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
-func (r *Router) Any(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.ANY(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
+func (r *Router) Any(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.ANY(pattern, convertHandler(handler), convertMiddleware(middleware...)...)
 }
 
 func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *RouteGroup {
@@ -71,6 +76,13 @@ func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *RouteGroup
 	return wrapRouteGroup(internalGroup)
 }
 
+// Domain creates a route group matching only hosts that match pattern, such
+// as "{tenant}.example.com". Host parameters are read with c.Param.
+func (r *Router) Domain(pattern string, middleware ...MiddlewareFunc) *RouteGroup {
+	internalGroup := r.Router.Domain(pattern, convertMiddleware(middleware...)...).(*routerImpl.RouteGroup)
+	return wrapRouteGroup(internalGroup)
+}
+
 // RouteGroup wrapper  
 type RouteGroup struct {
 	*routerImpl.RouteGroup
@@ -80,7 +92,7 @@ type RouteGroup struct {
 type Route = httpInternal.Route
 
 // RouteGroup compatibility methods
-func (rg *RouteGroup) Get(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (rg *RouteGroup) Get(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
 	// Convert handler and middleware to internal types
 	internalHandler := func(c httpInternal.Context) error {
 		return handler(c.(*contextImpl.Context))
@@ -94,10 +106,10 @@ func (rg *RouteGroup) Get(pattern string, handler HandlerFunc, middleware ...Mid
 		internalMiddleware = append(internalMiddleware, internalMw)
 	}
 	
-	rg.RouteGroup.Get(pattern, internalHandler, internalMiddleware...)
+	return rg.RouteGroup.Get(pattern, internalHandler, internalMiddleware...)
 }
 
-func (rg *RouteGroup) Post(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (rg *RouteGroup) Post(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
 	// Convert handler and middleware to internal types
 	internalHandler := func(c httpInternal.Context) error {
 		return handler(c.(*contextImpl.Context))
@@ -111,10 +123,10 @@ func (rg *RouteGroup) Post(pattern string, handler HandlerFunc, middleware ...Mi
 		internalMiddleware = append(internalMiddleware, internalMw)
 	}
 	
-	rg.RouteGroup.Post(pattern, internalHandler, internalMiddleware...)
+	return rg.RouteGroup.Post(pattern, internalHandler, internalMiddleware...)
 }
 
-func (rg *RouteGroup) Put(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (rg *RouteGroup) Put(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
 	// Convert handler and middleware to internal types
 	internalHandler := func(c httpInternal.Context) error {
 		return handler(c.(*contextImpl.Context))
@@ -128,10 +140,10 @@ func (rg *RouteGroup) Put(pattern string, handler HandlerFunc, middleware ...Mid
 		internalMiddleware = append(internalMiddleware, internalMw)
 	}
 	
-	rg.RouteGroup.Put(pattern, internalHandler, internalMiddleware...)
+	return rg.RouteGroup.Put(pattern, internalHandler, internalMiddleware...)
 }
 
-func (rg *RouteGroup) Delete(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (rg *RouteGroup) Delete(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
 	// Convert handler and middleware to internal types
 	internalHandler := func(c httpInternal.Context) error {
 		return handler(c.(*contextImpl.Context))
@@ -145,10 +157,10 @@ func (rg *RouteGroup) Delete(pattern string, handler HandlerFunc, middleware ...
 		internalMiddleware = append(internalMiddleware, internalMw)
 	}
 	
-	rg.RouteGroup.Delete(pattern, internalHandler, internalMiddleware...)
+	return rg.RouteGroup.Delete(pattern, internalHandler, internalMiddleware...)
 }
 
-func (rg *RouteGroup) Patch(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (rg *RouteGroup) Patch(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
 	// Convert handler and middleware to internal types
 	internalHandler := func(c httpInternal.Context) error {
 		return handler(c.(*contextImpl.Context))
@@ -162,7 +174,7 @@ func (rg *RouteGroup) Patch(pattern string, handler HandlerFunc, middleware ...M
 		internalMiddleware = append(internalMiddleware, internalMw)
 	}
 	
-	rg.RouteGroup.PATCH(pattern, internalHandler, internalMiddleware...)
+	return rg.RouteGroup.PATCH(pattern, internalHandler, internalMiddleware...)
 }
 
 // Initialize the prefix field when creating RouteGroup wrappers
@@ -176,9 +188,22 @@ func wrapRouteGroup(internal *routerImpl.RouteGroup) *RouteGroup {
 type Application struct {
 	router         httpInternal.Router
 	server         *http.Server
+	redirectServer *http.Server
+	certReloader   *certReloader
 	config         *Config
 	container      *Container
 	templateEngine *TemplateEngine
+	serverConfig   *ServerConfig
+	
+	lifecycleMutex sync.Mutex
+	startingHooks  []LifecycleHook
+	stoppingHooks  []LifecycleHook
+	shutdownOnce   sync.Once
+	shutdownErr    error
+	
+	health         *health.Registry
+	healthMutex    sync.RWMutex
+	healthPrefixes []string
 }
 
 func New() *Application {
@@ -187,6 +212,7 @@ func New() *Application {
 		router:    r,
 		config:    NewConfig(),
 		container: NewContainer(),
+		health:    health.NewRegistry(),
 	}
 	
 	r.SetApplication(app)
@@ -233,7 +259,12 @@ func New() *Application {
 	SetupErrorHandling(false) // Set to true for debug mode
 	
 	// Use internal middleware directly since they already use the correct interface
+	app.router.Use(tracecontext.Middleware())
 	app.router.Use(LoggerMiddleware())
+	app.router.Use(maintenance.Middleware(maintenance.Config{
+		Path:       MaintenancePath,
+		ExceptFunc: app.isHealthPath,
+	}))
 	app.router.Use(RecoveryMiddleware())
 	
 	// Convert old-style error middleware to new interface
@@ -246,17 +277,15 @@ func New() *Application {
 	return app
 }
 
+// Start runs the starting hooks and serves HTTP on address until the server
+// is shut down. It does not handle signals; use Run for graceful shutdown.
 func (app *Application) Start(address string) error {
-	app.server = &http.Server{
-		Addr:         address,
-		Handler:      app.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	listener, err := app.listen(context.Background(), address)
+	if err != nil {
+		return err
 	}
 	
-	fmt.Printf("🚀 Onyx server starting on %s\n", address)
-	return app.server.ListenAndServe()
+	return app.serve(listener)
 }
 
 func (app *Application) Config() *Config {
@@ -269,44 +298,64 @@ func (app *Application) Container() *Container {
 
 func (app *Application) SetTemplateEngine(viewsPath, layoutsPath string) error {
 	app.templateEngine = NewTemplateEngine(viewsPath, layoutsPath)
+	app.ShareRoutes(app.templateEngine)
 	return app.templateEngine.LoadTemplates()
 }
 
+// FunctionRegistrar is implemented by template renderers that accept custom functions
+type FunctionRegistrar interface {
+	AddFunction(name string, fn interface{})
+}
+
+// ShareRoutes registers the "route" function for named route URLs on each renderer,
+// e.g. the view engine or a mail manager
+func (app *Application) ShareRoutes(renderers ...FunctionRegistrar) {
+	routeFunc := app.router.(*routerImpl.Router).RouteFunc()
+	for _, renderer := range renderers {
+		renderer.AddFunction("route", routeFunc)
+	}
+}
+
+// URL generates the URL for a named route
+func (app *Application) URL(name string, params map[string]string, query url.Values) (string, error) {
+	return app.router.URL(name, params, query)
+}
+
 func (app *Application) GetTemplateEngine() *TemplateEngine {
 	return app.templateEngine
 }
 
 // Router delegation methods
-func (app *Application) GET(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.GET(pattern, handler, middleware...)
+func (app *Application) GET(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.GET(pattern, handler, middleware...)
 }
 
-func (app *Application) POST(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.POST(pattern, handler, middleware...)
+func (app *Application) POST(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.POST(pattern, handler, middleware...)
 }
 
-func (app *Application) PUT(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.PUT(pattern, handler, middleware...)
+func (app *Application) PUT(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.PUT(pattern, handler, middleware...)
 }
 
-func (app *Application) DELETE(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.DELETE(pattern, handler, middleware...)
+func (app *Application) DELETE(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.DELETE(pattern, handler, middleware...)
 }
 
-func (app *Application) PATCH(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.PATCH(pattern, handler, middleware...)
+func (app *Application) PATCH(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.PATCH(pattern, handler, middleware...)
 }
 
-func (app *Application) OPTIONS(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.OPTIONS(pattern, handler, middleware...)
+func (app *Application) OPTIONS(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.OPTIONS(pattern, handler, middleware...)
 }
 
-func (app *Application) HEAD(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.HEAD(pattern, handler, middleware...)
+func (app *Application) HEAD(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.HEAD(pattern, handler, middleware...)
 }
 
-func (app *Application) ANY(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.ANY(pattern, handler, middleware...)
+func (app *Application) ANY(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.ANY(pattern, handler, middleware...)
 }
 
 func (app *Application) Use(middleware ...httpInternal.MiddlewareFunc) {
@@ -465,70 +514,80 @@ func (app *Application) Group(prefix string, middleware ...httpInternal.Middlewa
 	return app.router.Group(prefix, middleware...)
 }
 
+// Domain creates a route group matching only hosts that match pattern, such
+// as "api.example.com" or "{tenant}.example.com"
+func (app *Application) Domain(pattern string, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteGroup {
+	return app.router.Domain(pattern, middleware...)
+}
+
 func (app *Application) SetNotFound(handler httpInternal.HandlerFunc) {
 	app.router.SetNotFound(handler)
 }
 
+func (app *Application) SetMethodNotAllowed(handler httpInternal.HandlerFunc) {
+	app.router.SetMethodNotAllowed(handler)
+}
+
 func (app *Application) GetRoutes() []httpInternal.Route {
 	return app.router.GetRoutes()
 }
 
 // Backward compatibility methods with lowercase names
-func (app *Application) Get(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.GET(pattern, handler, middleware...)
+func (app *Application) Get(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.GET(pattern, handler, middleware...)
 }
 
-func (app *Application) Post(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.POST(pattern, handler, middleware...)
+func (app *Application) Post(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.POST(pattern, handler, middleware...)
 }
 
-func (app *Application) Put(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.PUT(pattern, handler, middleware...)
+func (app *Application) Put(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.PUT(pattern, handler, middleware...)
 }
 
-func (app *Application) Delete(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.DELETE(pattern, handler, middleware...)
+func (app *Application) Delete(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.DELETE(pattern, handler, middleware...)
 }
 
-func (app *Application) Patch(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.PATCH(pattern, handler, middleware...)
+func (app *Application) Patch(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.PATCH(pattern, handler, middleware...)
 }
 
-func (app *Application) Options(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	app.router.OPTIONS(pattern, handler, middleware...)
+func (app *Application) Options(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.OPTIONS(pattern, handler, middleware...)
 }
 
 // Handlers with old-style function signatures for backward compatibility
-func (app *Application) GetHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.Get(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) GetHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.Get(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) PostHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.Post(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) PostHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.Post(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) PutHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.Put(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) PutHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.Put(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) DeleteHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.Delete(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) DeleteHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.Delete(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) PatchHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.Patch(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) PatchHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.Patch(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) OptionsHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.Options(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) OptionsHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.Options(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) HeadHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.HEAD(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) HeadHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.HEAD(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
-func (app *Application) AnyHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	app.ANY(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
+func (app *Application) AnyHandler(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.ANY(pattern, app.convertHandler(handler), app.convertMiddleware(middleware...)...)
 }
 
 // Helper functions to convert between old and new function types
@@ -624,6 +683,7 @@ func (a *HTTPErrorHandlerAdapter) Handle(ctx httpInternal.Context, err error) {
 	var message string
 	var httpErr *HTTPError
 	
+	err = httpErrorFrom(err)
 	if he, ok := err.(*HTTPError); ok {
 		httpErr = he
 		statusCode = he.Code
@@ -704,6 +764,7 @@ func LoggerMiddleware() httpInternal.MiddlewareFunc {
 			"duration_ms":  duration.Milliseconds(),
 			"status_code":  status,
 		}
+		addTraceContext(logContext, c.Request().Context())
 		
 		// Log at different levels based on status code
 		message := fmt.Sprintf("%s %s", c.Method(), c.URL())
@@ -735,6 +796,7 @@ func RecoveryMiddleware() httpInternal.MiddlewareFunc {
 					"user_agent": c.Header("User-Agent"),
 					"remote_ip":  c.RemoteIP(),
 				}
+				addTraceContext(panicContext, c.Request().Context())
 				
 				Fatal("Panic recovered in request handler", panicContext)
 				
@@ -802,7 +864,7 @@ func (app *Application) Schedule() *Schedule {
 	}
 
 	schedule := NewSchedule(logger.(Logger), queueManager)
-	app.container.Singleton("scheduler", schedule)
+	app.container.Instance("scheduler", schedule)
 	return schedule
 }
 
@@ -815,6 +877,19 @@ func (app *Application) StartScheduler() error {
 	return fmt.Errorf("scheduler not configured")
 }
 
+// Workers returns the application's queue worker pool, registered in the
+// container as "queue.workers". Workers started through it are stopped by
+// Shutdown before the stopping hooks run.
+func (app *Application) Workers() *WorkerPool {
+	if workers, err := app.container.Make("queue.workers"); err == nil {
+		return workers.(*WorkerPool)
+	}
+
+	workers := NewWorkerPool()
+	app.container.Instance("queue.workers", workers)
+	return workers
+}
+
 // StopScheduler stops the task scheduler gracefully
 func (app *Application) StopScheduler() error {
 	schedule := app.Schedule()
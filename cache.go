@@ -2,13 +2,18 @@ package onyx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 type Cache interface {
@@ -48,11 +53,14 @@ type MemoryCache struct {
 	items map[string]*CacheItem
 	mutex sync.RWMutex
 	tags  []string
+	done  chan struct{}
+	once  sync.Once
 }
 
 func NewMemoryCache() *MemoryCache {
 	cache := &MemoryCache{
 		items: make(map[string]*CacheItem),
+		done:  make(chan struct{}),
 	}
 	
 	go cache.cleanup()
@@ -63,18 +71,45 @@ func (mc *MemoryCache) cleanup() {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 	
-	for range ticker.C {
-		mc.mutex.Lock()
-		for key, item := range mc.items {
-			if item.IsExpired() {
-				delete(mc.items, key)
+	for {
+		select {
+		case <-ticker.C:
+			mc.mutex.Lock()
+			for key, item := range mc.items {
+				if item.IsExpired() {
+					delete(mc.items, key)
+				}
 			}
+			mc.mutex.Unlock()
+		case <-mc.done:
+			return
 		}
-		mc.mutex.Unlock()
 	}
 }
 
+// Close stops the background expiry sweep
+func (mc *MemoryCache) Close() error {
+	if mc.done != nil {
+		mc.once.Do(func() { close(mc.done) })
+	}
+	return nil
+}
+
 func (mc *MemoryCache) Get(key string) (interface{}, error) {
+	return mc.GetContext(context.Background(), key)
+}
+
+// GetContext gets a value like Get, recording a span in the trace of ctx
+func (mc *MemoryCache) GetContext(ctx context.Context, key string) (interface{}, error) {
+	_, span := startCacheSpan(ctx, "cache.get", "memory", key)
+	defer span.End()
+
+	value, err := mc.get(key)
+	span.SetAttribute("cache.hit", err == nil)
+	return value, err
+}
+
+func (mc *MemoryCache) get(key string) (interface{}, error) {
 	mc.mutex.RLock()
 	defer mc.mutex.RUnlock()
 	
@@ -92,6 +127,18 @@ func (mc *MemoryCache) Get(key string) (interface{}, error) {
 }
 
 func (mc *MemoryCache) Put(key string, value interface{}, duration time.Duration) error {
+	return mc.PutContext(context.Background(), key, value, duration)
+}
+
+// PutContext stores a value like Put, recording a span in the trace of ctx
+func (mc *MemoryCache) PutContext(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	_, span := startCacheSpan(ctx, "cache.put", "memory", key)
+	defer span.End()
+
+	return mc.put(key, value, duration)
+}
+
+func (mc *MemoryCache) put(key string, value interface{}, duration time.Duration) error {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 	
@@ -267,11 +314,22 @@ func NewFileCache(path string) *FileCache {
 }
 
 func (fc *FileCache) Put(key string, value interface{}, duration time.Duration) error {
-	if err := fc.MemoryCache.Put(key, value, duration); err != nil {
+	return fc.PutContext(context.Background(), key, value, duration)
+}
+
+// PutContext stores a value like Put, recording a span in the trace of ctx
+func (fc *FileCache) PutContext(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	_, span := startCacheSpan(ctx, "cache.put", "file", key)
+	defer span.End()
+
+	if err := fc.MemoryCache.put(key, value, duration); err != nil {
+		span.RecordError(err)
 		return err
 	}
 	
-	return fc.persistToFile(key, value, duration)
+	err := fc.persistToFile(key, value, duration)
+	span.RecordError(err)
+	return err
 }
 
 func (fc *FileCache) Forever(key string, value interface{}) error {
@@ -299,7 +357,21 @@ func (fc *FileCache) persistToFile(key string, value interface{}, duration time.
 }
 
 func (fc *FileCache) Get(key string) (interface{}, error) {
-	if value, err := fc.MemoryCache.Get(key); err == nil {
+	return fc.GetContext(context.Background(), key)
+}
+
+// GetContext gets a value like Get, recording a span in the trace of ctx
+func (fc *FileCache) GetContext(ctx context.Context, key string) (interface{}, error) {
+	_, span := startCacheSpan(ctx, "cache.get", "file", key)
+	defer span.End()
+
+	value, err := fc.get(key)
+	span.SetAttribute("cache.hit", err == nil)
+	return value, err
+}
+
+func (fc *FileCache) get(key string) (interface{}, error) {
+	if value, err := fc.MemoryCache.get(key); err == nil {
 		return value, nil
 	}
 	
@@ -319,7 +391,7 @@ func (fc *FileCache) Get(key string) (interface{}, error) {
 		return nil, fmt.Errorf("cache miss")
 	}
 	
-	fc.MemoryCache.Put(key, item.Value, 0)
+	fc.MemoryCache.put(key, item.Value, 0)
 	return item.Value, nil
 }
 
@@ -338,6 +410,14 @@ func (fc *FileCache) keyToFilename(key string) string {
 	return fmt.Sprintf("%x.cache", []byte(key))
 }
 
+// startCacheSpan starts a span for an operation on key in a cache store
+func startCacheSpan(ctx context.Context, name, store, key string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, name, tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(map[string]interface{}{
+		"cache.store": store,
+		"cache.key":   key,
+	}))
+}
+
 type CacheManager struct {
 	stores map[string]Cache
 	default_ string
@@ -387,6 +467,19 @@ func (cm *CacheManager) RegisterStore(name string, store Cache) {
 	cm.stores[name] = store
 }
 
+// Close closes every store that holds resources
+func (cm *CacheManager) Close() error {
+	var errs []error
+	for name, store := range cm.stores {
+		if closer, ok := store.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("cache store %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func CacheMiddleware(cache Cache, duration time.Duration) MiddlewareFunc {
 	return func(c Context) error {
 		key := fmt.Sprintf("route_cache_%s_%s", c.Method(), c.URL())
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/onyx-go/framework/internal/health"
+	"github.com/onyx-go/framework/internal/http/middleware/maintenance"
+
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
@@ -185,6 +195,23 @@ var commands = []Command{
 		Description: "Test API endpoints",
 		Action:      apiTest,
 	},
+	
+	// Operations commands
+	{
+		Name:        "health",
+		Description: "Check the health of a running application",
+		Action:      healthCheck,
+	},
+	{
+		Name:        "down",
+		Description: "Put the application into maintenance mode",
+		Action:      down,
+	},
+	{
+		Name:        "up",
+		Description: "Bring the application out of maintenance mode",
+		Action:      up,
+	},
 }
 
 func main() {
@@ -229,6 +256,7 @@ func showHelp() {
 		"Task Scheduling": {},
 		"API Documentation": {},
 		"API Tools": {},
+		"Operations": {},
 	}
 	
 	for _, cmd := range commands {
@@ -255,6 +283,8 @@ func showHelp() {
 			categories["API Documentation"] = append(categories["API Documentation"], cmd)
 		case strings.HasPrefix(cmd.Name, "api:"):
 			categories["API Tools"] = append(categories["API Tools"], cmd)
+		case cmd.Name == "health" || cmd.Name == "down" || cmd.Name == "up":
+			categories["Operations"] = append(categories["Operations"], cmd)
 		}
 	}
 	
@@ -508,7 +538,10 @@ func createMainFile(projectName string) error {
 	mainTemplate := `package main
 
 import (
+	"context"
 	"fmt"
+	"log"
+
 	"github.com/onyx-go/framework"
 )
 
@@ -521,8 +554,11 @@ func main() {
 	// Configure template engine (optional)
 	// app.SetTemplateEngine("resources/views", "resources/views/layouts")
 
+	// Run shuts down gracefully on SIGINT/SIGTERM
 	fmt.Println("🚀 {{.ProjectName}} server starting...")
-	app.Start(":8080")
+	if err := app.Run(context.Background(), ":8080"); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func loadRoutes(app *framework.Application) {
@@ -1209,14 +1245,15 @@ func routeList(args []string) error {
 	fmt.Println("  loadRoutes(app)")
 	fmt.Println("  routes := app.Router.GetRoutes()")
 	fmt.Println("  for _, route := range routes {")
-	fmt.Println("    fmt.Println(route.Method, route.Pattern, route.Handler)")
+	fmt.Println("    fmt.Println(route.Method(), route.Domain(), route.Pattern(), route.Name())")
 	fmt.Println("  }")
 	fmt.Println()
-	fmt.Printf("%-8s %-30s %-20s %s\n", "Method", "URI", "Name", "Action")
-	fmt.Println(strings.Repeat("-", 80))
-	fmt.Printf("%-8s %-30s %-20s %s\n", "GET", "/", "home", "HomeController@index")
-	fmt.Printf("%-8s %-30s %-20s %s\n", "GET", "/api/users", "users.index", "UserController@index")
-	fmt.Printf("%-8s %-30s %-20s %s\n", "POST", "/api/users", "users.store", "UserController@store")
+	fmt.Printf("%-8s %-24s %-24s %-20s %s\n", "Method", "Domain", "URI", "Name", "Action")
+	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%-8s %-24s %-24s %-20s %s\n", "GET", "", "/", "home", "HomeController@index")
+	fmt.Printf("%-8s %-24s %-24s %-20s %s\n", "GET", "api.example.com", "/users", "users.index", "UserController@index")
+	fmt.Printf("%-8s %-24s %-24s %-20s %s\n", "POST", "api.example.com", "/users", "users.store", "UserController@store")
+	fmt.Printf("%-8s %-24s %-24s %-20s %s\n", "GET", "{tenant}.example.com", "/dashboard", "tenant.dashboard", "DashboardController@show")
 	
 	return nil
 }
@@ -2027,4 +2064,228 @@ func apiTest(args []string) error {
 	fmt.Println("  📈 Success Rate: 80%")
 
 	return nil
-}
\ No newline at end of file
+}
+
+func healthCheck(args []string) error {
+	baseURL := "http://localhost:8080"
+	prefix := "/health"
+	probe := "ready"
+	timeout := 10 * time.Second
+	asJSON := false
+	
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--base-url":
+			if i+1 < len(args) {
+				baseURL = args[i+1]
+				i++
+			}
+		case "--prefix":
+			if i+1 < len(args) {
+				prefix = args[i+1]
+				i++
+			}
+		case "--timeout":
+			if i+1 < len(args) {
+				d, err := time.ParseDuration(args[i+1])
+				if err != nil {
+					return fmt.Errorf("invalid timeout: %w", err)
+				}
+				timeout = d
+				i++
+			}
+		case "--live":
+			probe = "live"
+		case "--json":
+			asJSON = true
+		case "--help":
+			fmt.Println("Check the liveness or readiness of a running application")
+			fmt.Println()
+			fmt.Println("Usage:")
+			fmt.Println("  github.com/onyx-go/framework health [options]")
+			fmt.Println()
+			fmt.Println("Options:")
+			fmt.Println("  --base-url URL    Base URL of the application (default http://localhost:8080)")
+			fmt.Println("  --prefix PATH     Path the health endpoints are served under (default /health)")
+			fmt.Println("  --live            Run the liveness checks instead of the readiness checks")
+			fmt.Println("  --timeout DUR     Give up after this long (default 10s)")
+			fmt.Println("  --json            Print the report as JSON")
+			fmt.Println("  --help            Show this help message")
+			fmt.Println()
+			fmt.Println("The command exits with status 1 when the application is down.")
+			return nil
+		}
+	}
+	
+	url := strings.TrimRight(baseURL, "/") + "/" + strings.Trim(prefix, "/") + "/" + probe
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read health report: %w", err)
+	}
+	
+	var report health.Report
+	if err := json.Unmarshal(body, &report); err != nil || report.Status == "" {
+		return fmt.Errorf("%s did not return a health report (status %d)", url, resp.StatusCode)
+	}
+	
+	if asJSON {
+		fmt.Println(strings.TrimSpace(string(body)))
+	} else {
+		printHealthReport(url, report)
+	}
+	
+	if report.Status == health.StatusDown {
+		return fmt.Errorf("application is down")
+	}
+	return nil
+}
+
+func printHealthReport(url string, report health.Report) {
+	icons := map[health.Status]string{
+		health.StatusUp:       "✅",
+		health.StatusDegraded: "⚠️ ",
+		health.StatusDown:     "❌",
+	}
+	
+	fmt.Printf("🩺 %s\n", url)
+	fmt.Printf("%s Status: %s\n", icons[report.Status], report.Status)
+	if len(report.Checks) == 0 {
+		return
+	}
+	
+	names := make([]string, 0, len(report.Checks))
+	for name := range report.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	
+	fmt.Println()
+	for _, name := range names {
+		result := report.Checks[name]
+		line := fmt.Sprintf("  %s %-25s %8.1fms", icons[result.Status], name, result.Duration)
+		if !result.Critical {
+			line += " (non-critical)"
+		}
+		if result.Error != "" {
+			line += "  " + result.Error
+		}
+		fmt.Println(line)
+	}
+}
+
+func down(args []string) error {
+	state := maintenance.State{}
+	path := maintenance.DefaultPath
+	
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		next := func() string {
+			if hasValue {
+				return value
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+		
+		switch name {
+		case "--secret":
+			state.Secret = strings.Trim(next(), "/")
+		case "--with-secret":
+			secret := make([]byte, 16)
+			if _, err := rand.Read(secret); err != nil {
+				return fmt.Errorf("failed to generate secret: %w", err)
+			}
+			state.Secret = hex.EncodeToString(secret)
+		case "--retry":
+			retry, err := strconv.Atoi(next())
+			if err != nil || retry < 0 {
+				return fmt.Errorf("--retry expects a number of seconds")
+			}
+			state.Retry = retry
+		case "--allow":
+			state.Allowed = append(state.Allowed, strings.Split(next(), ",")...)
+		case "--except":
+			state.Except = append(state.Except, strings.Split(next(), ",")...)
+		case "--render":
+			state.Template = next()
+		case "--message":
+			state.Message = next()
+		case "--path":
+			path = next()
+		case "--help":
+			fmt.Println("Put the application into maintenance mode")
+			fmt.Println()
+			fmt.Println("Usage:")
+			fmt.Println("  github.com/onyx-go/framework down [options]")
+			fmt.Println()
+			fmt.Println("Options:")
+			fmt.Println("  --secret=SECRET   Visiting /SECRET sets a cookie that bypasses maintenance mode")
+			fmt.Println("  --with-secret     Generate a random secret")
+			fmt.Println("  --retry=SECONDS   Value of the Retry-After header")
+			fmt.Println("  --allow=IPS       Comma-separated addresses or CIDR ranges let through")
+			fmt.Println("  --except=PATHS    Comma-separated paths served as usual; a trailing * matches a prefix")
+			fmt.Println("  --render=VIEW     Template rendered instead of the built-in page")
+			fmt.Println("  --message=TEXT    Message shown on the maintenance page")
+			fmt.Println("  --path=FILE       State file (default " + maintenance.DefaultPath + ")")
+			fmt.Println("  --help            Show this help message")
+			return nil
+		default:
+			return fmt.Errorf("unknown option %s", args[i])
+		}
+	}
+	
+	if err := maintenance.Down(path, state); err != nil {
+		return fmt.Errorf("failed to enter maintenance mode: %w", err)
+	}
+	
+	fmt.Println("🚧 Application is now in maintenance mode")
+	if state.Secret != "" {
+		fmt.Printf("🔑 Bypass by visiting: /%s\n", state.Secret)
+	}
+	if len(state.Allowed) > 0 {
+		fmt.Printf("✅ Allowed: %s\n", strings.Join(state.Allowed, ", "))
+	}
+	return nil
+}
+
+func up(args []string) error {
+	path := maintenance.DefaultPath
+	for i := 0; i < len(args); i++ {
+		if value, ok := strings.CutPrefix(args[i], "--path="); ok {
+			path = value
+		} else if args[i] == "--path" && i+1 < len(args) {
+			path = args[i+1]
+			i++
+		}
+	}
+	
+	// The middleware treats a file it cannot parse as down, so it is removed
+	// regardless and the parse error is only reported
+	state, err := maintenance.Read(path)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	} else if state == nil {
+		fmt.Println("ℹ️  Application is not in maintenance mode")
+		return nil
+	}
+	
+	if err := maintenance.Up(path); err != nil {
+		return fmt.Errorf("failed to leave maintenance mode: %w", err)
+	}
+	if state == nil {
+		fmt.Println("✅ Application is now live")
+		return nil
+	}
+	fmt.Printf("✅ Application is now live (down since %s)\n", state.Time.Format(time.RFC3339))
+	return nil
+}
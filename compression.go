@@ -84,8 +84,10 @@ func (crw *CompressedResponseWriter) WriteHeader(statusCode int) {
 	crw.statusCode = statusCode
 	crw.wroteHeader = true
 	
-	// Don't compress error responses or redirects - write immediately
-	if statusCode < 200 || statusCode >= 300 {
+	// Don't compress error responses, redirects, streams, byte ranges or
+	// responses that are already encoded - write immediately
+	if statusCode < 200 || statusCode >= 300 || statusCode == http.StatusPartialContent ||
+		isStreamingContentType(crw.Header().Get("Content-Type")) || crw.Header().Get("Content-Encoding") != "" {
 		crw.compressionSet = true
 		crw.ResponseWriter.WriteHeader(statusCode)
 		return
@@ -112,6 +114,8 @@ func (crw *CompressedResponseWriter) Write(data []byte) (int, error) {
 		shouldCompress := len(crw.buffer) >= crw.config.MinLength && 
 						  crw.statusCode >= 200 && 
 						  crw.statusCode < 300 &&
+						  crw.statusCode != http.StatusPartialContent &&
+						  crw.Header().Get("Content-Encoding") == "" &&
 						  crw.shouldCompress(contentType)
 		
 		
@@ -154,6 +158,34 @@ func (crw *CompressedResponseWriter) Write(data []byte) (int, error) {
 }
 
 
+// Flush sends buffered data to the client. A response flushed before the
+// compression decision is a stream and passes through uncompressed.
+func (crw *CompressedResponseWriter) Flush() {
+	if !crw.compressionSet {
+		crw.compressionSet = true
+		crw.wroteHeader = true
+		crw.ResponseWriter.WriteHeader(crw.statusCode)
+		if len(crw.buffer) > 0 {
+			crw.ResponseWriter.Write(crw.buffer)
+		}
+	}
+	
+	if crw.gzipWriter != nil {
+		crw.gzipWriter.Flush()
+	}
+	http.NewResponseController(crw.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (crw *CompressedResponseWriter) Unwrap() http.ResponseWriter {
+	return crw.ResponseWriter
+}
+
+// isStreamingContentType reports whether a content type is sent incrementally
+func isStreamingContentType(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), "text/event-stream")
+}
+
 // shouldCompress determines if the response should be compressed based on content type
 func (crw *CompressedResponseWriter) shouldCompress(contentType string) bool {
 	contentType = strings.ToLower(contentType)
@@ -224,6 +256,8 @@ func CompressionMiddleware(config ...CompressionConfig) MiddlewareFunc {
 		shouldCompress := len(bufferWriter.body) >= cfg.MinLength && 
 						 bufferWriter.statusCode >= 200 && 
 						 bufferWriter.statusCode < 300 &&
+						 bufferWriter.statusCode != http.StatusPartialContent &&
+						 bufferWriter.Header().Get("Content-Encoding") == "" &&
 						 shouldCompressContentType(contentType, cfg)
 		
 		println("DEBUG: Should compress:", shouldCompress)
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestDefaultCompressionConfig(t *testing.T) {
@@ -420,4 +421,86 @@ func TestCompressionRatio(t *testing.T) {
 	
 	t.Logf("Compression ratio: %.2f%% (compressed: %d bytes, uncompressed: %d bytes)", 
 		compressionRatio*100, compressedSize, uncompressedSize)
-}
\ No newline at end of file
+}
+
+func TestCompressionMiddleware_StreamingPassthrough(t *testing.T) {
+	app := New()
+	app.Use(NewStyleCompressionMiddleware())
+	
+	largeEvent := strings.Repeat("event payload ", 200) // > 1KB, would otherwise be compressed
+	
+	app.GetHandler("/events", func(c Context) error {
+		stream, err := c.SSE()
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return stream.Data(largeEvent)
+	})
+	
+	app.GetHandler("/stream", func(c Context) error {
+		sent := false
+		return c.Stream(200, "text/plain", func(w io.Writer) bool {
+			io.WriteString(w, largeEvent)
+			sent = true
+			return !sent
+		})
+	})
+	
+	for _, path := range []string{"/events", "/stream"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		
+		app.Router().ServeHTTP(w, req)
+		
+		if w.Header().Get("Content-Encoding") == "gzip" {
+			t.Errorf("%s: streaming response should not be compressed", path)
+		}
+		if !w.Flushed {
+			t.Errorf("%s: streaming response should be flushed through the compression writer", path)
+		}
+		if !strings.Contains(w.Body.String(), largeEvent) {
+			t.Errorf("%s: expected the raw payload in the body", path)
+		}
+	}
+}
+
+func TestCompressionMiddleware_AlreadyEncoded(t *testing.T) {
+	stylesheet := strings.Repeat("body { color: red; } ", 200)
+	
+	for name, register := range map[string]func(app *Application){
+		"writer": func(app *Application) { app.Use(NewStyleCompressionMiddleware()) },
+		"legacy": func(app *Application) { app.UseMiddleware(CompressionMiddleware()) },
+	} {
+		app := New()
+		register(app)
+		
+		app.Static("/assets", fstest.MapFS{
+			"app.css":    {Data: []byte(stylesheet)},
+			"app.css.br": {Data: []byte("brotli bytes")},
+		})
+		
+		req := httptest.NewRequest("GET", "/assets/app.css", nil)
+		req.Header.Set("Accept-Encoding", "gzip, br")
+		w := httptest.NewRecorder()
+		
+		app.Router().ServeHTTP(w, req)
+		
+		if w.Header().Get("Content-Encoding") != "br" || w.Body.String() != "brotli bytes" {
+			t.Errorf("%s: expected the brotli variant untouched, got %q %q", name, w.Header().Get("Content-Encoding"), w.Body.String())
+		}
+		
+		// Byte ranges of the plain file are not compressed either
+		req = httptest.NewRequest("GET", "/assets/app.css", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Range", "bytes=0-1999")
+		w = httptest.NewRecorder()
+		
+		app.Router().ServeHTTP(w, req)
+		
+		if w.Code != 206 || w.Header().Get("Content-Encoding") != "" || w.Body.String() != stylesheet[:2000] {
+			t.Errorf("%s: expected an uncompressed partial response, got %d %q", name, w.Code, w.Header().Get("Content-Encoding"))
+		}
+	}
+}
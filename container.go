@@ -130,6 +130,16 @@ func (c *Container) Has(name string) bool {
 	return hasInstance || hasBinding
 }
 
+// Resolved returns the instance registered or already resolved under name
+// without invoking any factory
+func (c *Container) Resolved(name string) (interface{}, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	
+	instance, exists := c.instances[name]
+	return instance, exists
+}
+
 type singletonBinding struct {
 	factory interface{}
 }
@@ -5,9 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
+	databaseInternal "github.com/onyx-go/framework/internal/database"
+	"github.com/onyx-go/framework/internal/database/grammar"
+	"github.com/onyx-go/framework/internal/tracing"
+
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
@@ -16,6 +21,7 @@ import (
 type DB struct {
 	*sql.DB
 	driver string
+	ctx    context.Context
 }
 
 type QueryBuilder struct {
@@ -24,6 +30,7 @@ type QueryBuilder struct {
 	selects         []string
 	wheres          []whereClause
 	orders          []string
+	orderColumns    []OrderByClause // The columns of orders, as given
 	limit           int
 	offset          int
 	joins           []string
@@ -33,6 +40,8 @@ type QueryBuilder struct {
 	eagerLoad       map[string]interface{}
 	eagerLoadEngine *EagerLoadingEngine
 	includeDeleted  bool // Whether to include soft-deleted records
+	ctx             context.Context
+	timeout         time.Duration
 }
 
 type whereClause struct {
@@ -46,6 +55,15 @@ type Model interface {
 	TableName() string
 }
 
+// Grammar is the SQL dialect of a connection: placeholder style, identifier
+// quoting, LIMIT/OFFSET syntax and how inserted ids are read back
+type Grammar = grammar.Grammar
+
+// ErrQueryCanceled is returned when a query is stopped because its context
+// was cancelled or its timeout passed. The error also wraps the context's
+// error, so errors.Is(err, context.DeadlineExceeded) tells timeouts apart.
+var ErrQueryCanceled = databaseInternal.ErrQueryCanceled
+
 func NewDB(driver, dsn string) (*DB, error) {
 	sqlDB, err := sql.Open(driver, dsn)
 	if err != nil {
@@ -62,6 +80,39 @@ func NewDB(driver, dsn string) (*DB, error) {
 	}, nil
 }
 
+// Grammar returns the SQL dialect for the connection's driver
+func (db *DB) Grammar() Grammar {
+	return grammar.For(db.driver)
+}
+
+// WithContext returns a copy of the connection whose query builders run
+// under ctx, so cancelling the request a query serves stops the query
+func (db *DB) WithContext(ctx context.Context) *DB {
+	return &DB{DB: db.DB, driver: db.driver, ctx: ctx}
+}
+
+// rebind rewrites the "?" placeholders in query for the connection's driver
+func (db *DB) rebind(query string) string {
+	return grammar.Rebind(db.Grammar(), query)
+}
+
+// insert runs an INSERT built with "?" placeholders. Drivers without
+// LastInsertId support read the new row back with RETURNING instead.
+func (db *DB) insert(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	g := db.Grammar()
+	if !g.SupportsReturning() {
+		result, err := db.ExecContext(ctx, grammar.Rebind(g, query), args...)
+		return result, databaseInternal.WrapCanceled(ctx, err)
+	}
+	
+	rows, err := db.QueryContext(ctx, grammar.Rebind(g, query+grammar.Returning), args...)
+	if err != nil {
+		return nil, databaseInternal.WrapCanceled(ctx, err)
+	}
+	result, err := grammar.ScanInsert(rows)
+	return result, databaseInternal.WrapCanceled(ctx, err)
+}
+
 func (db *DB) Table(tableName string) *QueryBuilder {
 	return &QueryBuilder{
 		db:              db,
@@ -76,6 +127,7 @@ func (db *DB) Table(tableName string) *QueryBuilder {
 		eagerLoad:       make(map[string]interface{}),
 		eagerLoadEngine: nil,
 		includeDeleted:  false,
+		ctx:             db.ctx,
 	}
 }
 
@@ -84,7 +136,7 @@ func (db *DB) Model(model Model) *QueryBuilder {
 }
 
 func NewQueryBuilder(db *DB) *QueryBuilder {
-	return &QueryBuilder{
+	qb := &QueryBuilder{
 		db:              db,
 		selects:         []string{"*"},
 		wheres:          []whereClause{},
@@ -97,6 +149,10 @@ func NewQueryBuilder(db *DB) *QueryBuilder {
 		eagerLoadEngine: nil,
 		includeDeleted:  false,
 	}
+	if db != nil {
+		qb.ctx = db.ctx
+	}
+	return qb
 }
 
 func (qb *QueryBuilder) Select(columns ...string) *QueryBuilder {
@@ -126,7 +182,8 @@ func (qb *QueryBuilder) OrWhere(column, operator string, value interface{}) *Que
 
 
 func (qb *QueryBuilder) OrderBy(column, direction string) *QueryBuilder {
-	qb.orders = append(qb.orders, fmt.Sprintf("%s %s", column, strings.ToUpper(direction)))
+	qb.orders = append(qb.orders, fmt.Sprintf("%s %s", qb.grammar().Wrap(column), strings.ToUpper(direction)))
+	qb.orderColumns = append(qb.orderColumns, OrderByClause{Column: column, Direction: strings.ToUpper(direction)})
 	return qb
 }
 
@@ -141,12 +198,14 @@ func (qb *QueryBuilder) Offset(offset int) *QueryBuilder {
 }
 
 func (qb *QueryBuilder) Join(table, first, operator, second string) *QueryBuilder {
-	qb.joins = append(qb.joins, fmt.Sprintf("JOIN %s ON %s %s %s", table, first, operator, second))
+	g := qb.grammar()
+	qb.joins = append(qb.joins, fmt.Sprintf("JOIN %s ON %s %s %s", g.Wrap(table), g.Wrap(first), operator, g.Wrap(second)))
 	return qb
 }
 
 func (qb *QueryBuilder) LeftJoin(table, first, operator, second string) *QueryBuilder {
-	qb.joins = append(qb.joins, fmt.Sprintf("LEFT JOIN %s ON %s %s %s", table, first, operator, second))
+	g := qb.grammar()
+	qb.joins = append(qb.joins, fmt.Sprintf("LEFT JOIN %s ON %s %s %s", g.Wrap(table), g.Wrap(first), operator, g.Wrap(second)))
 	return qb
 }
 
@@ -165,44 +224,104 @@ func (qb *QueryBuilder) Having(column, operator string, value interface{}) *Quer
 	return qb
 }
 
+// WithContext runs the query, and the relationships it eager loads, under
+// ctx. Cancelling ctx or passing its deadline stops the query with
+// ErrQueryCanceled.
+func (qb *QueryBuilder) WithContext(ctx context.Context) *QueryBuilder {
+	qb.ctx = ctx
+	return qb
+}
+
+// Timeout limits how long each statement the query runs may take
+func (qb *QueryBuilder) Timeout(timeout time.Duration) *QueryBuilder {
+	qb.timeout = timeout
+	return qb
+}
+
 func (qb *QueryBuilder) Get(dest interface{}) error {
 	query, args := qb.buildSelectQuery()
 	
-	rows, err := qb.db.Query(query, args...)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.driver, "SELECT", qb.table, query)
+	defer span.End()
+	
+	rows, err := qb.db.QueryContext(ctx, query, args...)
 	if err != nil {
+		err = databaseInternal.WrapCanceled(ctx, err)
+		span.RecordError(err)
 		return err
 	}
 	defer rows.Close()
 	
-	return qb.scanRows(rows, dest)
+	err = databaseInternal.WrapCanceled(ctx, qb.scanRows(rows, dest))
+	span.RecordError(err)
+	return err
 }
 
 func (qb *QueryBuilder) First(dest interface{}) error {
 	qb.Limit(1)
 	query, args := qb.buildSelectQuery()
 	
-	row := qb.db.QueryRow(query, args...)
-	return qb.scanRow(row, dest)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	row := qb.db.QueryRowContext(ctx, query, args...)
+	return databaseInternal.WrapCanceled(ctx, qb.scanRow(row, dest))
+}
+
+// Count returns the number of rows the query matches, ignoring its order,
+// limit and offset
+func (qb *QueryBuilder) Count() (int64, error) {
+	count := qb.clone()
+	count.orders, count.orderColumns = nil, nil
+	count.limit, count.offset = 0, 0
+	
+	var query string
+	var args []interface{}
+	if len(count.groupBy) > 0 {
+		// Count the groups, not the rows in them
+		query, args = count.compileSelect()
+		query = "SELECT COUNT(*) FROM (" + query + ") AS " + qb.grammar().Wrap("aggregate")
+	} else {
+		count.selects = []string{"COUNT(*)"}
+		query, args = count.compileSelect()
+	}
+	query = qb.db.rebind(query)
+	
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.driver, "SELECT", qb.table, query)
+	defer span.End()
+	
+	var total int64
+	err := qb.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	err = databaseInternal.WrapCanceled(ctx, err)
+	span.RecordError(err)
+	return total, err
 }
 
 func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
-	columns := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
+	g := qb.grammar()
+	columns := sortedColumns(data)
 	values := make([]interface{}, 0, len(data))
 	
-	for column, value := range data {
-		columns = append(columns, column)
-		placeholders = append(placeholders, "?")
-		values = append(values, value)
+	for _, column := range columns {
+		values = append(values, data[column])
 	}
 	
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		qb.table,
-		strings.Join(columns, ", "),
-		strings.Join(placeholders, ", "),
+		g.Wrap(qb.table),
+		grammar.Columnize(g, columns),
+		grammar.Parameters(len(columns)),
 	)
 	
-	result, err := qb.db.Exec(query, values...)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.driver, "INSERT", qb.table, query)
+	defer span.End()
+	
+	result, err := qb.db.insert(ctx, query, values...)
+	span.RecordError(err)
 	if err != nil {
 		return 0, err
 	}
@@ -211,15 +330,17 @@ func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
 }
 
 func (qb *QueryBuilder) Update(data map[string]interface{}) (int64, error) {
+	g := qb.grammar()
+	columns := sortedColumns(data)
 	setParts := make([]string, 0, len(data))
 	values := make([]interface{}, 0, len(data))
 	
-	for column, value := range data {
-		setParts = append(setParts, fmt.Sprintf("%s = ?", column))
-		values = append(values, value)
+	for _, column := range columns {
+		setParts = append(setParts, fmt.Sprintf("%s = ?", g.Wrap(column)))
+		values = append(values, data[column])
 	}
 	
-	query := fmt.Sprintf("UPDATE %s SET %s", qb.table, strings.Join(setParts, ", "))
+	query := fmt.Sprintf("UPDATE %s SET %s", g.Wrap(qb.table), strings.Join(setParts, ", "))
 	
 	if len(qb.wheres) > 0 {
 		whereClause, whereArgs := qb.buildWhereClause(qb.wheres)
@@ -227,7 +348,7 @@ func (qb *QueryBuilder) Update(data map[string]interface{}) (int64, error) {
 		values = append(values, whereArgs...)
 	}
 	
-	result, err := qb.db.Exec(query, values...)
+	result, err := qb.exec("UPDATE", query, values...)
 	if err != nil {
 		return 0, err
 	}
@@ -235,6 +356,20 @@ func (qb *QueryBuilder) Update(data map[string]interface{}) (int64, error) {
 	return result.RowsAffected()
 }
 
+// exec rebinds a write statement for the driver and runs it inside a span
+func (qb *QueryBuilder) exec(operation, query string, args ...interface{}) (sql.Result, error) {
+	query = qb.db.rebind(query)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.driver, operation, qb.table, query)
+	defer span.End()
+	
+	result, err := qb.db.ExecContext(ctx, query, args...)
+	err = databaseInternal.WrapCanceled(ctx, err)
+	span.RecordError(err)
+	return result, err
+}
+
 func (qb *QueryBuilder) Delete() (int64, error) {
 	// Perform soft delete by default for tables with deleted_at column
 	now := time.Now()
@@ -246,7 +381,7 @@ func (qb *QueryBuilder) Delete() (int64, error) {
 
 // ForceDelete performs a hard delete, permanently removing records
 func (qb *QueryBuilder) ForceDelete() (int64, error) {
-	query := fmt.Sprintf("DELETE FROM %s", qb.table)
+	query := fmt.Sprintf("DELETE FROM %s", qb.grammar().Wrap(qb.table))
 	var args []interface{}
 	
 	if len(qb.wheres) > 0 {
@@ -255,7 +390,7 @@ func (qb *QueryBuilder) ForceDelete() (int64, error) {
 		args = whereArgs
 	}
 	
-	result, err := qb.db.Exec(query, args...)
+	result, err := qb.exec("DELETE", query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -271,13 +406,30 @@ func (qb *QueryBuilder) Restore() (int64, error) {
 	})
 }
 
+// buildSelectQuery compiles the query with placeholders for the driver
 func (qb *QueryBuilder) buildSelectQuery() (string, []interface{}) {
+	query, args := qb.compileSelect()
+	return grammar.Rebind(qb.grammar(), query), args
+}
+
+// compileSelect builds the SELECT statement with "?" placeholders, so it can
+// also be embedded in another query as a subquery
+func (qb *QueryBuilder) compileSelect() (string, []interface{}) {
 	// Apply soft delete filter before building query
 	qb.applySoftDeleteFilter()
 	
-	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(qb.selects, ", "), qb.table)
+	g := qb.grammar()
 	var args []interface{}
 	
+	query := "SELECT *"
+	if len(qb.selects) > 0 {
+		query = "SELECT " + grammar.Columnize(g, qb.selects)
+	}
+	
+	if qb.table != "" {
+		query += " FROM " + g.Wrap(qb.table)
+	}
+	
 	if len(qb.joins) > 0 {
 		query += " " + strings.Join(qb.joins, " ")
 	}
@@ -289,7 +441,7 @@ func (qb *QueryBuilder) buildSelectQuery() (string, []interface{}) {
 	}
 	
 	if len(qb.groupBy) > 0 {
-		query += " GROUP BY " + strings.Join(qb.groupBy, ", ")
+		query += " GROUP BY " + grammar.Columnize(g, qb.groupBy)
 	}
 	
 	if len(qb.having) > 0 {
@@ -302,19 +454,13 @@ func (qb *QueryBuilder) buildSelectQuery() (string, []interface{}) {
 		query += " ORDER BY " + strings.Join(qb.orders, ", ")
 	}
 	
-	if qb.limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", qb.limit)
-	}
-	
-	if qb.offset > 0 {
-		query += fmt.Sprintf(" OFFSET %d", qb.offset)
-	}
+	query += g.Limit(qb.limit, qb.offset)
 	
-	args = append(args, qb.bindings...)
 	return query, args
 }
 
 func (qb *QueryBuilder) buildWhereClause(wheres []whereClause) (string, []interface{}) {
+	g := qb.grammar()
 	var parts []string
 	var args []interface{}
 	
@@ -325,12 +471,19 @@ func (qb *QueryBuilder) buildWhereClause(wheres []whereClause) (string, []interf
 			part += fmt.Sprintf(" %s ", where.boolean)
 		}
 		
-		if where.operator == "IN" {
-			part += fmt.Sprintf("%s %s %s", where.column, where.operator, where.value)
+		if where.operator == "RAW" {
+			part += where.column
+			if values, ok := where.value.([]interface{}); ok {
+				args = append(args, values...)
+			}
+		} else if where.operator == "IN" {
+			values, _ := where.value.([]interface{})
+			part += fmt.Sprintf("%s %s (%s)", g.Wrap(where.column), where.operator, grammar.Parameters(len(values)))
+			args = append(args, values...)
 		} else if where.operator == "IS NULL" || where.operator == "IS NOT NULL" {
-			part += fmt.Sprintf("%s %s", where.column, where.operator)
+			part += fmt.Sprintf("%s %s", g.Wrap(where.column), where.operator)
 		} else {
-			part += fmt.Sprintf("%s %s ?", where.column, where.operator)
+			part += fmt.Sprintf("%s %s ?", g.Wrap(where.column), where.operator)
 			args = append(args, where.value)
 		}
 		
@@ -340,6 +493,39 @@ func (qb *QueryBuilder) buildWhereClause(wheres []whereClause) (string, []interf
 	return strings.Join(parts, ""), args
 }
 
+// queryContext returns the context a statement runs under, limited by the
+// builder's timeout. The cancel function must be called once the statement
+// and its rows are done with.
+func (qb *QueryBuilder) queryContext() (context.Context, context.CancelFunc) {
+	ctx := qb.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if qb.timeout > 0 {
+		return context.WithTimeout(ctx, qb.timeout)
+	}
+	return ctx, func() {}
+}
+
+// grammar returns the dialect of the builder's connection
+func (qb *QueryBuilder) grammar() Grammar {
+	if qb.db == nil {
+		return grammar.For("")
+	}
+	return qb.db.Grammar()
+}
+
+// sortedColumns returns the keys of data in a stable order, so the same
+// data always builds the same statement
+func sortedColumns(data map[string]interface{}) []string {
+	columns := make([]string, 0, len(data))
+	for column := range data {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
 func (qb *QueryBuilder) scanRows(rows *sql.Rows, dest interface{}) error {
 	destValue := reflect.ValueOf(dest)
 	if destValue.Kind() != reflect.Ptr {
@@ -829,97 +1015,11 @@ func (qb *QueryBuilder) whereRaw(expression string) *QueryBuilder {
 	return qb
 }
 
-// toSQL converts the query to SQL string
+// toSQL converts the query to SQL string with "?" placeholders, for use as
+// a subquery
 func (qb *QueryBuilder) toSQL() string {
-	// Apply soft delete filter before building SQL
-	qb.applySoftDeleteFilter()
-	
-	var query strings.Builder
-	
-	// SELECT clause
-	query.WriteString("SELECT ")
-	if len(qb.selects) > 0 {
-		query.WriteString(strings.Join(qb.selects, ", "))
-	} else {
-		query.WriteString("*")
-	}
-	
-	// FROM clause
-	if qb.table != "" {
-		query.WriteString(" FROM ")
-		query.WriteString(qb.table)
-	}
-	
-	// JOIN clauses
-	for _, join := range qb.joins {
-		query.WriteString(" ")
-		query.WriteString(join)
-	}
-	
-	// WHERE clauses
-	if len(qb.wheres) > 0 {
-		query.WriteString(" WHERE ")
-		for i, where := range qb.wheres {
-			if i > 0 {
-				query.WriteString(" ")
-				query.WriteString(where.boolean)
-				query.WriteString(" ")
-			}
-			
-			if where.operator == "RAW" {
-				query.WriteString(where.column)
-			} else if where.operator == "IS NULL" || where.operator == "IS NOT NULL" {
-				query.WriteString(where.column)
-				query.WriteString(" ")
-				query.WriteString(where.operator)
-			} else {
-				query.WriteString(where.column)
-				query.WriteString(" ")
-				query.WriteString(where.operator)
-				query.WriteString(" ?")
-			}
-		}
-	}
-	
-	// GROUP BY clause
-	if len(qb.groupBy) > 0 {
-		query.WriteString(" GROUP BY ")
-		query.WriteString(strings.Join(qb.groupBy, ", "))
-	}
-	
-	// HAVING clauses
-	if len(qb.having) > 0 {
-		query.WriteString(" HAVING ")
-		for i, having := range qb.having {
-			if i > 0 {
-				query.WriteString(" ")
-				query.WriteString(having.boolean)
-				query.WriteString(" ")
-			}
-			query.WriteString(having.column)
-			query.WriteString(" ")
-			query.WriteString(having.operator)
-			query.WriteString(" ?")
-		}
-	}
-	
-	// ORDER BY clause
-	if len(qb.orders) > 0 {
-		query.WriteString(" ORDER BY ")
-		query.WriteString(strings.Join(qb.orders, ", "))
-	}
-	
-	// LIMIT clause
-	if qb.limit > 0 {
-		query.WriteString(fmt.Sprintf(" LIMIT %d", qb.limit))
-	}
-	
-	// OFFSET clause
-	if qb.offset > 0 {
-		query.WriteString(fmt.Sprintf(" OFFSET %d", qb.offset))
-	}
-	
-	return query.String()
+	query, _ := qb.compileSelect()
+	return query
 }
 
 // WhereIn adds a WHERE IN clause
@@ -928,16 +1028,10 @@ func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuild
 		return qb
 	}
 	
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholders[i] = "?"
-		qb.bindings = append(qb.bindings, values[i])
-	}
-	
 	qb.wheres = append(qb.wheres, whereClause{
 		column:   column,
 		operator: "IN",
-		value:    fmt.Sprintf("(%s)", strings.Join(placeholders, ", ")),
+		value:    values,
 		boolean:  "AND",
 	})
 	
@@ -1030,20 +1124,16 @@ func CreateModel(ctx context.Context, db *DB, model EventableModel) error {
 	fields, values := extractModelFields(model)
 	
 	// Build INSERT query
-	placeholders := make([]string, len(values))
-	for i := range placeholders {
-		placeholders[i] = "?"
-	}
-	
+	g := db.Grammar()
 	query := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES (%s)",
-		tableName,
-		strings.Join(fields, ", "),
-		strings.Join(placeholders, ", "),
+		g.Wrap(tableName),
+		grammar.Columnize(g, fields),
+		grammar.Parameters(len(values)),
 	)
 	
 	// Execute the insert
-	result, err := db.Exec(query, values...)
+	result, err := db.insert(ctx, query, values...)
 	if err != nil {
 		return fmt.Errorf("failed to create %s: %w", model.GetModelName(), err)
 	}
@@ -1103,26 +1193,29 @@ func UpdateModel(ctx context.Context, db *DB, model EventableModel) error {
 	}
 	
 	// Build UPDATE query for dirty fields only
+	g := db.Grammar()
 	dirtyFields := baseModel.GetDirtyFields()
 	fields := make([]string, 0, len(dirtyFields))
 	values := make([]interface{}, 0, len(dirtyFields))
 	
-	for field, value := range dirtyFields {
-		fields = append(fields, fmt.Sprintf("%s = ?", field))
-		values = append(values, value)
+	for _, field := range sortedColumns(dirtyFields) {
+		fields = append(fields, fmt.Sprintf("%s = ?", g.Wrap(field)))
+		values = append(values, dirtyFields[field])
 	}
 	
 	// Add ID to WHERE clause
 	values = append(values, baseModel.ID)
 	
 	query := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE id = ?",
-		model.TableName(),
+		"UPDATE %s SET %s WHERE %s = ?",
+		g.Wrap(model.TableName()),
 		strings.Join(fields, ", "),
+		g.Wrap("id"),
 	)
 	
 	// Execute the update
-	result, err := db.Exec(query, values...)
+	result, err := db.ExecContext(ctx, grammar.Rebind(g, query), values...)
+	err = databaseInternal.WrapCanceled(ctx, err)
 	if err != nil {
 		return fmt.Errorf("failed to update %s: %w", model.GetModelName(), err)
 	}
@@ -1180,8 +1273,11 @@ func DeleteModel(ctx context.Context, db *DB, model EventableModel) error {
 	baseModel.MarkAsDirty("updated_at", now)
 	
 	// Execute the soft delete
-	query := fmt.Sprintf("UPDATE %s SET deleted_at = ?, updated_at = ? WHERE id = ?", model.TableName())
-	result, err := db.Exec(query, now, now, baseModel.ID)
+	g := db.Grammar()
+	query := fmt.Sprintf("UPDATE %s SET %s = ?, %s = ? WHERE %s = ?",
+		g.Wrap(model.TableName()), g.Wrap("deleted_at"), g.Wrap("updated_at"), g.Wrap("id"))
+	result, err := db.ExecContext(ctx, grammar.Rebind(g, query), now, now, baseModel.ID)
+	err = databaseInternal.WrapCanceled(ctx, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete %s: %w", model.GetModelName(), err)
 	}
@@ -1227,8 +1323,10 @@ func ForceDeleteModel(ctx context.Context, db *DB, model EventableModel) error {
 	}
 	
 	// Execute the hard delete
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = ?", model.TableName())
-	result, err := db.Exec(query, baseModel.ID)
+	g := db.Grammar()
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", g.Wrap(model.TableName()), g.Wrap("id"))
+	result, err := db.ExecContext(ctx, grammar.Rebind(g, query), baseModel.ID)
+	err = databaseInternal.WrapCanceled(ctx, err)
 	if err != nil {
 		return fmt.Errorf("failed to force delete %s: %w", model.GetModelName(), err)
 	}
@@ -1274,8 +1372,11 @@ func RestoreModel(ctx context.Context, db *DB, model EventableModel) error {
 	baseModel.MarkAsDirty("updated_at", now)
 	
 	// Execute the restore
-	query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL, updated_at = ? WHERE id = ?", model.TableName())
-	result, err := db.Exec(query, now, baseModel.ID)
+	g := db.Grammar()
+	query := fmt.Sprintf("UPDATE %s SET %s = NULL, %s = ? WHERE %s = ?",
+		g.Wrap(model.TableName()), g.Wrap("deleted_at"), g.Wrap("updated_at"), g.Wrap("id"))
+	result, err := db.ExecContext(ctx, grammar.Rebind(g, query), now, baseModel.ID)
+	err = databaseInternal.WrapCanceled(ctx, err)
 	if err != nil {
 		return fmt.Errorf("failed to restore %s: %w", model.GetModelName(), err)
 	}
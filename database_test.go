@@ -1,7 +1,9 @@
 package onyx
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
@@ -180,4 +182,121 @@ func TestDatabaseORMInsertUpdate(t *testing.T) {
 	if affected != 1 {
 		t.Errorf("Expected 1 affected row, got %d", affected)
 	}
-}
\ No newline at end of file
+}
+
+func TestQueryBuilderGrammar(t *testing.T) {
+	db := &DB{driver: "postgres"}
+	query, args := db.Table("orders").
+		Select("id", "user").
+		WhereIn("status", []interface{}{"new", "paid"}).
+		Where("total", ">", 10).
+		OrderBy("created_at", "desc").
+		Limit(5).
+		buildSelectQuery()
+
+	expected := `SELECT "id", "user" FROM "orders" WHERE "status" IN ($1, $2) AND "total" > $3 AND "deleted_at" IS NULL ORDER BY "created_at" DESC LIMIT 5`
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	if len(args) != 3 || args[0] != "new" || args[2] != 10 {
+		t.Errorf("Expected arguments in placeholder order, got %v", args)
+	}
+}
+
+func TestQueryBuilderReservedWords(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	_, err = sqlDB.Exec(`CREATE TABLE "order" (id INTEGER PRIMARY KEY AUTOINCREMENT, "group" TEXT, "user" TEXT, deleted_at DATETIME)`)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	db := &DB{DB: sqlDB, driver: "sqlite3"}
+	id, err := db.Table("order").Insert(map[string]interface{}{"group": "a", "user": "ana"})
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if id != 1 {
+		t.Errorf("Expected id 1, got %d", id)
+	}
+
+	if _, err := db.Table("order").Where("group", "=", "a").Update(map[string]interface{}{"user": "bo"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	var rows []struct {
+		ID   int    `db:"id"`
+		User string `db:"user"`
+	}
+	if err := db.Table("order").Select("id", "user").Where("group", "=", "a").Get(&rows); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].User != "bo" {
+		t.Errorf("Expected the updated row, got %+v", rows)
+	}
+}
+
+func TestQueryBuilderContext(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	_, err = sqlDB.Exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, title TEXT, deleted_at DATETIME)`)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	db := &DB{DB: sqlDB, driver: "sqlite3"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var posts []struct {
+		ID int `db:"id"`
+	}
+	err = db.WithContext(ctx).Table("posts").Get(&posts)
+	if !errors.Is(err, ErrQueryCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled Get to return ErrQueryCanceled, got %v", err)
+	}
+	if _, err := db.Table("posts").WithContext(ctx).Insert(map[string]interface{}{"title": "late"}); !errors.Is(err, ErrQueryCanceled) {
+		t.Errorf("Expected a cancelled Insert to return ErrQueryCanceled, got %v", err)
+	}
+	if _, err := db.Table("posts").WithContext(ctx).Update(map[string]interface{}{"title": "late"}); !errors.Is(err, ErrQueryCanceled) {
+		t.Errorf("Expected a cancelled Update to return ErrQueryCanceled, got %v", err)
+	}
+	if err := db.Table("posts").Get(&posts); err != nil || len(posts) != 0 {
+		t.Errorf("Expected the cancelled insert not to run, got %v %+v", err, posts)
+	}
+
+	// Timeouts apply per statement and report DeadlineExceeded
+	err = db.Table("posts").
+		whereRaw("id IN (WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT x FROM c WHERE x < 0)").
+		Timeout(50 * time.Millisecond).
+		Get(&posts)
+	if !errors.Is(err, ErrQueryCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a timed out query to return ErrQueryCanceled, got %v", err)
+	}
+
+	// Relationship queries inherit the context
+	relationship := NewHasMany(&RelUser{BaseModel: BaseModel{ID: 1}}, &RelPost{}, "user_id", "id")
+	relationship.GetQuery().db = db
+	if _, err := GetResultsContext(ctx, relationship); !errors.Is(err, ErrQueryCanceled) {
+		t.Errorf("Expected GetResultsContext to run under the context, got %v", err)
+	}
+
+	RegisterRelationship("RelUser", "contextPosts", func() Relationship {
+		relationship := NewHasMany(&RelUser{}, &RelPost{}, "user_id", "id")
+		relationship.GetQuery().db = db
+		return relationship
+	})
+	engine := NewEagerLoadingEngine().WithContext(ctx)
+	engine.AddRelation("contextPosts", nil)
+	if err := engine.LoadForModels([]interface{}{&RelUser{BaseModel: BaseModel{ID: 1}}}); !errors.Is(err, ErrQueryCanceled) {
+		t.Errorf("Expected eager loading to run under the context, got %v", err)
+	}
+}
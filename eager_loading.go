@@ -1,14 +1,18 @@
 package onyx
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // EagerLoadingEngine handles eager loading of relationships
 type EagerLoadingEngine struct {
 	relations map[string]*EagerLoadDefinition
+	ctx       context.Context
+	timeout   time.Duration
 }
 
 // EagerLoadDefinition defines how a relationship should be eagerly loaded
@@ -46,6 +50,12 @@ func (ele *EagerLoadingEngine) AddRelation(name string, constraints func(*QueryB
 	}
 }
 
+// WithContext runs the relationship queries under ctx
+func (ele *EagerLoadingEngine) WithContext(ctx context.Context) *EagerLoadingEngine {
+	ele.ctx = ctx
+	return ele
+}
+
 // LoadForModels loads all registered relationships for the given models
 func (ele *EagerLoadingEngine) LoadForModels(models []interface{}) error {
 	if len(models) == 0 {
@@ -89,9 +99,15 @@ func (ele *EagerLoadingEngine) loadRelationshipForModelType(models []interface{}
 	// Create relationship instance
 	relationship := relationFactory()
 	
+	// Related queries run under the context and timeout of the query loading them
+	query := relationship.GetQuery()
+	if ele.ctx != nil {
+		query.WithContext(ele.ctx)
+	}
+	query.Timeout(ele.timeout)
+	
 	// Apply constraints if any
 	if definition.Constraints != nil {
-		query := relationship.GetQuery()
 		definition.Constraints(query)
 	}
 	
@@ -428,7 +444,7 @@ func (qb *QueryBuilder) GetWithRelationships() ([]interface{}, error) {
 	
 	// Load relationships if eager loading is enabled
 	if qb.eagerLoadEngine != nil {
-		err = qb.eagerLoadEngine.LoadForModels(models)
+		err = qb.loadRelationships(models)
 		if err != nil {
 			return nil, err
 		}
@@ -446,7 +462,7 @@ func (qb *QueryBuilder) FirstWithRelationships(dest interface{}) error {
 	
 	// Load relationships if eager loading is enabled
 	if qb.eagerLoadEngine != nil {
-		err = qb.eagerLoadEngine.LoadForModels([]interface{}{dest})
+		err = qb.loadRelationships([]interface{}{dest})
 		if err != nil {
 			return err
 		}
@@ -455,6 +471,18 @@ func (qb *QueryBuilder) FirstWithRelationships(dest interface{}) error {
 	return nil
 }
 
+// loadRelationships eager loads relationships for models under the query's
+// context and timeout
+func (qb *QueryBuilder) loadRelationships(models []interface{}) error {
+	if qb.ctx != nil {
+		qb.eagerLoadEngine.ctx = qb.ctx
+	}
+	if qb.timeout > 0 {
+		qb.eagerLoadEngine.timeout = qb.timeout
+	}
+	return qb.eagerLoadEngine.LoadForModels(models)
+}
+
 // Lazy Loading Support
 
 // LazyLoader provides lazy loading functionality for individual models
@@ -469,7 +497,12 @@ func NewLazyLoader(model interface{}) *LazyLoader {
 
 // Load loads specific relationships for the model
 func (ll *LazyLoader) Load(relations ...string) error {
-	engine := NewEagerLoadingEngine()
+	return ll.LoadContext(context.Background(), relations...)
+}
+
+// LoadContext loads specific relationships for the model under ctx
+func (ll *LazyLoader) LoadContext(ctx context.Context, relations ...string) error {
+	engine := NewEagerLoadingEngine().WithContext(ctx)
 	
 	for _, relation := range relations {
 		engine.AddRelation(relation, nil)
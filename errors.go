@@ -2,12 +2,15 @@ package onyx
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
 )
 
 // HTTPError represents an HTTP error with status code and message
@@ -139,6 +142,8 @@ func (eh *ErrorHandler) renderDefault(c Context, err error) {
 	var message string
 	var context map[string]interface{}
 
+	err = httpErrorFrom(err)
+
 	// Extract error information
 	switch e := err.(type) {
 	case *HTTPError:
@@ -405,6 +410,20 @@ func ServiceUnavailable(message string) *HTTPError {
 	return NewHTTPError(503, message)
 }
 
+// httpErrorFrom turns an error carrying an HTTP status from the internal
+// packages, such as a timeout or body limit, into an HTTPError
+func httpErrorFrom(err error) error {
+	if _, ok := err.(*HTTPError); ok {
+		return err
+	}
+
+	var statusErr *httpInternal.StatusError
+	if errors.As(err, &statusErr) {
+		return NewHTTPErrorWithInternal(statusErr.Code, statusErr.Message, err)
+	}
+	return err
+}
+
 // Validation error helpers
 func NewValidationError(field, message, value string) ValidationError {
 	return ValidationError{
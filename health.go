@@ -0,0 +1,121 @@
+package onyx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onyx-go/framework/internal/health"
+)
+
+// Health check types re-exported for application code. Anything with a
+// HealthCheck(ctx) error method, such as storage and mail drivers, can be
+// registered as is:
+//
+//	app.Health().Register("s3", disk.HealthCheck, onyx.HealthNonCritical())
+type (
+	HealthRegistry  = health.Registry
+	HealthCheckFunc = health.CheckFunc
+	HealthOption    = health.Option
+	HealthReport    = health.Report
+	HealthResult    = health.Result
+	HealthStatus    = health.Status
+)
+
+// Health statuses
+const (
+	HealthUp       = health.StatusUp
+	HealthDegraded = health.StatusDegraded
+	HealthDown     = health.StatusDown
+)
+
+// Health check options re-exported for application code
+var (
+	HealthTimeout     = health.WithTimeout
+	HealthNonCritical = health.NonCritical
+	HealthLiveness    = health.Liveness
+)
+
+// Health returns the application's health check registry
+func (app *Application) Health() *HealthRegistry {
+	return app.health
+}
+
+// HealthEndpoints serves the liveness report at prefix+"/live" and the
+// readiness report at prefix+"/ready", under "/health" when prefix is empty.
+// Both answer 200 while the application is up or degraded and 503 when a
+// critical check fails, and both stay up during maintenance mode.
+func (app *Application) HealthEndpoints(prefix string, middleware ...MiddlewareFunc) {
+	if prefix == "" {
+		prefix = "/health"
+	}
+	prefix = "/" + strings.Trim(prefix, "/")
+	app.healthMutex.Lock()
+	app.healthPrefixes = append(app.healthPrefixes, prefix)
+	app.healthMutex.Unlock()
+
+	live := app.health.LiveHandler()
+	ready := app.health.ReadyHandler()
+	app.GetHandler(prefix+"/live", func(c Context) error {
+		live.ServeHTTP(c.ResponseWriter(), c.Request())
+		return nil
+	}, middleware...)
+	app.GetHandler(prefix+"/ready", func(c Context) error {
+		ready.ServeHTTP(c.ResponseWriter(), c.Request())
+		return nil
+	}, middleware...)
+}
+
+// isHealthPath reports whether path is below a prefix passed to
+// HealthEndpoints, so maintenance mode keeps serving the probes
+func (app *Application) isHealthPath(path string) bool {
+	app.healthMutex.RLock()
+	defer app.healthMutex.RUnlock()
+	for _, prefix := range app.healthPrefixes {
+		if strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DatabaseHealthCheck pings db
+func DatabaseHealthCheck(db *DB) HealthCheckFunc {
+	return health.Ping(db)
+}
+
+// CacheHealthCheck writes a value to cache, reads it back and forgets it
+func CacheHealthCheck(cache health.RoundTripCache) HealthCheckFunc {
+	return health.CacheRoundTrip(cache)
+}
+
+// QueueBacklogHealthCheck fails when more than max jobs are waiting on the
+// named queue
+func QueueBacklogHealthCheck(queue Queue, name string, max int) HealthCheckFunc {
+	return func(ctx context.Context) error {
+		if size := queue.Size(name); size > max {
+			return fmt.Errorf("%d jobs waiting on %s, more than %d", size, name, max)
+		}
+		return nil
+	}
+}
+
+// DiskSpaceHealthCheck fails when the file system holding root has less than
+// minFreeBytes available. Pass the Root of a LocalStorage or local storage
+// driver.
+func DiskSpaceHealthCheck(root string, minFreeBytes uint64) HealthCheckFunc {
+	return health.DiskSpace(root, minFreeBytes)
+}
+
+// SchedulerHealthCheck fails when the scheduler is not running or has not
+// recorded a heartbeat within maxAge, which should exceed ten seconds
+func SchedulerHealthCheck(schedule *Schedule, maxAge time.Duration) HealthCheckFunc {
+	heartbeat := health.Heartbeat(schedule.LastHeartbeat, maxAge)
+	return func(ctx context.Context) error {
+		if !schedule.IsRunning() {
+			return fmt.Errorf("scheduler is not running")
+		}
+		return heartbeat(ctx)
+	}
+}
@@ -0,0 +1,58 @@
+package onyx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	app := New()
+	app.HealthEndpoints("")
+
+	cache := NewMemoryCache()
+	app.Health().Register("cache", CacheHealthCheck(cache))
+	app.Health().Register("mail", func(ctx context.Context) error {
+		return errors.New("smtp unreachable")
+	}, HealthNonCritical())
+
+	request := func(path string) (int, HealthReport) {
+		w := httptest.NewRecorder()
+		app.Router().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var report HealthReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s returned invalid JSON: %v", path, err)
+		}
+		return w.Code, report
+	}
+
+	code, report := request("/health/ready")
+	if code != http.StatusOK || report.Status != HealthDegraded {
+		t.Errorf("Expected a degraded but ready application, got %d %s", code, report.Status)
+	}
+	if report.Checks["cache"].Status != HealthUp || report.Checks["mail"].Error != "smtp unreachable" {
+		t.Errorf("Unexpected checks %+v", report.Checks)
+	}
+
+	schedule := setupTestScheduler(t)
+	app.Health().Register("scheduler", SchedulerHealthCheck(schedule, time.Minute))
+	if code, report := request("/health/ready"); code != http.StatusServiceUnavailable || report.Checks["scheduler"].Error != "scheduler is not running" {
+		t.Errorf("Expected a stopped scheduler to fail readiness, got %d %+v", code, report.Checks["scheduler"])
+	}
+
+	if err := schedule.Start(); err != nil {
+		t.Fatalf("Failed to start scheduler: %v", err)
+	}
+	defer schedule.Stop()
+	if code, report := request("/health/ready"); code != http.StatusOK || report.Checks["scheduler"].Status != HealthUp {
+		t.Errorf("Expected a running scheduler to pass, got %d %+v", code, report.Checks["scheduler"])
+	}
+
+	if code, report := request("/health/live"); code != http.StatusOK || len(report.Checks) != 0 {
+		t.Errorf("Expected liveness to run no checks, got %d %+v", code, report.Checks)
+	}
+}
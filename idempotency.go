@@ -0,0 +1,40 @@
+package onyx
+
+import (
+	"github.com/onyx-go/framework/internal/cache"
+	"github.com/onyx-go/framework/internal/http/middleware/idempotency"
+)
+
+// CacheStore is a cache store from the cache package, re-exported for
+// middleware that keeps state in one
+type CacheStore = cache.Store
+
+// IdempotencyConfig configures IdempotencyMiddleware
+type IdempotencyConfig = idempotency.Config
+
+// NewMemoryCacheStore returns an in-process store holding at most size items
+func NewMemoryCacheStore(size int) (CacheStore, error) {
+	return cache.NewMemoryStore(cache.MemoryConfig{Size: size})
+}
+
+// NewFileCacheStore returns a store keeping items as files under path, which
+// processes sharing the directory see alike
+func NewFileCacheStore(path string) (CacheStore, error) {
+	return cache.NewFileStore(cache.FileConfig{Path: path})
+}
+
+// IdempotencyMiddleware makes POST and PATCH requests carrying an
+// Idempotency-Key header safe to retry. The first response for a key is
+// stored in config.Store and replayed, with an Idempotent-Replayed header,
+// for retries of the same request. Retries arriving while the first request
+// is running get 409, and a key reused for a different request gets 422.
+func IdempotencyMiddleware(config ...IdempotencyConfig) MiddlewareFunc {
+	var cfg IdempotencyConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	middleware := idempotency.Middleware(cfg)
+	return func(c Context) error {
+		return middleware(c)
+	}
+}
@@ -0,0 +1,43 @@
+package onyx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	store, err := NewFileCacheStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	app := New()
+	orders := 0
+	app.PostHandler("/orders", func(c Context) error {
+		orders++
+		return c.JSON(http.StatusCreated, map[string]int{"id": orders})
+	}, IdempotencyMiddleware(IdempotencyConfig{Store: store}))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", "order-1")
+		w := httptest.NewRecorder()
+		app.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	first := post(`{"item":"pizza"}`)
+	retry := post(`{"item":"pizza"}`)
+	if orders != 1 || retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the retry to replay the first order, got %d orders and %d %s", orders, retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("Expected the replayed response to be marked")
+	}
+
+	if w := post(`{"item":"salad"}`); w.Code != http.StatusUnprocessableEntity || orders != 1 {
+		t.Errorf("Expected 422 for a reused key, got %d with %d orders", w.Code, orders)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // cache implements the Cache interface
@@ -24,19 +26,25 @@ func NewCache(store Store, metrics Metrics) Cache {
 
 // GetContext retrieves a value from cache with context
 func (c *cache) GetContext(ctx context.Context, key string) (interface{}, error) {
+	ctx, span := c.startSpan(ctx, "cache.get", key)
+	defer span.End()
+	
 	item, err := c.store.Get(ctx, key)
 	if err != nil {
 		c.metrics.RecordMiss(c.store.GetInfo().Name)
+		span.SetAttribute("cache.hit", false)
 		return nil, fmt.Errorf("cache miss: %w", err)
 	}
 	
 	if item.IsExpired() {
 		c.store.Delete(ctx, key)
 		c.metrics.RecordMiss(c.store.GetInfo().Name)
+		span.SetAttribute("cache.hit", false)
 		return nil, fmt.Errorf("cache miss: expired")
 	}
 	
 	c.metrics.RecordHit(c.store.GetInfo().Name)
+	span.SetAttribute("cache.hit", true)
 	return item.Value, nil
 }
 
@@ -53,14 +61,26 @@ func (c *cache) PutContext(ctx context.Context, key string, value interface{}, d
 		ExpiresAt: expiresAt,
 	}
 	
+	ctx, span := c.startSpan(ctx, "cache.put", key)
+	defer span.End()
+	
 	err := c.store.Put(ctx, key, item)
 	if err == nil {
 		c.metrics.RecordWrite(c.store.GetInfo().Name, key, int64(len(fmt.Sprintf("%v", value))))
 	}
+	span.RecordError(err)
 	
 	return err
 }
 
+// startSpan starts a span for a cache operation on key
+func (c *cache) startSpan(ctx context.Context, name, key string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, name, tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(map[string]interface{}{
+		"cache.store": c.store.GetInfo().Name,
+		"cache.key":   key,
+	}))
+}
+
 // ForeverContext stores a value in cache permanently with context
 func (c *cache) ForeverContext(ctx context.Context, key string, value interface{}) error {
 	return c.PutContext(ctx, key, value, 0)
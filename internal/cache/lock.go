@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Adder is implemented by stores that can write an item only when its key is
+// absent, in one step
+type Adder interface {
+	Add(ctx context.Context, key string, item *Item) (bool, error)
+}
+
+// storeLock implements Lock on top of a Store. Each lock holds a random owner
+// token, so it only releases or extends locks it acquired itself.
+type storeLock struct {
+	store Store
+	owner string
+}
+
+// NewLock returns a Lock kept in store. Locks are atomic when the store is an
+// Adder, as the built-in stores are; with other stores two callers racing for
+// a free lock may both acquire it.
+func NewLock(store Store) Lock {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return &storeLock{store: store, owner: hex.EncodeToString(token)}
+}
+
+// Acquire takes the lock on key for ttl, reporting false if someone else
+// holds it
+func (l *storeLock) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	item := &Item{Key: key, Value: l.owner}
+	if ttl > 0 {
+		item.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	if adder, ok := l.store.(Adder); ok {
+		return adder.Add(ctx, key, item)
+	}
+	if l.store.Exists(ctx, key) {
+		return false, nil
+	}
+	if err := l.store.Put(ctx, key, item); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release frees the lock on key if this lock holds it
+func (l *storeLock) Release(ctx context.Context, key string) error {
+	if !l.owns(ctx, key) {
+		return nil
+	}
+	return l.store.Delete(ctx, key)
+}
+
+// Extend pushes back the expiry of a lock this lock holds
+func (l *storeLock) Extend(ctx context.Context, key string, ttl time.Duration) error {
+	if !l.owns(ctx, key) {
+		return fmt.Errorf("lock %s is not held", key)
+	}
+	return l.store.Touch(ctx, key, ttl)
+}
+
+func (l *storeLock) owns(ctx context.Context, key string) bool {
+	item, err := l.store.Get(ctx, key)
+	return err == nil && item.Value == l.owner
+}
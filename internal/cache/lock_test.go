@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	memory, _ := NewMemoryStore(MemoryConfig{Size: 10})
+	defer memory.(*MemoryStore).Close()
+	file, err := NewFileStore(FileConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	for name, store := range map[string]Store{"memory": memory, "file": file} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			first, second := NewLock(store), NewLock(store)
+
+			if ok, err := first.Acquire(ctx, "job", time.Minute); !ok || err != nil {
+				t.Fatalf("Expected to acquire a free lock, got %v %v", ok, err)
+			}
+			if ok, _ := second.Acquire(ctx, "job", time.Minute); ok {
+				t.Error("Expected a held lock to be refused")
+			}
+
+			// Only the holder may release or extend it
+			second.Release(ctx, "job")
+			if err := second.Extend(ctx, "job", time.Minute); err == nil {
+				t.Error("Expected extending someone else's lock to fail")
+			}
+			if ok, _ := second.Acquire(ctx, "job", time.Minute); ok {
+				t.Error("Expected the lock to survive a release by another owner")
+			}
+
+			if err := first.Release(ctx, "job"); err != nil {
+				t.Fatalf("Release failed: %v", err)
+			}
+			if ok, _ := second.Acquire(ctx, "job", time.Minute); !ok {
+				t.Error("Expected a released lock to be free")
+			}
+
+			// An expired lock is free again
+			first.Acquire(ctx, "short", 10*time.Millisecond)
+			time.Sleep(20 * time.Millisecond)
+			if ok, _ := second.Acquire(ctx, "short", time.Minute); !ok {
+				t.Error("Expected an expired lock to be free")
+			}
+		})
+	}
+}
+
+func TestLock_Concurrent(t *testing.T) {
+	store, _ := NewMemoryStore(MemoryConfig{Size: 10})
+	defer store.(*MemoryStore).Close()
+
+	var acquired atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := NewLock(store).Acquire(context.Background(), "job", time.Minute); ok {
+				acquired.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if acquired.Load() != 1 {
+		t.Errorf("Expected exactly one holder, got %d", acquired.Load())
+	}
+}
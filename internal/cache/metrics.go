@@ -3,6 +3,16 @@ package cache
 import (
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/metrics"
+)
+
+// Counters reported to the metrics registry, summed over every cache
+var (
+	hitsTotal    = metrics.Default().NewCounter("onyx_cache_hits_total", "Cache lookups that found a value.", "store")
+	missesTotal  = metrics.Default().NewCounter("onyx_cache_misses_total", "Cache lookups that found nothing or an expired value.", "store")
+	writesTotal  = metrics.Default().NewCounter("onyx_cache_writes_total", "Values written to the cache.", "store")
+	deletesTotal = metrics.Default().NewCounter("onyx_cache_deletes_total", "Values deleted from the cache.", "store")
 )
 
 // SimpleMetrics provides basic metrics collection for cache operations
@@ -33,6 +43,8 @@ func NewSimpleMetrics() *SimpleMetrics {
 
 // RecordHit records a cache hit
 func (sm *SimpleMetrics) RecordHit(store string) {
+	hitsTotal.With(store).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -43,6 +55,8 @@ func (sm *SimpleMetrics) RecordHit(store string) {
 
 // RecordMiss records a cache miss
 func (sm *SimpleMetrics) RecordMiss(store string) {
+	missesTotal.With(store).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -53,6 +67,8 @@ func (sm *SimpleMetrics) RecordMiss(store string) {
 
 // RecordWrite records a cache write operation
 func (sm *SimpleMetrics) RecordWrite(store string, key string, size int64) {
+	writesTotal.With(store).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -65,6 +81,8 @@ func (sm *SimpleMetrics) RecordWrite(store string, key string, size int64) {
 
 // RecordDelete records a cache delete operation
 func (sm *SimpleMetrics) RecordDelete(store string, key string) {
+	deletesTotal.With(store).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
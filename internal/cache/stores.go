@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -157,6 +158,25 @@ func (ms *MemoryStore) Put(ctx context.Context, key string, item *Item) error {
 	ms.mutex.Lock()
 	defer ms.mutex.Unlock()
 
+	ms.put(key, item)
+	return nil
+}
+
+// Add stores an item only if the key is missing or expired
+func (ms *MemoryStore) Add(ctx context.Context, key string, item *Item) (bool, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if existing, exists := ms.items[key]; exists && !existing.IsExpired() {
+		return false, nil
+	}
+
+	ms.put(key, item)
+	return true, nil
+}
+
+// put stores an item; the caller holds the write lock
+func (ms *MemoryStore) put(key string, item *Item) {
 	// Check if we need to evict items
 	if len(ms.items) >= ms.config.Size {
 		if evictKey, shouldEvict := ms.evictionPolicy.ShouldEvict(len(ms.items), ms.config.Size); shouldEvict {
@@ -174,8 +194,6 @@ func (ms *MemoryStore) Put(ctx context.Context, key string, item *Item) error {
 	ms.items[key] = item
 	ms.evictionPolicy.OnAdd(key)
 	ms.stats.writes++
-
-	return nil
 }
 
 // Delete removes an item from memory
@@ -430,7 +448,7 @@ func (fs *FileStore) Get(ctx context.Context, key string) (*Item, error) {
 
 	if item.IsExpired() {
 		fs.stats.misses++
-		os.Remove(filename) // Clean up expired file
+		fs.removeIfUnchanged(filename, data) // Clean up expired file
 		return nil, fmt.Errorf("key expired")
 	}
 
@@ -465,6 +483,105 @@ func (fs *FileStore) Put(ctx context.Context, key string, item *Item) error {
 	return nil
 }
 
+// staleFileAge is how long a cache file may fail to parse before Add treats
+// it as expired. A file another process is still writing is never replaced.
+const staleFileAge = time.Minute
+
+// Add writes an item file only if the key is missing or expired. The item is
+// written to a temporary file and linked into place, which fails if the key
+// exists, so other processes sharing the directory never see it half written.
+func (fs *FileStore) Add(ctx context.Context, key string, item *Item) (bool, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	data, err := fs.serializer.Serialize(item)
+	if err != nil {
+		return false, fmt.Errorf("failed to serialize item: %v", err)
+	}
+
+	temp, err := os.CreateTemp(fs.config.Path, ".add-*.tmp")
+	if err != nil {
+		return false, fmt.Errorf("failed to write cache file: %v", err)
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write cache file: %v", err)
+	}
+
+	filename := fs.keyToFilename(key)
+	for attempt := 0; attempt < 3; attempt++ {
+		err := os.Link(temp.Name(), filename)
+		if err == nil {
+			fs.stats.writes++
+			fs.stats.size += int64(len(data))
+			return true, nil
+		}
+		if !os.IsExist(err) {
+			return false, fmt.Errorf("failed to write cache file: %v", err)
+		}
+
+		existing, err := os.ReadFile(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read cache file: %v", err)
+		}
+		if !fs.expired(filename, existing) {
+			return false, nil
+		}
+		if _, err := fs.removeIfUnchanged(filename, existing); err != nil {
+			return false, err
+		}
+	}
+
+	// The key kept changing hands; someone else holds it now
+	return false, nil
+}
+
+// expired reports whether the cache file holding data has expired. A file
+// that does not parse only counts once it is older than staleFileAge.
+func (fs *FileStore) expired(filename string, data []byte) bool {
+	var item Item
+	if err := fs.serializer.Unserialize(data, &item); err == nil {
+		return item.IsExpired()
+	}
+	info, err := os.Stat(filename)
+	return err == nil && time.Since(info.ModTime()) > staleFileAge
+}
+
+// removeIfUnchanged removes filename if it still holds data. The file is
+// renamed aside first and checked there, so a file another process put in its
+// place after data was read is restored rather than deleted.
+func (fs *FileStore) removeIfUnchanged(filename string, data []byte) (bool, error) {
+	aside, err := os.CreateTemp(fs.config.Path, ".expired-*.tmp")
+	if err != nil {
+		return false, fmt.Errorf("failed to remove cache file: %v", err)
+	}
+	aside.Close()
+	defer os.Remove(aside.Name())
+
+	if err := os.Rename(filename, aside.Name()); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to remove cache file: %v", err)
+	}
+
+	moved, err := os.ReadFile(aside.Name())
+	if err == nil && bytes.Equal(moved, data) {
+		return true, nil
+	}
+	if err := os.Link(aside.Name(), filename); err != nil && !os.IsExist(err) {
+		return false, fmt.Errorf("failed to restore cache file: %v", err)
+	}
+	return false, nil
+}
+
 // Delete removes an item file
 func (fs *FileStore) Delete(ctx context.Context, key string) error {
 	fs.mutex.Lock()
@@ -2,9 +2,12 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -372,6 +375,72 @@ func TestFileStore_Expiration(t *testing.T) {
 	}
 }
 
+func TestFileStore_Add(t *testing.T) {
+	tempDir := t.TempDir()
+	ctx := context.Background()
+	store, err := NewFileStore(FileConfig{Path: tempDir})
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	fileStore := store.(*FileStore)
+	filename := fileStore.keyToFilename("lock")
+
+	// A lock another process has created but not yet written is held
+	if err := os.WriteFile(filename, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := fileStore.Add(ctx, "lock", &Item{Key: "lock", Value: "mine"}); ok || err != nil {
+		t.Fatalf("Expected an unwritten lock to be held, got %v %v", ok, err)
+	}
+	if _, err := os.Stat(filename); err != nil {
+		t.Fatalf("Expected the unwritten lock to be left alone: %v", err)
+	}
+
+	// Until it has been unreadable for too long
+	old := time.Now().Add(-2 * staleFileAge)
+	os.Chtimes(filename, old, old)
+	if ok, err := fileStore.Add(ctx, "lock", &Item{Key: "lock", Value: "mine"}); !ok || err != nil {
+		t.Fatalf("Expected a stale unreadable lock to be replaced, got %v %v", ok, err)
+	}
+
+	// An expired lock replaced by someone else after it was read is restored
+	expired, _ := json.Marshal(&Item{Key: "lock", Value: "old", ExpiresAt: time.Now().Add(-time.Second)})
+	fresh, _ := json.Marshal(&Item{Key: "lock", Value: "theirs", ExpiresAt: time.Now().Add(time.Minute)})
+	os.WriteFile(filename, fresh, 0644)
+	if removed, err := fileStore.removeIfUnchanged(filename, expired); removed || err != nil {
+		t.Fatalf("Expected a changed file to be kept, got %v %v", removed, err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != string(fresh) {
+		t.Fatalf("Expected the other lock to be restored, got %s", data)
+	}
+
+	// Processes racing for an expired lock: exactly one takes it
+	for round := 0; round < 20; round++ {
+		os.WriteFile(filename, expired, 0644)
+
+		var acquired atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				process, _ := NewFileStore(FileConfig{Path: tempDir})
+				if ok, _ := process.(*FileStore).Add(ctx, "lock", &Item{Key: "lock", Value: fmt.Sprint(i)}); ok {
+					acquired.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		if acquired.Load() != 1 {
+			t.Fatalf("Expected exactly one process to take the expired lock, got %d", acquired.Load())
+		}
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(tempDir, "*.tmp")); len(matches) != 0 {
+		t.Errorf("Expected temporary files to be cleaned up, got %v", matches)
+	}
+}
+
 func TestFileStore_Clear(t *testing.T) {
 	tempDir := t.TempDir()
 	config := FileConfig{
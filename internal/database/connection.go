@@ -1,8 +1,11 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 
+	"github.com/onyx-go/framework/internal/database/grammar"
+
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
@@ -12,6 +15,7 @@ import (
 type DB struct {
 	*sql.DB
 	driver string
+	ctx    context.Context
 }
 
 // NewDB creates a new database connection
@@ -36,21 +40,38 @@ func (db *DB) Driver() string {
 	return db.driver
 }
 
+// Grammar returns the SQL dialect for the connection's driver
+func (db *DB) Grammar() grammar.Grammar {
+	return grammar.For(db.driver)
+}
+
+// WithContext returns a copy of the connection whose queries, including
+// those of the query builders it creates, run under ctx
+func (db *DB) WithContext(ctx context.Context) Database {
+	return &DB{DB: db.DB, driver: db.driver, ctx: ctx}
+}
+
+// context returns the context queries run under when none is given
+func (db *DB) context() context.Context {
+	if db.ctx == nil {
+		return context.Background()
+	}
+	return db.ctx
+}
+
+// connection runs the statements a query builder produces. DB and Tx
+// implement it.
+type connection interface {
+	Driver() string
+	Grammar() grammar.Grammar
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // Table creates a new query builder for the specified table
 func (db *DB) Table(tableName string) QueryBuilder {
-	return &queryBuilder{
-		db:              db,
-		table:           tableName,
-		selects:         []string{},
-		wheres:          []whereClause{},
-		orders:          []string{},
-		joins:           []string{},
-		groupBy:         []string{},
-		having:          []whereClause{},
-		bindings:        []interface{}{},
-		eagerLoad:       make(map[string]interface{}),
-		includeDeleted:  false,
-	}
+	return newQueryBuilder(db.context(), db, tableName)
 }
 
 // Model creates a new query builder for the specified model
@@ -75,22 +96,22 @@ func (db *DB) Close() error {
 
 // Ping verifies the database connection is still alive
 func (db *DB) Ping() error {
-	return db.DB.Ping()
+	return db.DB.PingContext(db.context())
 }
 
 // Exec executes a query that doesn't return rows
 func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return db.DB.Exec(query, args...)
+	return db.DB.ExecContext(db.context(), query, args...)
 }
 
 // Query executes a query that returns rows
 func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return db.DB.Query(query, args...)
+	return db.DB.QueryContext(db.context(), query, args...)
 }
 
 // QueryRow executes a query that is expected to return at most one row
 func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
-	return db.DB.QueryRow(query, args...)
+	return db.DB.QueryRowContext(db.context(), query, args...)
 }
 
 // Begin starts a transaction
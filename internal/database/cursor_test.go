@@ -0,0 +1,87 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type cursorPost struct {
+	ID    int    `db:"id"`
+	Title string `db:"title"`
+}
+
+func TestChunkAndCursor(t *testing.T) {
+	db := newTestDB(t)
+	for i := 1; i <= 5; i++ {
+		if _, err := db.Table("posts").Insert(map[string]interface{}{"title": fmt.Sprintf("post %d", i)}); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+
+	var chunk []cursorPost
+	var sizes []int
+	if err := db.Table("posts").Chunk(2, &chunk, func() error {
+		sizes = append(sizes, len(chunk))
+		return nil
+	}); err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Errorf("Expected chunks of [2 2 1], got %v", sizes)
+	}
+
+	var ids []int
+	if err := db.Table("posts").ChunkByID(2, "posts.id", &chunk, func() error {
+		for _, post := range chunk {
+			ids = append(ids, post.ID)
+			if _, err := db.Table("posts").Where("id", "=", post.ID).ForceDelete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ChunkByID failed: %v", err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("Expected every row once while deleting them, got %v", ids)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if _, err := db.Table("posts").Insert(map[string]interface{}{"title": fmt.Sprintf("new %d", i)}); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	// The keyset condition must apply to both sides of the OR
+	var matched []string
+	if err := db.Table("posts").Where("title", "=", "new 1").OrWhere("title", "=", "new 3").ChunkByID(1, "id", &chunk, func() error {
+		if len(matched) > 3 {
+			return errors.New("chunking did not stop")
+		}
+		matched = append(matched, chunk[0].Title)
+		return nil
+	}); err != nil || fmt.Sprint(matched) != "[new 1 new 3]" {
+		t.Errorf("Expected [new 1 new 3] with OrWhere, got %v %v", matched, err)
+	}
+	if _, err := db.Table("posts").Where("title", "LIKE", "new%").ForceDelete(); err != nil {
+		t.Fatalf("ForceDelete failed: %v", err)
+	}
+
+	if _, err := db.Table("posts").Insert(map[string]interface{}{"title": "last"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	var titles []string
+	for post, err := range Iterate[cursorPost](db.Table("posts")) {
+		if err != nil {
+			t.Fatalf("Iterate failed: %v", err)
+		}
+		titles = append(titles, post.Title)
+	}
+	if fmt.Sprint(titles) != "[last]" {
+		t.Errorf("Expected the remaining row, got %v", titles)
+	}
+
+	if err := db.Raw("SELECT * FROM posts").Chunk(2, &chunk, func() error { return nil }); err == nil {
+		t.Error("Expected raw queries to be rejected by Chunk")
+	}
+}
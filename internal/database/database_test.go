@@ -174,6 +174,44 @@ func TestQueryBuilderCreation(t *testing.T) {
 	}
 }
 
+func TestQueryBuilderGrammar(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"postgres", `SELECT "id", "order" FROM "users" WHERE "order" = $1 AND "status" IN ($2, $3) AND "deleted_at" IS NULL ORDER BY "users"."created_at" DESC LIMIT 10 OFFSET 5`},
+		{"mysql", "SELECT `id`, `order` FROM `users` WHERE `order` = ? AND `status` IN (?, ?) AND `deleted_at` IS NULL ORDER BY `users`.`created_at` DESC LIMIT 10 OFFSET 5"},
+		{"sqlite3", `SELECT "id", "order" FROM "users" WHERE "order" = ? AND "status" IN (?, ?) AND "deleted_at" IS NULL ORDER BY "users"."created_at" DESC LIMIT 10 OFFSET 5`},
+	}
+
+	for _, tt := range tests {
+		db := &DB{driver: tt.driver}
+		query, args, err := db.Table("users").
+			Select("id", "order").
+			Where("order", "=", 1).
+			WhereIn("status", []interface{}{"new", "paid"}).
+			OrderBy("users.created_at", "DESC").
+			Limit(10).
+			Offset(5).
+			ToSQL()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.driver, err)
+		}
+		if query != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.driver, query, tt.want)
+		}
+		if len(args) != 3 {
+			t.Errorf("%s: expected 3 args, got %v", tt.driver, args)
+		}
+	}
+
+	// Raw queries are passed through as written
+	query, _, _ := (&DB{driver: "postgres"}).Raw("SELECT * FROM users WHERE id = $1", 1).ToSQL()
+	if query != "SELECT * FROM users WHERE id = $1" {
+		t.Errorf("Expected raw SQL to be left alone, got %s", query)
+	}
+}
+
 func TestSoftDeleteConfig(t *testing.T) {
 	config := DefaultSoftDeleteConfig()
 
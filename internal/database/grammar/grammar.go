@@ -0,0 +1,203 @@
+// Package grammar holds what differs between the SQL dialects the framework
+// speaks: bind parameter style, identifier quoting, LIMIT/OFFSET syntax and
+// how generated keys come back from an INSERT. The query builders and the
+// migration SQL generators build statements with "?" placeholders and
+// unquoted names and pass them through the grammar for the connection's
+// driver.
+package grammar
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Grammar renders the dialect-specific parts of a statement
+type Grammar interface {
+	// Name is the dialect: "mysql", "postgres" or "sqlite"
+	Name() string
+
+	// Placeholder returns the bind parameter for the n-th argument,
+	// counting from 1
+	Placeholder(n int) string
+
+	// Wrap quotes an identifier such as "users", "users.id" or
+	// "name as author". "*", expressions and already quoted names are left
+	// alone.
+	Wrap(identifier string) string
+
+	// Limit renders the LIMIT and OFFSET clauses with a leading space, or ""
+	// when both are zero
+	Limit(limit, offset int) string
+
+	// SupportsReturning reports whether INSERT ... RETURNING is how
+	// generated keys are read, instead of LastInsertId
+	SupportsReturning() bool
+
+	// NullsFirst reports whether NULL sorts before every other value in an
+	// ascending ORDER BY, and so after them in a descending one
+	NullsFirst() bool
+}
+
+// The built-in grammars
+var (
+	MySQL    Grammar = &mysql{}
+	Postgres Grammar = &postgres{}
+	SQLite   Grammar = &sqlite{}
+)
+
+// For returns the grammar for a database/sql driver name. Unknown drivers
+// get the MySQL grammar, matching the migration generators' fallback.
+func For(driver string) Grammar {
+	switch driver {
+	case "postgres", "postgresql", "pgx":
+		return Postgres
+	case "sqlite3", "sqlite":
+		return SQLite
+	default:
+		return MySQL
+	}
+}
+
+// Rebind rewrites the "?" placeholders in query into the grammar's style.
+// Question marks inside quoted strings and identifiers are left alone.
+func Rebind(g Grammar, query string) string {
+	if g.Placeholder(1) == "?" || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '?':
+			n++
+			b.WriteString(g.Placeholder(n))
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// Columnize wraps and joins a list of identifiers
+func Columnize(g Grammar, identifiers []string) string {
+	wrapped := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		wrapped[i] = g.Wrap(identifier)
+	}
+	return strings.Join(wrapped, ", ")
+}
+
+// Parameters returns n comma-separated "?" placeholders
+func Parameters(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
+
+type mysql struct{}
+
+func (mysql) Name() string                  { return "mysql" }
+func (mysql) Placeholder(n int) string      { return "?" }
+func (mysql) Wrap(identifier string) string { return wrap(identifier, '`') }
+func (mysql) SupportsReturning() bool       { return false }
+func (mysql) NullsFirst() bool              { return true }
+
+// Limit uses the largest row count MySQL accepts when only an offset is set
+func (mysql) Limit(limit, offset int) string {
+	if limit <= 0 && offset > 0 {
+		return " LIMIT 18446744073709551615 OFFSET " + strconv.Itoa(offset)
+	}
+	return limitOffset(limit, offset)
+}
+
+type postgres struct{}
+
+func (postgres) Name() string                   { return "postgres" }
+func (postgres) Placeholder(n int) string       { return "$" + strconv.Itoa(n) }
+func (postgres) Wrap(identifier string) string  { return wrap(identifier, '"') }
+func (postgres) Limit(limit, offset int) string { return limitOffset(limit, offset) }
+func (postgres) SupportsReturning() bool        { return true }
+func (postgres) NullsFirst() bool               { return false }
+
+type sqlite struct{}
+
+func (sqlite) Name() string                  { return "sqlite" }
+func (sqlite) Placeholder(n int) string      { return "?" }
+func (sqlite) Wrap(identifier string) string { return wrap(identifier, '"') }
+func (sqlite) SupportsReturning() bool       { return false }
+func (sqlite) NullsFirst() bool              { return true }
+
+// Limit uses SQLite's "no limit" when only an offset is set
+func (sqlite) Limit(limit, offset int) string {
+	if limit <= 0 && offset > 0 {
+		return " LIMIT -1 OFFSET " + strconv.Itoa(offset)
+	}
+	return limitOffset(limit, offset)
+}
+
+func limitOffset(limit, offset int) string {
+	var clause string
+	if limit > 0 {
+		clause += " LIMIT " + strconv.Itoa(limit)
+	}
+	if offset > 0 {
+		clause += " OFFSET " + strconv.Itoa(offset)
+	}
+	return clause
+}
+
+// wrap quotes each dot-separated segment of identifier with quote, handling
+// "expr as alias"
+func wrap(identifier string, quote byte) string {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" || identifier == "*" {
+		return identifier
+	}
+
+	if i := strings.LastIndex(strings.ToLower(identifier), " as "); i > 0 {
+		expression, alias := identifier[:i], strings.TrimSpace(identifier[i+4:])
+		// Skip "as" inside an expression, e.g. CAST(x AS int)
+		if !strings.ContainsAny(alias, " ()'\"`") && strings.Count(expression, "(") == strings.Count(expression, ")") {
+			return wrap(expression, quote) + " AS " + wrapSegment(alias, quote)
+		}
+	}
+
+	// Expressions, function calls and quoted names are used as written
+	if strings.ContainsAny(identifier, " ()'\"`+-/<>=,") {
+		return identifier
+	}
+
+	segments := strings.Split(identifier, ".")
+	for i, segment := range segments {
+		segments[i] = wrapSegment(segment, quote)
+	}
+	return strings.Join(segments, ".")
+}
+
+func wrapSegment(segment string, quote byte) string {
+	if segment == "*" || segment == "" || isNumber(segment) {
+		return segment
+	}
+	q := string(quote)
+	return q + strings.ReplaceAll(segment, q, q+q) + q
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package grammar
+
+import "testing"
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		grammar    Grammar
+		identifier string
+		want       string
+	}{
+		{MySQL, "order", "`order`"},
+		{Postgres, "user", `"user"`},
+		{SQLite, "users.id", `"users"."id"`},
+		{Postgres, "users.*", `"users".*`},
+		{Postgres, "*", "*"},
+		{MySQL, "name as author", "`name` AS `author`"},
+		{Postgres, "COUNT(*) as count", `COUNT(*) AS "count"`},
+		{Postgres, "CAST(total AS integer)", "CAST(total AS integer)"},
+		{Postgres, `"already"`, `"already"`},
+		{SQLite, "1", "1"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.grammar.Wrap(tt.identifier); got != tt.want {
+			t.Errorf("%s.Wrap(%q) = %s, want %s", tt.grammar.Name(), tt.identifier, got, tt.want)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := `SELECT * FROM "t" WHERE a = ? AND b = '?' AND "c?" IN (?, ?)`
+
+	if got := Rebind(MySQL, query); got != query {
+		t.Errorf("MySQL rebind changed the query: %s", got)
+	}
+	want := `SELECT * FROM "t" WHERE a = $1 AND b = '?' AND "c?" IN ($2, $3)`
+	if got := Rebind(Postgres, query); got != want {
+		t.Errorf("Postgres rebind = %s, want %s", got, want)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		grammar       Grammar
+		limit, offset int
+		want          string
+	}{
+		{Postgres, 0, 0, ""},
+		{Postgres, 10, 20, " LIMIT 10 OFFSET 20"},
+		{Postgres, 0, 20, " OFFSET 20"},
+		{MySQL, 0, 20, " LIMIT 18446744073709551615 OFFSET 20"},
+		{SQLite, 0, 20, " LIMIT -1 OFFSET 20"},
+		{SQLite, 5, 0, " LIMIT 5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.grammar.Limit(tt.limit, tt.offset); got != tt.want {
+			t.Errorf("%s.Limit(%d, %d) = %q, want %q", tt.grammar.Name(), tt.limit, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestNullsFirst(t *testing.T) {
+	if !MySQL.NullsFirst() || !SQLite.NullsFirst() || Postgres.NullsFirst() {
+		t.Error("Expected NULLs first in MySQL and SQLite and last in PostgreSQL")
+	}
+}
+
+func TestFor(t *testing.T) {
+	for driver, want := range map[string]Grammar{"postgres": Postgres, "pgx": Postgres, "sqlite3": SQLite, "mysql": MySQL, "": MySQL} {
+		if got := For(driver); got != want {
+			t.Errorf("For(%q) = %s, want %s", driver, got.Name(), want.Name())
+		}
+	}
+}
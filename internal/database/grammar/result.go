@@ -0,0 +1,68 @@
+package grammar
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Returning is appended to an INSERT on grammars that support RETURNING,
+// so the inserted row's id can be read whether or not the table has one
+const Returning = " RETURNING *"
+
+// InsertResult is the sql.Result of an INSERT ... RETURNING, read with
+// ScanInsert
+type InsertResult struct {
+	ID   int64
+	Rows int64
+}
+
+// LastInsertId returns the id column of the last inserted row, or 0 when
+// the table has no integer id column
+func (r InsertResult) LastInsertId() (int64, error) {
+	return r.ID, nil
+}
+
+// RowsAffected returns the number of rows inserted
+func (r InsertResult) RowsAffected() (int64, error) {
+	return r.Rows, nil
+}
+
+// ScanInsert reads the rows returned by an INSERT ending in Returning and
+// closes them
+func ScanInsert(rows *sql.Rows) (InsertResult, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return InsertResult{}, err
+	}
+	idIndex := -1
+	for i, column := range columns {
+		if column == "id" {
+			idIndex = i
+		}
+	}
+
+	var result InsertResult
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return InsertResult{}, err
+		}
+		result.Rows++
+		if idIndex >= 0 {
+			switch id := (*values[idIndex].(*interface{})).(type) {
+			case int64:
+				result.ID = id
+			case int32:
+				result.ID = int64(id)
+			case []byte:
+				fmt.Sscan(string(id), &result.ID)
+			}
+		}
+	}
+	return result, rows.Err()
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"time"
 )
@@ -37,6 +38,13 @@ type Database interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
+	
+	// Transactions
+	Transaction(ctx context.Context, fn func(tx Database) error, opts ...TxOption) error
+	AfterCommit(fn func())
+	
+	// Cancellation
+	WithContext(ctx context.Context) Database
 }
 
 // QueryBuilder interface defines the contract for query building
@@ -86,6 +94,10 @@ type QueryBuilder interface {
 	WithTrashed() QueryBuilder
 	OnlyTrashed() QueryBuilder
 	
+	// Cancellation
+	WithContext(ctx context.Context) QueryBuilder
+	Timeout(timeout time.Duration) QueryBuilder
+	
 	// Execution
 	Get(dest interface{}) error
 	First(dest interface{}) error
@@ -97,6 +109,11 @@ type QueryBuilder interface {
 	Min(column string) (interface{}, error)
 	Max(column string) (interface{}, error)
 	
+	// Streaming
+	Chunk(size int, dest interface{}, fn func() error) error
+	ChunkByID(size int, column string, dest interface{}, fn func() error) error
+	Cursor() (*Cursor, error)
+	
 	// Mutation
 	Insert(data interface{}) (sql.Result, error)
 	Update(data interface{}) (sql.Result, error)
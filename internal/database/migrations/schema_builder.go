@@ -310,16 +310,7 @@ func (sb *schemaBuilder) executeStatements(statements []string) error {
 
 // getDefaultSQLGenerator returns the default SQL generator for a driver
 func getDefaultSQLGenerator(driver string) SQLGenerator {
-	switch driver {
-	case "mysql":
-		return NewMySQLGenerator()
-	case "postgres":
-		return NewPostgreSQLGenerator()
-	case "sqlite3":
-		return NewSQLiteGenerator()
-	default:
-		return NewMySQLGenerator() // Default fallback
-	}
+	return newSQLGenerator(driver)
 }
 
 // Ensure schemaBuilder implements SchemaBuilder
@@ -3,25 +3,43 @@ package migrations
 import (
 	"fmt"
 	"strings"
+
+	"github.com/onyx-go/framework/internal/database/grammar"
 )
 
 // NewMySQLGenerator creates a new MySQL SQL generator
 func NewMySQLGenerator() SQLGenerator {
-	return &mysqlGenerator{}
+	return &mysqlGenerator{grammar: grammar.MySQL}
 }
 
 // NewPostgreSQLGenerator creates a new PostgreSQL SQL generator
 func NewPostgreSQLGenerator() SQLGenerator {
-	return &postgresqlGenerator{}
+	return &postgresqlGenerator{&mysqlGenerator{grammar: grammar.Postgres}}
 }
 
 // NewSQLiteGenerator creates a new SQLite SQL generator
 func NewSQLiteGenerator() SQLGenerator {
-	return &sqliteGenerator{}
+	return &sqliteGenerator{&mysqlGenerator{grammar: grammar.SQLite}}
+}
+
+// newSQLGenerator returns the built-in generator for a driver, falling back
+// to MySQL for unknown drivers
+func newSQLGenerator(driver string) SQLGenerator {
+	switch grammar.For(driver) {
+	case grammar.Postgres:
+		return NewPostgreSQLGenerator()
+	case grammar.SQLite:
+		return NewSQLiteGenerator()
+	default:
+		return NewMySQLGenerator()
+	}
 }
 
-// mysqlGenerator implements SQLGenerator for MySQL
-type mysqlGenerator struct{}
+// mysqlGenerator implements SQLGenerator for MySQL. The other generators
+// embed it with their own grammar, which quotes names and binds parameters.
+type mysqlGenerator struct {
+	grammar grammar.Grammar
+}
 
 // Table operations
 func (mg *mysqlGenerator) GenerateCreateTable(definition *TableDefinition) string {
@@ -40,7 +58,7 @@ func (mg *mysqlGenerator) GenerateCreateTable(definition *TableDefinition) strin
 		}
 	}
 	if len(primaryColumns) > 0 {
-		parts = append(parts, fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(primaryColumns, ", ")))
+		parts = append(parts, fmt.Sprintf("  PRIMARY KEY (%s)", grammar.Columnize(mg.grammar, primaryColumns)))
 	}
 	
 	// Add other indexes during table creation if needed
@@ -53,7 +71,7 @@ func (mg *mysqlGenerator) GenerateCreateTable(definition *TableDefinition) strin
 		}
 	}
 	
-	tableSQL := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", definition.Name, strings.Join(parts, ",\n"))
+	tableSQL := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", mg.grammar.Wrap(definition.Name), strings.Join(parts, ",\n"))
 	
 	// Add table options
 	var options []string
@@ -83,9 +101,9 @@ func (mg *mysqlGenerator) GenerateAlterTable(tableName string, definition *Table
 	// Add columns
 	for _, column := range definition.Columns {
 		if column.Change {
-			statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", tableName, mg.generateColumnSQL(column)))
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", mg.grammar.Wrap(tableName), mg.generateColumnSQL(column)))
 		} else {
-			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", tableName, mg.generateColumnSQL(column)))
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", mg.grammar.Wrap(tableName), mg.generateColumnSQL(column)))
 		}
 	}
 	
@@ -94,30 +112,30 @@ func (mg *mysqlGenerator) GenerateAlterTable(tableName string, definition *Table
 
 func (mg *mysqlGenerator) GenerateDropTable(tableName string, ifExists bool) string {
 	if ifExists {
-		return fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+		return fmt.Sprintf("DROP TABLE IF EXISTS %s", mg.grammar.Wrap(tableName))
 	}
-	return fmt.Sprintf("DROP TABLE %s", tableName)
+	return fmt.Sprintf("DROP TABLE %s", mg.grammar.Wrap(tableName))
 }
 
 func (mg *mysqlGenerator) GenerateRenameTable(oldName, newName string) string {
-	return fmt.Sprintf("RENAME TABLE %s TO %s", oldName, newName)
+	return fmt.Sprintf("RENAME TABLE %s TO %s", mg.grammar.Wrap(oldName), mg.grammar.Wrap(newName))
 }
 
 // Column operations
 func (mg *mysqlGenerator) GenerateAddColumn(tableName string, column ColumnDefinition) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", tableName, mg.generateColumnSQL(column))
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", mg.grammar.Wrap(tableName), mg.generateColumnSQL(column))
 }
 
 func (mg *mysqlGenerator) GenerateModifyColumn(tableName string, column ColumnDefinition) string {
-	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", tableName, mg.generateColumnSQL(column))
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", mg.grammar.Wrap(tableName), mg.generateColumnSQL(column))
 }
 
 func (mg *mysqlGenerator) GenerateDropColumn(tableName string, columnName string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tableName, columnName)
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", mg.grammar.Wrap(tableName), mg.grammar.Wrap(columnName))
 }
 
 func (mg *mysqlGenerator) GenerateRenameColumn(tableName, oldName, newName string) string {
-	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName, oldName, newName)
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", mg.grammar.Wrap(tableName), mg.grammar.Wrap(oldName), mg.grammar.Wrap(newName))
 }
 
 // Index operations
@@ -132,7 +150,7 @@ func (mg *mysqlGenerator) GenerateCreateIndex(tableName string, index IndexDefin
 		indexType = "FULLTEXT "
 	}
 	
-	sql := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", indexType, index.Name, tableName, strings.Join(index.Columns, ", "))
+	sql := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", indexType, mg.grammar.Wrap(index.Name), mg.grammar.Wrap(tableName), grammar.Columnize(mg.grammar, index.Columns))
 	
 	if index.Algorithm != "" {
 		sql += " USING " + index.Algorithm
@@ -142,13 +160,14 @@ func (mg *mysqlGenerator) GenerateCreateIndex(tableName string, index IndexDefin
 }
 
 func (mg *mysqlGenerator) GenerateDropIndex(tableName string, indexName string) string {
-	return fmt.Sprintf("DROP INDEX %s ON %s", indexName, tableName)
+	return fmt.Sprintf("DROP INDEX %s ON %s", mg.grammar.Wrap(indexName), mg.grammar.Wrap(tableName))
 }
 
 // Foreign key operations
 func (mg *mysqlGenerator) GenerateAddForeignKey(tableName string, foreignKey ForeignKeyDefinition) string {
 	sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
-		tableName, foreignKey.Name, foreignKey.Column, foreignKey.ReferencedTable, foreignKey.ReferencedColumn)
+		mg.grammar.Wrap(tableName), mg.grammar.Wrap(foreignKey.Name), mg.grammar.Wrap(foreignKey.Column),
+		mg.grammar.Wrap(foreignKey.ReferencedTable), mg.grammar.Wrap(foreignKey.ReferencedColumn))
 	
 	if foreignKey.OnDelete != "" {
 		sql += " ON DELETE " + foreignKey.OnDelete
@@ -161,28 +180,28 @@ func (mg *mysqlGenerator) GenerateAddForeignKey(tableName string, foreignKey For
 }
 
 func (mg *mysqlGenerator) GenerateDropForeignKey(tableName string, keyName string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", tableName, keyName)
+	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", mg.grammar.Wrap(tableName), mg.grammar.Wrap(keyName))
 }
 
 // Introspection queries
 func (mg *mysqlGenerator) GetTableExistsQuery(tableName string) string {
-	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	return grammar.Rebind(mg.grammar, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?")
 }
 
 func (mg *mysqlGenerator) GetColumnExistsQuery(tableName, columnName string) string {
-	return "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?"
+	return grammar.Rebind(mg.grammar, "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?")
 }
 
 func (mg *mysqlGenerator) GetIndexExistsQuery(tableName, indexName string) string {
-	return "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?"
+	return grammar.Rebind(mg.grammar, "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?")
 }
 
 func (mg *mysqlGenerator) GetForeignKeyExistsQuery(tableName, keyName string) string {
-	return "SELECT COUNT(*) FROM information_schema.key_column_usage WHERE table_schema = DATABASE() AND table_name = ? AND constraint_name = ?"
+	return grammar.Rebind(mg.grammar, "SELECT COUNT(*) FROM information_schema.key_column_usage WHERE table_schema = DATABASE() AND table_name = ? AND constraint_name = ?")
 }
 
 func (mg *mysqlGenerator) GetColumnListingQuery(tableName string) string {
-	return "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position"
+	return grammar.Rebind(mg.grammar, "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position")
 }
 
 func (mg *mysqlGenerator) GetTableListingQuery() string {
@@ -266,7 +285,7 @@ func (mg *mysqlGenerator) SupportsFeature(feature string) bool {
 
 // Helper methods
 func (mg *mysqlGenerator) generateColumnSQL(column ColumnDefinition) string {
-	sql := column.Name + " " + mg.getColumnTypeSQL(column)
+	sql := mg.grammar.Wrap(column.Name) + " " + mg.getColumnTypeSQL(column)
 	
 	if column.Unsigned {
 		sql += " UNSIGNED"
@@ -298,7 +317,7 @@ func (mg *mysqlGenerator) generateColumnSQL(column ColumnDefinition) string {
 	}
 	
 	if column.After != "" {
-		sql += " AFTER " + column.After
+		sql += " AFTER " + mg.grammar.Wrap(column.After)
 	}
 	
 	if column.First {
@@ -348,15 +367,16 @@ func (mg *mysqlGenerator) getColumnTypeSQL(column ColumnDefinition) string {
 }
 
 func (mg *mysqlGenerator) generateIndexInTable(index IndexDefinition) string {
+	name, columns := mg.grammar.Wrap(index.Name), grammar.Columnize(mg.grammar, index.Columns)
 	switch index.Type {
 	case "unique":
-		return fmt.Sprintf("UNIQUE KEY %s (%s)", index.Name, strings.Join(index.Columns, ", "))
+		return fmt.Sprintf("UNIQUE KEY %s (%s)", name, columns)
 	case "spatial":
-		return fmt.Sprintf("SPATIAL KEY %s (%s)", index.Name, strings.Join(index.Columns, ", "))
+		return fmt.Sprintf("SPATIAL KEY %s (%s)", name, columns)
 	case "fulltext":
-		return fmt.Sprintf("FULLTEXT KEY %s (%s)", index.Name, strings.Join(index.Columns, ", "))
+		return fmt.Sprintf("FULLTEXT KEY %s (%s)", name, columns)
 	default:
-		return fmt.Sprintf("KEY %s (%s)", index.Name, strings.Join(index.Columns, ", "))
+		return fmt.Sprintf("KEY %s (%s)", name, columns)
 	}
 }
 
@@ -371,11 +391,11 @@ type sqliteGenerator struct {
 
 // Override specific methods for PostgreSQL
 func (pg *postgresqlGenerator) GetTableExistsQuery(tableName string) string {
-	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1"
+	return grammar.Rebind(pg.grammar, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?")
 }
 
 func (pg *postgresqlGenerator) GetColumnExistsQuery(tableName, columnName string) string {
-	return "SELECT COUNT(*) FROM information_schema.columns WHERE table_name = $1 AND column_name = $2"
+	return grammar.Rebind(pg.grammar, "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?")
 }
 
 // Override specific methods for SQLite
@@ -254,16 +254,7 @@ func (mc *MigrationConfig) GetSQLGenerator() SQLGenerator {
 	}
 	
 	// Return default generator based on driver
-	switch mc.Driver {
-	case "mysql":
-		return NewMySQLGenerator()
-	case "postgres":
-		return NewPostgreSQLGenerator()
-	case "sqlite3":
-		return NewSQLiteGenerator()
-	default:
-		return NewMySQLGenerator() // Default fallback
-	}
+	return newSQLGenerator(mc.Driver)
 }
 
 // MigrationError represents an error that occurred during migration
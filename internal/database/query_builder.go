@@ -1,15 +1,21 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/onyx-go/framework/internal/database/grammar"
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // queryBuilder implements the QueryBuilder interface
 type queryBuilder struct {
-	db              *DB
+	db              connection
 	table           string
 	selects         []string
 	wheres          []whereClause
@@ -24,12 +30,39 @@ type queryBuilder struct {
 	eagerLoadEngine interface{} // Will be properly typed when we refactor eager loading
 	includeDeleted  bool
 	rawQuery        string
+	ctx             context.Context
+	timeout         time.Duration
+}
+
+// ErrQueryCanceled is returned when a query is stopped because its context
+// was cancelled or its timeout passed. The error also wraps the context's
+// error, so errors.Is(err, context.DeadlineExceeded) tells timeouts apart.
+var ErrQueryCanceled = errors.New("database: query canceled")
+
+// WrapCanceled returns err marked with ErrQueryCanceled when it happened
+// because ctx ended, and err unchanged otherwise
+func WrapCanceled(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil || errors.Is(err, ErrQueryCanceled) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrQueryCanceled, ctx.Err())
 }
 
 // NewQueryBuilder creates a new query builder instance
 func NewQueryBuilder(db *DB) QueryBuilder {
+	if db == nil {
+		return newQueryBuilder(context.Background(), nil, "")
+	}
+	return newQueryBuilder(db.context(), db, "")
+}
+
+// newQueryBuilder creates a query builder running on conn, which is a DB or
+// a transaction, under ctx
+func newQueryBuilder(ctx context.Context, conn connection, tableName string) *queryBuilder {
 	return &queryBuilder{
-		db:              db,
+		db:              conn,
+		ctx:             ctx,
+		table:           tableName,
 		selects:         []string{"*"},
 		wheres:          []whereClause{},
 		orders:          []string{},
@@ -139,19 +172,22 @@ func (qb *queryBuilder) OrWhereNotNull(column string) QueryBuilder {
 
 // Join adds an INNER JOIN clause
 func (qb *queryBuilder) Join(table, first, operator, second string) QueryBuilder {
-	qb.joins = append(qb.joins, fmt.Sprintf("JOIN %s ON %s %s %s", table, first, operator, second))
+	g := qb.grammar()
+	qb.joins = append(qb.joins, fmt.Sprintf("JOIN %s ON %s %s %s", g.Wrap(table), g.Wrap(first), operator, g.Wrap(second)))
 	return qb
 }
 
 // LeftJoin adds a LEFT JOIN clause
 func (qb *queryBuilder) LeftJoin(table, first, operator, second string) QueryBuilder {
-	qb.joins = append(qb.joins, fmt.Sprintf("LEFT JOIN %s ON %s %s %s", table, first, operator, second))
+	g := qb.grammar()
+	qb.joins = append(qb.joins, fmt.Sprintf("LEFT JOIN %s ON %s %s %s", g.Wrap(table), g.Wrap(first), operator, g.Wrap(second)))
 	return qb
 }
 
 // RightJoin adds a RIGHT JOIN clause
 func (qb *queryBuilder) RightJoin(table, first, operator, second string) QueryBuilder {
-	qb.joins = append(qb.joins, fmt.Sprintf("RIGHT JOIN %s ON %s %s %s", table, first, operator, second))
+	g := qb.grammar()
+	qb.joins = append(qb.joins, fmt.Sprintf("RIGHT JOIN %s ON %s %s %s", g.Wrap(table), g.Wrap(first), operator, g.Wrap(second)))
 	return qb
 }
 
@@ -166,7 +202,7 @@ func (qb *queryBuilder) OrderBy(column string, direction ...string) QueryBuilder
 	if len(direction) > 0 && strings.ToUpper(direction[0]) == "DESC" {
 		dir = "DESC"
 	}
-	qb.orders = append(qb.orders, fmt.Sprintf("%s %s", column, dir))
+	qb.orders = append(qb.orders, fmt.Sprintf("%s %s", qb.grammar().Wrap(column), dir))
 	return qb
 }
 
@@ -227,6 +263,19 @@ func (qb *queryBuilder) OnlyTrashed() QueryBuilder {
 	return qb.WhereNotNull("deleted_at")
 }
 
+// WithContext runs the query under ctx, so cancelling it or passing its
+// deadline stops the query with ErrQueryCanceled
+func (qb *queryBuilder) WithContext(ctx context.Context) QueryBuilder {
+	qb.ctx = ctx
+	return qb
+}
+
+// Timeout limits how long each statement the query runs may take
+func (qb *queryBuilder) Timeout(timeout time.Duration) QueryBuilder {
+	qb.timeout = timeout
+	return qb
+}
+
 // Get executes the query and returns all results
 func (qb *queryBuilder) Get(dest interface{}) error {
 	query, args, err := qb.buildSelectQuery()
@@ -234,15 +283,24 @@ func (qb *queryBuilder) Get(dest interface{}) error {
 		return err
 	}
 
-	rows, err := qb.db.Query(query, args...)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.Driver(), "SELECT", qb.table, query)
+	defer span.End()
+
+	rows, err := qb.db.QueryContext(ctx, query, args...)
 	if err != nil {
+		err = WrapCanceled(ctx, err)
+		span.RecordError(err)
 		return err
 	}
 	defer rows.Close()
 
 	// Use scanner to populate dest
 	scanner := NewScanner()
-	return scanner.ScanRows(rows, dest)
+	err = WrapCanceled(ctx, scanner.ScanRows(rows, dest))
+	span.RecordError(err)
+	return err
 }
 
 // First executes the query and returns the first result
@@ -253,11 +311,13 @@ func (qb *queryBuilder) First(dest interface{}) error {
 		return err
 	}
 
-	row := qb.db.QueryRow(query, args...)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	row := qb.db.QueryRowContext(ctx, query, args...)
 	
 	// Use scanner to populate dest
 	scanner := NewScanner()
-	return scanner.ScanRow(row, dest)
+	return WrapCanceled(ctx, scanner.ScanRow(row, dest))
 }
 
 // Find finds a record by ID
@@ -283,7 +343,7 @@ func (qb *queryBuilder) Count() (int64, error) {
 	}
 
 	var count int64
-	err = qb.db.QueryRow(query, args...).Scan(&count)
+	err = qb.queryRow(query, args, &count)
 	qb.selects = originalSelects
 	return count, err
 }
@@ -291,7 +351,7 @@ func (qb *queryBuilder) Count() (int64, error) {
 // Sum returns the sum of a column
 func (qb *queryBuilder) Sum(column string) (float64, error) {
 	originalSelects := qb.selects
-	qb.selects = []string{fmt.Sprintf("SUM(%s) as sum", column)}
+	qb.selects = []string{fmt.Sprintf("SUM(%s) as sum", qb.grammar().Wrap(column))}
 	
 	query, args, err := qb.buildSelectQuery()
 	if err != nil {
@@ -300,7 +360,7 @@ func (qb *queryBuilder) Sum(column string) (float64, error) {
 	}
 
 	var sum sql.NullFloat64
-	err = qb.db.QueryRow(query, args...).Scan(&sum)
+	err = qb.queryRow(query, args, &sum)
 	qb.selects = originalSelects
 	
 	if !sum.Valid {
@@ -312,7 +372,7 @@ func (qb *queryBuilder) Sum(column string) (float64, error) {
 // Avg returns the average of a column
 func (qb *queryBuilder) Avg(column string) (float64, error) {
 	originalSelects := qb.selects
-	qb.selects = []string{fmt.Sprintf("AVG(%s) as avg", column)}
+	qb.selects = []string{fmt.Sprintf("AVG(%s) as avg", qb.grammar().Wrap(column))}
 	
 	query, args, err := qb.buildSelectQuery()
 	if err != nil {
@@ -321,7 +381,7 @@ func (qb *queryBuilder) Avg(column string) (float64, error) {
 	}
 
 	var avg sql.NullFloat64
-	err = qb.db.QueryRow(query, args...).Scan(&avg)
+	err = qb.queryRow(query, args, &avg)
 	qb.selects = originalSelects
 	
 	if !avg.Valid {
@@ -333,7 +393,7 @@ func (qb *queryBuilder) Avg(column string) (float64, error) {
 // Min returns the minimum value of a column
 func (qb *queryBuilder) Min(column string) (interface{}, error) {
 	originalSelects := qb.selects
-	qb.selects = []string{fmt.Sprintf("MIN(%s) as min", column)}
+	qb.selects = []string{fmt.Sprintf("MIN(%s) as min", qb.grammar().Wrap(column))}
 	
 	query, args, err := qb.buildSelectQuery()
 	if err != nil {
@@ -342,7 +402,7 @@ func (qb *queryBuilder) Min(column string) (interface{}, error) {
 	}
 
 	var min interface{}
-	err = qb.db.QueryRow(query, args...).Scan(&min)
+	err = qb.queryRow(query, args, &min)
 	qb.selects = originalSelects
 	return min, err
 }
@@ -350,7 +410,7 @@ func (qb *queryBuilder) Min(column string) (interface{}, error) {
 // Max returns the maximum value of a column
 func (qb *queryBuilder) Max(column string) (interface{}, error) {
 	originalSelects := qb.selects
-	qb.selects = []string{fmt.Sprintf("MAX(%s) as max", column)}
+	qb.selects = []string{fmt.Sprintf("MAX(%s) as max", qb.grammar().Wrap(column))}
 	
 	query, args, err := qb.buildSelectQuery()
 	if err != nil {
@@ -359,7 +419,7 @@ func (qb *queryBuilder) Max(column string) (interface{}, error) {
 	}
 
 	var max interface{}
-	err = qb.db.QueryRow(query, args...).Scan(&max)
+	err = qb.queryRow(query, args, &max)
 	qb.selects = originalSelects
 	return max, err
 }
@@ -399,15 +459,17 @@ func (qb *queryBuilder) Delete() (sql.Result, error) {
 
 // ForceDelete performs a hard delete
 func (qb *queryBuilder) ForceDelete() (sql.Result, error) {
-	query := fmt.Sprintf("DELETE FROM %s", qb.table)
+	g := qb.grammar()
+	query := fmt.Sprintf("DELETE FROM %s", g.Wrap(qb.table))
 	
+	var args []interface{}
 	if len(qb.wheres) > 0 {
 		whereClause, whereArgs := qb.buildWhereClause(qb.wheres)
 		query += " WHERE " + whereClause
-		return qb.db.Exec(query, whereArgs...)
+		args = whereArgs
 	}
 	
-	return qb.db.Exec(query)
+	return qb.exec("DELETE", query, args...)
 }
 
 // Restore restores soft-deleted records
@@ -426,37 +488,102 @@ func (qb *queryBuilder) ToSQL() (string, []interface{}, error) {
 
 // Helper methods
 
-func (qb *queryBuilder) insertMap(data map[string]interface{}) (sql.Result, error) {
+// queryContext returns the context a statement runs under, limited by the
+// builder's timeout. The cancel function must be called once the statement
+// and its rows are done with.
+func (qb *queryBuilder) queryContext() (context.Context, context.CancelFunc) {
+	ctx := qb.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if qb.timeout > 0 {
+		return context.WithTimeout(ctx, qb.timeout)
+	}
+	return ctx, func() {}
+}
+
+// queryRow runs a query expected to return one row and scans it into dest
+func (qb *queryBuilder) queryRow(query string, args []interface{}, dest ...interface{}) error {
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	return WrapCanceled(ctx, qb.db.QueryRowContext(ctx, query, args...).Scan(dest...))
+}
+
+// grammar returns the dialect of the builder's connection
+func (qb *queryBuilder) grammar() grammar.Grammar {
+	if qb.db == nil {
+		return grammar.For("")
+	}
+	return qb.db.Grammar()
+}
+
+// sortedColumns returns the keys of data in a stable order, so the same
+// data always builds the same statement
+func sortedColumns(data map[string]interface{}) []string {
 	columns := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
+	for column := range data {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func (qb *queryBuilder) insertMap(data map[string]interface{}) (sql.Result, error) {
+	g := qb.grammar()
+	columns := sortedColumns(data)
 	values := make([]interface{}, 0, len(data))
 	
-	for column, value := range data {
-		columns = append(columns, column)
-		placeholders = append(placeholders, "?")
-		values = append(values, value)
+	for _, column := range columns {
+		values = append(values, data[column])
 	}
 	
 	query := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES (%s)",
-		qb.table,
-		strings.Join(columns, ", "),
-		strings.Join(placeholders, ", "),
+		g.Wrap(qb.table),
+		grammar.Columnize(g, columns),
+		grammar.Parameters(len(columns)),
 	)
 	
-	return qb.db.Exec(query, values...)
+	// Drivers without LastInsertId support hand back the new row instead
+	if g.SupportsReturning() {
+		return qb.insertReturning(query+grammar.Returning, values...)
+	}
+	
+	return qb.exec("INSERT", query, values...)
+}
+
+// insertReturning runs an INSERT ... RETURNING statement inside a span
+func (qb *queryBuilder) insertReturning(query string, args ...interface{}) (sql.Result, error) {
+	query = grammar.Rebind(qb.grammar(), query)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.Driver(), "INSERT", qb.table, query)
+	defer span.End()
+	
+	rows, err := qb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = WrapCanceled(ctx, err)
+		span.RecordError(err)
+		return nil, err
+	}
+	result, err := grammar.ScanInsert(rows)
+	err = WrapCanceled(ctx, err)
+	span.RecordError(err)
+	return result, err
 }
 
 func (qb *queryBuilder) updateMap(data map[string]interface{}) (sql.Result, error) {
+	g := qb.grammar()
+	columns := sortedColumns(data)
 	setParts := make([]string, 0, len(data))
 	values := make([]interface{}, 0, len(data))
 	
-	for column, value := range data {
-		setParts = append(setParts, fmt.Sprintf("%s = ?", column))
-		values = append(values, value)
+	for _, column := range columns {
+		setParts = append(setParts, fmt.Sprintf("%s = ?", g.Wrap(column)))
+		values = append(values, data[column])
 	}
 	
-	query := fmt.Sprintf("UPDATE %s SET %s", qb.table, strings.Join(setParts, ", "))
+	query := fmt.Sprintf("UPDATE %s SET %s", g.Wrap(qb.table), strings.Join(setParts, ", "))
 	
 	if len(qb.wheres) > 0 {
 		whereClause, whereArgs := qb.buildWhereClause(qb.wheres)
@@ -464,7 +591,21 @@ func (qb *queryBuilder) updateMap(data map[string]interface{}) (sql.Result, erro
 		values = append(values, whereArgs...)
 	}
 	
-	return qb.db.Exec(query, values...)
+	return qb.exec("UPDATE", query, values...)
+}
+
+// exec rebinds a write statement for the driver and runs it inside a span
+func (qb *queryBuilder) exec(operation, query string, args ...interface{}) (sql.Result, error) {
+	query = grammar.Rebind(qb.grammar(), query)
+	ctx, cancel := qb.queryContext()
+	defer cancel()
+	ctx, span := tracing.StartQuery(ctx, qb.db.Driver(), operation, qb.table, query)
+	defer span.End()
+	
+	result, err := qb.db.ExecContext(ctx, query, args...)
+	err = WrapCanceled(ctx, err)
+	span.RecordError(err)
+	return result, err
 }
 
 func (qb *queryBuilder) buildSelectQuery() (string, []interface{}, error) {
@@ -472,7 +613,8 @@ func (qb *queryBuilder) buildSelectQuery() (string, []interface{}, error) {
 		return qb.rawQuery, qb.bindings, nil
 	}
 
-	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(qb.selects, ", "), qb.table)
+	g := qb.grammar()
+	query := fmt.Sprintf("SELECT %s FROM %s", grammar.Columnize(g, qb.selects), g.Wrap(qb.table))
 	args := []interface{}{}
 	
 	// Add joins
@@ -490,15 +632,15 @@ func (qb *queryBuilder) buildSelectQuery() (string, []interface{}, error) {
 	// Add soft delete filtering
 	if !qb.includeDeleted {
 		if len(qb.wheres) > 0 {
-			query += " AND deleted_at IS NULL"
+			query += " AND " + g.Wrap("deleted_at") + " IS NULL"
 		} else {
-			query += " WHERE deleted_at IS NULL"
+			query += " WHERE " + g.Wrap("deleted_at") + " IS NULL"
 		}
 	}
 	
 	// Add group by
 	if len(qb.groupBy) > 0 {
-		query += " GROUP BY " + strings.Join(qb.groupBy, ", ")
+		query += " GROUP BY " + grammar.Columnize(g, qb.groupBy)
 	}
 	
 	// Add having
@@ -514,14 +656,9 @@ func (qb *queryBuilder) buildSelectQuery() (string, []interface{}, error) {
 	}
 	
 	// Add limit and offset
-	if qb.limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", qb.limit)
-	}
-	if qb.offset > 0 {
-		query += fmt.Sprintf(" OFFSET %d", qb.offset)
-	}
+	query += g.Limit(qb.limit, qb.offset)
 	
-	return query, args, nil
+	return grammar.Rebind(g, query), args, nil
 }
 
 func (qb *queryBuilder) buildWhereClause(wheres []whereClause) (string, []interface{}) {
@@ -529,6 +666,7 @@ func (qb *queryBuilder) buildWhereClause(wheres []whereClause) (string, []interf
 		return "", []interface{}{}
 	}
 	
+	g := qb.grammar()
 	parts := make([]string, len(wheres))
 	args := make([]interface{}, 0)
 	
@@ -537,31 +675,34 @@ func (qb *queryBuilder) buildWhereClause(wheres []whereClause) (string, []interf
 		if i > 0 {
 			boolean = where.Boolean + " "
 		}
+		column := g.Wrap(where.Column)
 		
 		switch where.Operator {
+		case "RAW":
+			// Column holds SQL built by the builder itself, such as a group
+			parts[i] = boolean + where.Column
+			if values, ok := where.Value.([]interface{}); ok {
+				args = append(args, values...)
+			}
 		case "IN", "NOT IN":
 			if values, ok := where.Value.([]interface{}); ok {
-				placeholders := make([]string, len(values))
-				for j := range values {
-					placeholders[j] = "?"
-				}
-				parts[i] = fmt.Sprintf("%s%s %s (%s)", boolean, where.Column, where.Operator, strings.Join(placeholders, ", "))
+				parts[i] = fmt.Sprintf("%s%s %s (%s)", boolean, column, where.Operator, grammar.Parameters(len(values)))
 				args = append(args, values...)
 			}
 		case "BETWEEN", "NOT BETWEEN":
 			if values, ok := where.Value.([]interface{}); ok && len(values) == 2 {
-				parts[i] = fmt.Sprintf("%s%s %s ? AND ?", boolean, where.Column, where.Operator)
+				parts[i] = fmt.Sprintf("%s%s %s ? AND ?", boolean, column, where.Operator)
 				args = append(args, values...)
 			}
 		case "IS", "IS NOT":
 			if where.Value == nil {
-				parts[i] = fmt.Sprintf("%s%s %s NULL", boolean, where.Column, where.Operator)
+				parts[i] = fmt.Sprintf("%s%s %s NULL", boolean, column, where.Operator)
 			} else {
-				parts[i] = fmt.Sprintf("%s%s %s ?", boolean, where.Column, where.Operator)
+				parts[i] = fmt.Sprintf("%s%s %s ?", boolean, column, where.Operator)
 				args = append(args, where.Value)
 			}
 		default:
-			parts[i] = fmt.Sprintf("%s%s %s ?", boolean, where.Column, where.Operator)
+			parts[i] = fmt.Sprintf("%s%s %s ?", boolean, column, where.Operator)
 			args = append(args, where.Value)
 		}
 	}
@@ -0,0 +1,277 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/onyx-go/framework/internal/database/grammar"
+)
+
+// TxOptions configures a transaction started with Transaction
+type TxOptions struct {
+	// Isolation is the isolation level; the driver's default when zero
+	Isolation sql.IsolationLevel
+
+	// ReadOnly starts a read-only transaction
+	ReadOnly bool
+
+	// Attempts is how many times the transaction runs when it fails with a
+	// deadlock or serialization failure; 3 when zero
+	Attempts int
+}
+
+// TxOption configures a transaction
+type TxOption func(*TxOptions)
+
+// WithIsolation sets the isolation level of a transaction
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *TxOptions) {
+		o.Isolation = level
+	}
+}
+
+// ReadOnly starts a read-only transaction
+func ReadOnly() TxOption {
+	return func(o *TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// WithAttempts sets how many times a transaction runs before a deadlock or
+// serialization failure is returned; 1 disables retries
+func WithAttempts(attempts int) TxOption {
+	return func(o *TxOptions) {
+		o.Attempts = attempts
+	}
+}
+
+// retryDelay is the base wait between attempts, multiplied by the attempt
+// number
+const retryDelay = 20 * time.Millisecond
+
+// Tx is a Database bound to a transaction. Query builders created from it
+// run inside the transaction.
+type Tx struct {
+	tx          *sql.Tx
+	db          *DB
+	ctx         context.Context
+	depth       int
+	afterCommit *[]func()
+}
+
+// Transaction runs fn inside a transaction, committing when fn returns nil
+// and rolling back when it returns an error or panics. The whole transaction
+// is run again when it fails with a deadlock or serialization failure, so fn
+// should have no effects outside the database except through AfterCommit.
+func (db *DB) Transaction(ctx context.Context, fn func(tx Database) error, opts ...TxOption) error {
+	options := TxOptions{Attempts: 3}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Attempts < 1 {
+		options.Attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= options.Attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(time.Duration(attempt-1) * retryDelay):
+			}
+		}
+
+		var callbacks []func()
+		callbacks, err = db.runTransaction(ctx, fn, options)
+		if err == nil {
+			for _, callback := range callbacks {
+				callback()
+			}
+			return nil
+		}
+		if !IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// runTransaction runs one attempt of a transaction, returning the
+// after-commit callbacks once it has committed
+func (db *DB) runTransaction(ctx context.Context, fn func(tx Database) error, options TxOptions) (callbacks []func(), err error) {
+	sqlTx, err := db.DB.BeginTx(ctx, &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{tx: sqlTx, db: db, ctx: ctx, afterCommit: new([]func())}
+
+	defer func() {
+		if r := recover(); r != nil {
+			sqlTx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return nil, fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return nil, err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return nil, err
+	}
+	return *tx.afterCommit, nil
+}
+
+// AfterCommit runs fn immediately, as there is no transaction to wait for
+func (db *DB) AfterCommit(fn func()) {
+	fn()
+}
+
+// Transaction runs fn inside a savepoint of the current transaction. An
+// error from fn rolls back to the savepoint and is returned, leaving the
+// outer transaction to decide. Options do not apply to savepoints.
+func (tx *Tx) Transaction(ctx context.Context, fn func(tx Database) error, opts ...TxOption) (err error) {
+	name := fmt.Sprintf("sp_%d", tx.depth+1)
+	if _, err := tx.Exec("SAVEPOINT " + name); err != nil {
+		return err
+	}
+	nested := &Tx{tx: tx.tx, db: tx.db, ctx: ctx, depth: tx.depth + 1, afterCommit: new([]func())}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Exec("ROLLBACK TO SAVEPOINT " + name)
+			panic(r)
+		}
+	}()
+
+	if err := fn(nested); err != nil {
+		if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT " + name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	if _, err := tx.Exec("RELEASE SAVEPOINT " + name); err != nil {
+		return err
+	}
+	// Callbacks from a savepoint wait for the outermost commit
+	*tx.afterCommit = append(*tx.afterCommit, *nested.afterCommit...)
+	return nil
+}
+
+// AfterCommit queues fn to run once the outermost transaction commits. It is
+// dropped if the transaction, or the savepoint it was queued in, rolls back.
+func (tx *Tx) AfterCommit(fn func()) {
+	*tx.afterCommit = append(*tx.afterCommit, fn)
+}
+
+// WithContext returns a copy of the transaction whose queries run under ctx.
+// The copy shares the transaction and its after-commit callbacks.
+func (tx *Tx) WithContext(ctx context.Context) Database {
+	copied := *tx
+	copied.ctx = ctx
+	return &copied
+}
+
+// Driver returns the database driver name
+func (tx *Tx) Driver() string {
+	return tx.db.Driver()
+}
+
+// Grammar returns the SQL dialect for the connection's driver
+func (tx *Tx) Grammar() grammar.Grammar {
+	return tx.db.Grammar()
+}
+
+// Table creates a query builder for the table that runs in the transaction
+func (tx *Tx) Table(tableName string) QueryBuilder {
+	return newQueryBuilder(tx.ctx, tx, tableName)
+}
+
+// Model creates a query builder for the model that runs in the transaction
+func (tx *Tx) Model(model Model) QueryBuilder {
+	return tx.Table(model.TableName())
+}
+
+// Raw creates a query builder with raw SQL that runs in the transaction
+func (tx *Tx) Raw(query string, args ...interface{}) QueryBuilder {
+	qb := newQueryBuilder(tx.ctx, tx, "")
+	qb.rawQuery = query
+	qb.bindings = args
+	return qb
+}
+
+// Close reports an error; a transaction ends when its function returns
+func (tx *Tx) Close() error {
+	return errors.New("database: a transaction is closed by returning from its function")
+}
+
+// Ping verifies the underlying connection is still alive
+func (tx *Tx) Ping() error {
+	return tx.db.PingContext(tx.ctx)
+}
+
+// Exec executes a query that doesn't return rows in the transaction
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.tx.ExecContext(tx.ctx, query, args...)
+}
+
+// Query executes a query that returns rows in the transaction
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.tx.QueryContext(tx.ctx, query, args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row in
+// the transaction
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.tx.QueryRowContext(tx.ctx, query, args...)
+}
+
+// ExecContext executes a query that doesn't return rows in the transaction
+// under ctx
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.tx.ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows in the transaction under
+// ctx
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.tx.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one
+// row in the transaction under ctx
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.tx.QueryRowContext(ctx, query, args...)
+}
+
+// IsRetryable reports whether err is a deadlock or serialization failure,
+// after which the whole transaction can be run again
+func IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_LOCK_DEADLOCK and ER_LOCK_WAIT_TIMEOUT
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// serialization_failure and deadlock_detected
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// Ensure Tx implements Database interface
+var _ Database = (*Tx)(nil)
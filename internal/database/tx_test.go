@@ -0,0 +1,234 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := NewDB("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, deleted_at DATETIME)`); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	return db
+}
+
+func countPosts(t *testing.T, db Database) int64 {
+	t.Helper()
+	count, err := db.Table("posts").Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	return count
+}
+
+func TestTransaction(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	var fired []string
+
+	err := db.Transaction(ctx, func(tx Database) error {
+		if _, err := tx.Table("posts").Insert(map[string]interface{}{"title": "first"}); err != nil {
+			return err
+		}
+		tx.AfterCommit(func() { fired = append(fired, "outer") })
+		if countPosts(t, tx) != 1 {
+			t.Error("Expected the insert to be visible inside the transaction")
+		}
+		if len(fired) != 0 {
+			t.Error("Expected after-commit callbacks to wait for the commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if countPosts(t, db) != 1 || len(fired) != 1 {
+		t.Errorf("Expected a committed row and one callback, got %d rows and %v", countPosts(t, db), fired)
+	}
+
+	failure := errors.New("boom")
+	err = db.Transaction(ctx, func(tx Database) error {
+		tx.Table("posts").Insert(map[string]interface{}{"title": "second"})
+		tx.AfterCommit(func() { fired = append(fired, "rolled back") })
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the function's error, got %v", err)
+	}
+	if countPosts(t, db) != 1 || len(fired) != 1 {
+		t.Errorf("Expected the insert and callback to be discarded, got %d rows and %v", countPosts(t, db), fired)
+	}
+}
+
+func TestTransaction_Savepoints(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	var fired []string
+
+	err := db.Transaction(ctx, func(tx Database) error {
+		tx.Table("posts").Insert(map[string]interface{}{"title": "outer"})
+
+		err := tx.Transaction(ctx, func(inner Database) error {
+			inner.Table("posts").Insert(map[string]interface{}{"title": "inner"})
+			inner.AfterCommit(func() { fired = append(fired, "inner") })
+			return errors.New("inner failed")
+		})
+		if err == nil {
+			t.Error("Expected the savepoint error to be returned")
+		}
+
+		return tx.Transaction(ctx, func(inner Database) error {
+			inner.AfterCommit(func() { fired = append(fired, "kept") })
+			_, err := inner.Table("posts").Insert(map[string]interface{}{"title": "kept"})
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	if n := countPosts(t, db); n != 2 {
+		t.Errorf("Expected the outer and released savepoint rows, got %d", n)
+	}
+	if fmt.Sprint(fired) != "[kept]" {
+		t.Errorf("Expected only the released savepoint's callback, got %v", fired)
+	}
+}
+
+func TestTransaction_Retry(t *testing.T) {
+	db := newTestDB(t)
+	attempts := 0
+
+	err := db.Transaction(context.Background(), func(tx Database) error {
+		attempts++
+		tx.Table("posts").Insert(map[string]interface{}{"title": "retried"})
+		if attempts == 1 {
+			return fmt.Errorf("insert: %w", &pq.Error{Code: "40001"})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if attempts != 2 || countPosts(t, db) != 1 {
+		t.Errorf("Expected one retry and one row, got %d attempts and %d rows", attempts, countPosts(t, db))
+	}
+
+	attempts = 0
+	err = db.Transaction(context.Background(), func(tx Database) error {
+		attempts++
+		return &mysql.MySQLError{Number: 1213}
+	}, WithAttempts(2))
+	if !IsRetryable(err) || attempts != 2 {
+		t.Errorf("Expected the deadlock after 2 attempts, got %v after %d", err, attempts)
+	}
+}
+
+func TestTransaction_Panic(t *testing.T) {
+	db := newTestDB(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to propagate")
+			}
+		}()
+		db.Transaction(context.Background(), func(tx Database) error {
+			tx.Table("posts").Insert(map[string]interface{}{"title": "lost"})
+			panic("boom")
+		})
+	}()
+
+	if n := countPosts(t, db); n != 0 {
+		t.Errorf("Expected the panic to roll back, got %d rows", n)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&mysql.MySQLError{Number: 1213}, true},
+		{&mysql.MySQLError{Number: 1062}, false},
+		{&pq.Error{Code: "40P01"}, true},
+		{&pq.Error{Code: "23505"}, false},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{errors.New("other"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// endless never finishes on its own, so only cancellation stops it
+const endless = `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT COUNT(*) FROM c`
+
+func TestQueryBuilderContext(t *testing.T) {
+	db := newTestDB(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var posts []map[string]interface{}
+	err := db.WithContext(ctx).Table("posts").Get(&posts)
+	if !errors.Is(err, ErrQueryCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled Get to return ErrQueryCanceled, got %v", err)
+	}
+	if _, err := db.Table("posts").WithContext(ctx).Insert(map[string]interface{}{"title": "late"}); !errors.Is(err, ErrQueryCanceled) {
+		t.Errorf("Expected a cancelled Insert to return ErrQueryCanceled, got %v", err)
+	}
+	if countPosts(t, db) != 0 {
+		t.Error("Expected the cancelled insert not to run")
+	}
+
+	start := time.Now()
+	_, err = db.Raw(endless).Timeout(50 * time.Millisecond).Count()
+	if !errors.Is(err, ErrQueryCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a timed out query to return ErrQueryCanceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the timeout to stop the query, took %v", elapsed)
+	}
+
+	// Errors unrelated to the context are left alone
+	if _, err := db.Table("missing").Count(); err == nil || errors.Is(err, ErrQueryCanceled) {
+		t.Errorf("Expected a plain error for a missing table, got %v", err)
+	}
+
+	// A transaction bound to another context shares its callbacks
+	fired := false
+	err = db.Transaction(context.Background(), func(tx Database) error {
+		bound := tx.WithContext(ctx)
+		bound.AfterCommit(func() { fired = true })
+		if _, err := bound.Table("posts").Count(); !errors.Is(err, ErrQueryCanceled) {
+			t.Errorf("Expected the cancelled context to stop the query, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if !fired {
+		t.Error("Expected a callback queued on the bound transaction to run after commit")
+	}
+}
@@ -3,6 +3,15 @@ package events
 import (
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/metrics"
+)
+
+// Counters reported to the metrics registry, summed over every dispatcher
+var (
+	eventsTotal    = metrics.Default().NewCounter("onyx_events_dispatched_total", "Events dispatched.", "event")
+	listenersTotal = metrics.Default().NewCounter("onyx_event_listener_calls_total", "Event listener calls by result.", "listener", "result")
+	errorsTotal    = metrics.Default().NewCounter("onyx_events_errors_total", "Events whose listeners returned an error.", "event")
 )
 
 // SimpleMetrics provides basic metrics collection for events
@@ -46,6 +55,8 @@ func NewSimpleMetrics() *SimpleMetrics {
 
 // RecordEvent records metrics for an event
 func (sm *SimpleMetrics) RecordEvent(eventName string, duration int64) {
+	eventsTotal.With(eventName).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -67,6 +78,12 @@ func (sm *SimpleMetrics) RecordEvent(eventName string, duration int64) {
 
 // RecordListener records metrics for a listener
 func (sm *SimpleMetrics) RecordListener(listenerName string, duration int64, success bool) {
+	result := "success"
+	if !success {
+		result = "error"
+	}
+	listenersTotal.With(listenerName, result).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -85,6 +102,8 @@ func (sm *SimpleMetrics) RecordListener(listenerName string, duration int64, suc
 
 // RecordError records an error for an event
 func (sm *SimpleMetrics) RecordError(eventName string, err error) {
+	errorsTotal.With(eventName).Inc()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Pinger is implemented by *sql.DB and anything embedding it
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Ping checks that a database answers
+func Ping(db Pinger) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// RoundTripCache is the part of a cache a round trip uses
+type RoundTripCache interface {
+	Put(key string, value interface{}, duration time.Duration) error
+	Get(key string) (interface{}, error)
+	Forget(key string) error
+}
+
+// CacheRoundTrip checks that a value written to the cache can be read back
+func CacheRoundTrip(cache RoundTripCache) CheckFunc {
+	return func(ctx context.Context) error {
+		key := fmt.Sprintf("health:%d", time.Now().UnixNano())
+		if err := cache.Put(key, "ok", time.Minute); err != nil {
+			return fmt.Errorf("write failed: %w", err)
+		}
+		defer cache.Forget(key)
+
+		value, err := cache.Get(key)
+		if err != nil {
+			return fmt.Errorf("read failed: %w", err)
+		}
+		if value != "ok" {
+			return fmt.Errorf("read back %v, wrote %q", value, "ok")
+		}
+		return nil
+	}
+}
+
+// QueueSizer is implemented by the queue drivers
+type QueueSizer interface {
+	Size(ctx context.Context, queue ...string) (int, error)
+}
+
+// QueueBacklog fails when more than max jobs are waiting on queue
+func QueueBacklog(q QueueSizer, queue string, max int) CheckFunc {
+	return func(ctx context.Context) error {
+		size, err := q.Size(ctx, queue)
+		if err != nil {
+			return err
+		}
+		if size > max {
+			return fmt.Errorf("%d jobs waiting on %s, more than %d", size, queue, max)
+		}
+		return nil
+	}
+}
+
+// DiskSpace fails when the file system holding path has less than
+// minFreeBytes available
+func DiskSpace(path string, minFreeBytes uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		free, err := freeBytes(path)
+		if err != nil {
+			return err
+		}
+		if free < minFreeBytes {
+			return fmt.Errorf("%d bytes free under %s, less than %d", free, path, minFreeBytes)
+		}
+		return nil
+	}
+}
+
+// Heartbeat fails when last reports a time more than maxAge ago, e.g. for a
+// scheduler that stopped ticking
+func Heartbeat(last func() time.Time, maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		beat := last()
+		if beat.IsZero() {
+			return fmt.Errorf("no heartbeat yet")
+		}
+		if age := time.Since(beat); age > maxAge {
+			return fmt.Errorf("last heartbeat %s ago, more than %s", age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package health
+
+import (
+	"errors"
+	"runtime"
+)
+
+// freeBytes is not supported on this platform
+func freeBytes(path string) (uint64, error) {
+	return 0, errors.New("disk space checks are not supported on " + runtime.GOOS)
+}
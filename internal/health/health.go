@@ -0,0 +1,240 @@
+// Package health runs named checks against the application's dependencies
+// and reports whether the process is alive and ready to take traffic.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a check or of a whole report
+type Status string
+
+const (
+	// StatusUp means every check passed
+	StatusUp Status = "up"
+
+	// StatusDegraded means only non-critical checks failed
+	StatusDegraded Status = "degraded"
+
+	// StatusDown means a critical check failed
+	StatusDown Status = "down"
+)
+
+// DefaultTimeout bounds checks registered without WithTimeout
+const DefaultTimeout = 5 * time.Second
+
+// CheckFunc reports a problem with a dependency as an error
+type CheckFunc func(ctx context.Context) error
+
+// Check is a registered check
+type Check struct {
+	Name     string
+	Func     CheckFunc
+	Timeout  time.Duration
+	Critical bool
+	Liveness bool
+}
+
+// Option configures a check when it is registered
+type Option func(*Check)
+
+// WithTimeout fails the check when it takes longer than timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Check) {
+		c.Timeout = timeout
+	}
+}
+
+// NonCritical reports a failure of the check as degraded instead of down,
+// so the application stays ready without it
+func NonCritical() Option {
+	return func(c *Check) {
+		c.Critical = false
+	}
+}
+
+// Liveness runs the check for liveness as well as readiness. Keep liveness
+// checks to problems only a restart fixes; a failing liveness probe gets
+// the process killed.
+func Liveness() Option {
+	return func(c *Check) {
+		c.Liveness = true
+	}
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status   Status  `json:"status"`
+	Critical bool    `json:"critical"`
+	Duration float64 `json:"duration_ms"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of a liveness or readiness run
+type Report struct {
+	Status    Status            `json:"status"`
+	Checks    map[string]Result `json:"checks"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// HTTPStatus returns 503 when the report is down and 200 otherwise
+func (r Report) HTTPStatus() int {
+	if r.Status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// Registry holds the checks of an application
+type Registry struct {
+	checks map[string]*Check
+	mutex  sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]*Check)}
+}
+
+// Register adds a critical readiness check, replacing any check registered
+// under the same name
+func (r *Registry) Register(name string, check CheckFunc, options ...Option) {
+	c := &Check{Name: name, Func: check, Timeout: DefaultTimeout, Critical: true}
+	for _, option := range options {
+		option(c)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checks[name] = c
+}
+
+// Unregister removes the check registered under name
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.checks, name)
+}
+
+// Checks returns the registered checks sorted by name
+func (r *Registry) Checks() []Check {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	checks := make([]Check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, *c)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+	return checks
+}
+
+// Live runs the liveness checks. With none registered the process is
+// reported up as long as it can answer.
+func (r *Registry) Live(ctx context.Context) Report {
+	var checks []Check
+	for _, c := range r.Checks() {
+		if c.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	return run(ctx, checks)
+}
+
+// Ready runs every check
+func (r *Registry) Ready(ctx context.Context) Report {
+	return run(ctx, r.Checks())
+}
+
+// LiveHandler serves the liveness report as JSON
+func (r *Registry) LiveHandler() http.Handler {
+	return reportHandler(r.Live)
+}
+
+// ReadyHandler serves the readiness report as JSON
+func (r *Registry) ReadyHandler() http.Handler {
+	return reportHandler(r.Ready)
+}
+
+func reportHandler(report func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := report(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(result.HTTPStatus())
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// run runs the checks concurrently, each under its own timeout
+func run(ctx context.Context, checks []Check) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks)), Timestamp: time.Now().UTC()}
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.Name] = result
+		switch {
+		case result.Status == StatusUp:
+		case c.Critical:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// runCheck runs one check, giving up when its timeout passes even if the
+// check ignores its context
+func runCheck(ctx context.Context, c Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("check panicked: %v", recovered)
+			}
+		}()
+		done <- c.Func(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.Timeout)
+	}
+
+	result := Result{
+		Status:   StatusUp,
+		Critical: c.Critical,
+		Duration: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
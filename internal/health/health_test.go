@@ -0,0 +1,176 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Ready(t *testing.T) {
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	passing := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name   string
+		setup  func(r *Registry)
+		status Status
+	}{
+		{"no checks", func(r *Registry) {}, StatusUp},
+		{"all passing", func(r *Registry) {
+			r.Register("db", passing)
+			r.Register("cache", passing, NonCritical())
+		}, StatusUp},
+		{"non-critical failing", func(r *Registry) {
+			r.Register("db", passing)
+			r.Register("cache", failing, NonCritical())
+		}, StatusDegraded},
+		{"critical failing", func(r *Registry) {
+			r.Register("db", failing)
+			r.Register("cache", failing, NonCritical())
+		}, StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.setup(r)
+
+			report := r.Ready(context.Background())
+			if report.Status != tt.status {
+				t.Errorf("status = %s, want %s (%+v)", report.Status, tt.status, report.Checks)
+			}
+			if len(report.Checks) != len(r.Checks()) {
+				t.Errorf("report has %d checks, want %d", len(report.Checks), len(r.Checks()))
+			}
+		})
+	}
+}
+
+func TestRegistry_Timeout(t *testing.T) {
+	r := NewRegistry()
+	r.Register("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	report := r.Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Ready waited %s for a check that ignores its context", elapsed)
+	}
+	if result := report.Checks["stuck"]; result.Status != StatusDown || result.Error != "timed out after 20ms" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRegistry_Live(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", func(ctx context.Context) error { return errors.New("down") })
+	r.Register("deadlock", func(ctx context.Context) error { return nil }, Liveness())
+
+	live := r.Live(context.Background())
+	if live.Status != StatusUp || len(live.Checks) != 1 {
+		t.Errorf("liveness ran %+v, want only the liveness check", live.Checks)
+	}
+	if ready := r.Ready(context.Background()); ready.Status != StatusDown || len(ready.Checks) != 2 {
+		t.Errorf("readiness = %s with %d checks", ready.Status, len(ready.Checks))
+	}
+}
+
+func TestRegistry_Handlers(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	w := httptest.NewRecorder()
+	r.ReadyHandler().ServeHTTP(w, httptest.NewRequest("GET", "/health/ready", nil))
+	if w.Code != 503 || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("ready = %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if result := report.Checks["db"]; report.Status != StatusDown || result.Error != "connection refused" || !result.Critical {
+		t.Errorf("report = %+v", report)
+	}
+
+	w = httptest.NewRecorder()
+	r.LiveHandler().ServeHTTP(w, httptest.NewRequest("GET", "/health/live", nil))
+	if w.Code != 200 {
+		t.Errorf("live = %d, want 200 while only readiness fails", w.Code)
+	}
+}
+
+type mapCache struct {
+	values map[string]interface{}
+	mutex  sync.Mutex
+}
+
+func (c *mapCache) Put(key string, value interface{}, duration time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.values[key] = value
+	return nil
+}
+
+func (c *mapCache) Get(key string) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return value, nil
+}
+
+func (c *mapCache) Forget(key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.values, key)
+	return nil
+}
+
+type queueSize int
+
+func (q queueSize) Size(ctx context.Context, queue ...string) (int, error) {
+	return int(q), nil
+}
+
+func TestChecks(t *testing.T) {
+	cache := &mapCache{values: make(map[string]interface{})}
+
+	tests := []struct {
+		name  string
+		check CheckFunc
+		ok    bool
+	}{
+		{"cache round trip", CacheRoundTrip(cache), true},
+		{"queue under threshold", QueueBacklog(queueSize(10), "default", 10), true},
+		{"queue over threshold", QueueBacklog(queueSize(11), "default", 10), false},
+		{"disk space available", DiskSpace(t.TempDir(), 1), true},
+		{"disk space short", DiskSpace(t.TempDir(), math.MaxUint64), false},
+		{"disk space missing path", DiskSpace("/does/not/exist", 1), false},
+		{"recent heartbeat", Heartbeat(time.Now, time.Minute), true},
+		{"stale heartbeat", Heartbeat(func() time.Time { return time.Now().Add(-time.Hour) }, time.Minute), false},
+		{"no heartbeat", Heartbeat(func() time.Time { return time.Time{} }, time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check(context.Background())
+			if (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+
+	if len(cache.values) != 0 {
+		t.Errorf("cache round trip left %v behind", cache.values)
+	}
+}
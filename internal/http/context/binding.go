@@ -0,0 +1,500 @@
+package context
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onyx-go/framework/internal/storage"
+	"github.com/onyx-go/framework/internal/validation"
+)
+
+// ErrUnsupportedContentType is returned by Bind when the request body has a
+// content type that cannot be bound
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// timeLayouts are tried in order when a time.Time field has no time_format tag
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var (
+	uploadedFileType    = reflect.TypeOf(&storage.UploadedFile{})
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind fills obj, a pointer to a struct, from the request. The body is decoded
+// according to its Content-Type: JSON and XML use their usual tags, while
+// url-encoded and multipart forms use `form` tags (falling back to the field
+// name), with uploads bound into *storage.UploadedFile fields. Fields tagged `query`, `param` and `header` are then filled from the
+// query string, route parameters and request headers.
+//
+// Slices take every value sent for a key, nested structs use dotted keys such
+// as "address.city", pointers are allocated when a value is present and
+// time.Time fields accept RFC 3339 or the layout given in a `time_format` tag.
+// Values that cannot be converted are reported together as
+// validation.ValidationErrors, one entry per field.
+func (c *Context) Bind(obj interface{}) error {
+	target := reflect.ValueOf(obj)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a non-nil pointer to a struct, got %T", obj)
+	}
+
+	var fieldErrors validation.ValidationErrors
+
+	if err := c.bindBody(obj, &fieldErrors); err != nil {
+		return err
+	}
+
+	bindValues(target.Elem(), "query", "", false, c.queries, &fieldErrors)
+	bindValues(target.Elem(), "header", "", false, c.request.Header, &fieldErrors)
+	bindValues(target.Elem(), "param", "", false, paramValues(c.params), &fieldErrors)
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// BindAndValidate binds the request into obj and validates it against its
+// `validate` tags. Conversion failures and rule failures are returned together
+// in one validation result; err is only set when the request could not be
+// bound at all, such as for a malformed body.
+func (c *Context) BindAndValidate(obj interface{}) (validation.Result, error) {
+	result := validation.NewResult()
+
+	err := c.Bind(obj)
+
+	var fieldErrors validation.ValidationErrors
+	switch {
+	case errors.As(err, &fieldErrors):
+		for _, fieldError := range fieldErrors {
+			result.AddError(fieldError.Field, fieldError.Message)
+		}
+	case err != nil:
+		return nil, err
+	}
+
+	validator := validation.NewValidator(nil, nil)
+	validated := validator.ValidateStruct(c.request.Context(), obj)
+
+	// A field that failed to convert is only reported once
+	for field, messages := range validated.GetErrors() {
+		if !result.HasError(field) {
+			result.AddErrors(field, messages)
+		}
+	}
+
+	return result, nil
+}
+
+// bindBody decodes the request body into obj according to its content type
+func (c *Context) bindBody(obj interface{}, fieldErrors *validation.ValidationErrors) error {
+	if c.request.Body == nil || c.request.Body == http.NoBody || c.request.ContentLength == 0 {
+		return nil
+	}
+
+	contentType := c.Header("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if err := json.NewDecoder(c.request.Body).Decode(obj); err != nil && err != io.EOF {
+			var typeError *json.UnmarshalTypeError
+			if errors.As(err, &typeError) && typeError.Field != "" {
+				*fieldErrors = append(*fieldErrors, typeMismatch(typeError.Field, typeError.Type, typeError.Value))
+				return nil
+			}
+			return fmt.Errorf("invalid JSON body: %w", err)
+		}
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		if err := xml.NewDecoder(c.request.Body).Decode(obj); err != nil && err != io.EOF {
+			return fmt.Errorf("invalid XML body: %w", err)
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := c.request.ParseForm(); err != nil {
+			return fmt.Errorf("invalid form body: %w", err)
+		}
+		bindValues(reflect.ValueOf(obj).Elem(), "form", "", true, c.request.PostForm, fieldErrors)
+	case mediaType == "multipart/form-data":
+		form, err := c.multipartForm()
+		if err != nil {
+			return fmt.Errorf("invalid multipart body: %w", err)
+		}
+		bindValues(reflect.ValueOf(obj).Elem(), "form", "", true, form.Value, fieldErrors)
+		bindFiles(reflect.ValueOf(obj).Elem(), form.File)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	return nil
+}
+
+// bindValues fills the fields of target carrying tag from values. When
+// useFieldName is set, untagged fields are bound under their Go name. It
+// reports whether any field was set.
+func bindValues(target reflect.Value, tag, prefix string, useFieldName bool, values map[string][]string, fieldErrors *validation.ValidationErrors) bool {
+	return bindFields(target, tag, prefix, useFieldName, values, fieldErrors, map[reflect.Type]string{})
+}
+
+// bindFields is bindValues with the prefix each struct type on the current
+// path was entered at. A type met again at the same prefix would bind the same
+// keys forever, as with an untagged `Parent *Category`, so it is skipped.
+func bindFields(target reflect.Value, tag, prefix string, useFieldName bool, values map[string][]string, fieldErrors *validation.ValidationErrors, visiting map[reflect.Type]string) bool {
+	targetType := target.Type()
+	if entered, ok := visiting[targetType]; ok && entered == prefix {
+		return false
+	}
+	previous, wasVisiting := visiting[targetType]
+	visiting[targetType] = prefix
+	defer func() {
+		if wasVisiting {
+			visiting[targetType] = previous
+		} else {
+			delete(visiting, targetType)
+		}
+	}()
+
+	bound := false
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		value := target.Field(i)
+
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs are flattened into their parent
+		if field.Anonymous && name == "" {
+			if nested, ok := structValue(value); ok {
+				if bindNested(value, nested, tag, prefix, useFieldName, values, fieldErrors, visiting) {
+					bound = true
+				}
+			}
+			continue
+		}
+
+		if !field.IsExported() || isFileField(field.Type) {
+			continue
+		}
+
+		if nested, ok := structValue(value); ok {
+			nestedPrefix := prefix
+			if name != "" || useFieldName {
+				nestedPrefix = prefix + nameOrField(name, field) + "."
+			}
+			if bindNested(value, nested, tag, nestedPrefix, useFieldName, values, fieldErrors, visiting) {
+				bound = true
+			}
+			continue
+		}
+
+		if name == "" && !useFieldName {
+			continue
+		}
+
+		key := prefix + nameOrField(name, field)
+		lookup := key
+		if tag == "header" {
+			lookup = http.CanonicalHeaderKey(key)
+		}
+		raw, ok := values[lookup]
+		if !ok {
+			raw, ok = values[lookup+"[]"]
+		}
+		if !ok || len(raw) == 0 {
+			continue
+		}
+
+		if err := setValue(value, raw, field.Tag.Get("time_format")); err != nil {
+			*fieldErrors = append(*fieldErrors, typeMismatch(key, field.Type, strings.Join(raw, ",")))
+			continue
+		}
+		bound = true
+	}
+
+	return bound
+}
+
+// bindFiles fills *storage.UploadedFile and []*storage.UploadedFile fields
+// from the uploaded files named by their `form` tag or field name
+func bindFiles(target reflect.Value, files map[string][]*multipart.FileHeader) {
+	targetType := target.Type()
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if !field.IsExported() || !isFileField(field.Type) {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		name = nameOrField(name, field)
+
+		headers := files[name]
+		if len(headers) == 0 {
+			headers = files[name+"[]"]
+		}
+		if len(headers) == 0 {
+			continue
+		}
+
+		if field.Type == uploadedFileType {
+			target.Field(i).Set(reflect.ValueOf(storage.NewUploadedFile(name, headers[0])))
+			continue
+		}
+
+		uploaded := make([]*storage.UploadedFile, len(headers))
+		for j, header := range headers {
+			uploaded[j] = storage.NewUploadedFile(name, header)
+		}
+		target.Field(i).Set(reflect.ValueOf(uploaded))
+	}
+}
+
+func isFileField(t reflect.Type) bool {
+	return t == uploadedFileType || (t.Kind() == reflect.Slice && t.Elem() == uploadedFileType)
+}
+
+// bindNested binds a nested struct, allocating a nil struct pointer only when
+// one of its fields receives a value. Structs with no key under prefix are not
+// entered at all, which also ends the descent into self-referencing types.
+func bindNested(value, nested reflect.Value, tag, prefix string, useFieldName bool, values map[string][]string, fieldErrors *validation.ValidationErrors, visiting map[reflect.Type]string) bool {
+	if !hasKeyPrefix(values, tag, prefix) {
+		return false
+	}
+
+	if value.Kind() != reflect.Ptr || !value.IsNil() {
+		return bindFields(nested, tag, prefix, useFieldName, values, fieldErrors, visiting)
+	}
+
+	if !value.CanSet() {
+		return false
+	}
+
+	allocated := reflect.New(value.Type().Elem())
+	if !bindFields(allocated.Elem(), tag, prefix, useFieldName, values, fieldErrors, visiting) {
+		return false
+	}
+	value.Set(allocated)
+	return true
+}
+
+// hasKeyPrefix reports whether any key in values starts with prefix. Header
+// names are canonicalized, so they are compared without regard to case.
+func hasKeyPrefix(values map[string][]string, tag, prefix string) bool {
+	if prefix == "" {
+		return len(values) > 0
+	}
+	for key := range values {
+		if strings.HasPrefix(key, prefix) || (tag == "header" && len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// structValue returns the struct a field holds or points to, unless the
+// struct is bound from a single value like time.Time
+func structValue(value reflect.Value) (reflect.Value, bool) {
+	t := value.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return reflect.Value{}, false
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, true
+		}
+		return value.Elem(), true
+	}
+	return value, true
+}
+
+func nameOrField(name string, field reflect.StructField) string {
+	if name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// setValue converts raw into value, allocating pointers and filling slices
+func setValue(value reflect.Value, raw []string, layout string) error {
+	switch {
+	case value.Kind() == reflect.Ptr:
+		allocated := reflect.New(value.Type().Elem())
+		if err := setValue(allocated.Elem(), raw, layout); err != nil {
+			return err
+		}
+		value.Set(allocated)
+		return nil
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8:
+		slice := reflect.MakeSlice(value.Type(), len(raw), len(raw))
+		for i, item := range raw {
+			if err := setValue(slice.Index(i), []string{item}, layout); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+
+	return setScalar(value, raw[0], layout)
+}
+
+// setScalar converts a single string into value
+func setScalar(value reflect.Value, raw, layout string) error {
+	if value.Type() == timeType {
+		if raw == "" {
+			return nil
+		}
+		parsed, err := parseTime(raw, layout)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	if value.CanAddr() {
+		if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(raw))
+		}
+	}
+
+	if value.Type() == durationType {
+		if raw == "" {
+			return nil
+		}
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+		return nil
+	case reflect.Slice:
+		// []byte takes the raw string
+		value.SetBytes([]byte(raw))
+		return nil
+	}
+
+	// Empty values leave numbers and booleans at their zero value
+	if raw == "" {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		if raw == "on" {
+			value.SetBool(true)
+			return nil
+		}
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("cannot bind into %s", value.Type())
+	}
+
+	return nil
+}
+
+func parseTime(raw, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, raw)
+	}
+
+	for _, candidate := range timeLayouts {
+		if parsed, err := time.Parse(candidate, raw); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", raw)
+}
+
+// describeType names a field type in binding error messages
+func describeType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return "date"
+	case t == durationType:
+		return "duration"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "positive integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return t.String()
+}
+
+func typeMismatch(field string, t reflect.Type, value string) *validation.ValidationError {
+	return validation.NewValidationError(field, "type", fmt.Sprintf("the %s field must be a valid %s", field, describeType(t)), value, nil)
+}
+
+func paramValues(params map[string]string) map[string][]string {
+	values := make(map[string][]string, len(params))
+	for name, value := range params {
+		values[name] = []string{value}
+	}
+	return values
+}
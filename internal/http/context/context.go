@@ -7,9 +7,9 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 
 	httpInternal "github.com/onyx-go/framework/internal/http"
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // Context implements the HTTP context interface
@@ -19,11 +19,16 @@ type Context struct {
 	params         map[string]string
 	queries        url.Values
 	app            httpInternal.Application
+	urls           httpInternal.URLGenerator
 	index          int
 	middleware     []httpInternal.MiddlewareFunc
 	data           map[string]interface{}
 	aborted        bool
 	statusCode     int
+	proxies        *httpInternal.TrustedProxies
+	client         *httpInternal.ClientInfo
+	
+	maxMultipartMemory int64
 }
 
 // NewContext creates a new HTTP context
@@ -39,6 +44,8 @@ func NewContext(w http.ResponseWriter, r *http.Request, app httpInternal.Applica
 		data:           make(map[string]interface{}),
 		aborted:        false,
 		statusCode:     200,
+		
+		maxMultipartMemory: DefaultMaxMultipartMemory,
 	}
 }
 
@@ -143,19 +150,6 @@ func (c *Context) Get(key string) (interface{}, bool) {
 
 // Request body methods implementation
 
-func (c *Context) Bind(obj interface{}) error {
-	contentType := c.Header("Content-Type")
-	
-	if strings.Contains(contentType, "application/json") {
-		decoder := json.NewDecoder(c.request.Body)
-		return decoder.Decode(obj)
-	}
-	
-	// For form data, we would implement form binding here
-	// This is a simplified implementation
-	return fmt.Errorf("unsupported content type: %s", contentType)
-}
-
 func (c *Context) Body() ([]byte, error) {
 	return io.ReadAll(c.request.Body)
 }
@@ -170,6 +164,16 @@ func (c *Context) Request() *http.Request {
 	return c.request
 }
 
+// SetRequest replaces the request, e.g. with one carrying a deadline
+func (c *Context) SetRequest(req *http.Request) {
+	c.request = req
+}
+
+// SetResponseWriter replaces the response writer for the rest of the chain
+func (c *Context) SetResponseWriter(w http.ResponseWriter) {
+	c.responseWriter = w
+}
+
 // Context-specific methods for router integration
 
 // SetParam sets a route parameter value
@@ -177,6 +181,11 @@ func (c *Context) SetParam(key, value string) {
 	c.params[key] = value
 }
 
+// SetURLGenerator sets the generator used to build named route URLs
+func (c *Context) SetURLGenerator(urls httpInternal.URLGenerator) {
+	c.urls = urls
+}
+
 // AddMiddleware adds middleware to the context's middleware chain
 func (c *Context) AddMiddleware(middleware ...httpInternal.MiddlewareFunc) {
 	c.middleware = append(c.middleware, middleware...)
@@ -212,18 +221,47 @@ func (c *Context) UserAgent() string {
 	return c.request.UserAgent()
 }
 
+// RemoteIP returns the client address. Forwarding headers are only used
+// when the connecting peer is a trusted proxy.
 func (c *Context) RemoteIP() string {
-	forwarded := c.request.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return strings.Split(forwarded, ",")[0]
-	}
-	
-	realIP := c.request.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+	return c.clientInfo().IP
+}
+
+// Scheme returns "http" or "https" as seen by the client
+func (c *Context) Scheme() string {
+	return c.clientInfo().Scheme
+}
+
+// Host returns the host the client addressed, including any port
+func (c *Context) Host() string {
+	return c.clientInfo().Host
+}
+
+// RequestID returns the ID of the request, taken from X-Request-ID or
+// generated, or "" when request ID middleware is not in use
+func (c *Context) RequestID() string {
+	return tracing.RequestID(c.request.Context())
+}
+
+// SpanContext returns the W3C trace context of the request
+func (c *Context) SpanContext() tracing.SpanContext {
+	sc, _ := tracing.SpanContextFrom(c.request.Context())
+	return sc
+}
+
+// SetTrustedProxies sets the proxies whose forwarding headers are believed
+func (c *Context) SetTrustedProxies(proxies *httpInternal.TrustedProxies) {
+	c.proxies = proxies
+	c.client = nil
+}
+
+// clientInfo resolves the client address, scheme and host once per request
+func (c *Context) clientInfo() *httpInternal.ClientInfo {
+	if c.client == nil {
+		info := c.proxies.Resolve(c.request)
+		c.client = &info
 	}
-	
-	return strings.Split(c.request.RemoteAddr, ":")[0]
+	return c.client
 }
 
 func (c *Context) Redirect(code int, location string) error {
@@ -232,6 +270,23 @@ func (c *Context) Redirect(code int, location string) error {
 	return nil
 }
 
+// RouteURL generates the URL for a named route
+func (c *Context) RouteURL(name string, params map[string]string, query url.Values) (string, error) {
+	if c.urls == nil {
+		return "", fmt.Errorf("cannot generate URL for route %q: no URL generator configured", name)
+	}
+	return c.urls.URL(name, params, query)
+}
+
+// RedirectToRoute redirects to the URL of a named route
+func (c *Context) RedirectToRoute(code int, name string, params map[string]string, query url.Values) error {
+	location, err := c.RouteURL(name, params, query)
+	if err != nil {
+		return err
+	}
+	return c.Redirect(code, location)
+}
+
 // Application returns the application instance
 func (c *Context) Application() httpInternal.Application {
 	return c.app
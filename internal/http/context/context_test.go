@@ -1,14 +1,23 @@
 package context
 
 import (
+	"bytes"
+	stdcontext "context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	httpInternal "github.com/onyx-go/framework/internal/http"
+	"github.com/onyx-go/framework/internal/storage"
+	"github.com/onyx-go/framework/internal/validation"
 )
 
 // Mock application for testing
@@ -125,9 +134,20 @@ func TestContextRequestMethods(t *testing.T) {
 		t.Errorf("expected UserAgent test-agent, got %s", ctx.UserAgent())
 	}
 
+	// X-Real-IP is ignored unless the peer is a trusted proxy
+	if ctx.RemoteIP() != "192.0.2.1" {
+		t.Errorf("expected the peer address 192.0.2.1, got %s", ctx.RemoteIP())
+	}
+
+	proxies, _ := httpInternal.NewTrustedProxies("192.0.2.0/24")
+	ctx.SetTrustedProxies(proxies)
 	if ctx.RemoteIP() != "192.168.1.1" {
 		t.Errorf("expected RemoteIP 192.168.1.1, got %s", ctx.RemoteIP())
 	}
+
+	if ctx.Scheme() != "http" || ctx.Host() != "example.com" {
+		t.Errorf("expected http://example.com, got %s://%s", ctx.Scheme(), ctx.Host())
+	}
 }
 
 func TestContextParams(t *testing.T) {
@@ -411,6 +431,337 @@ func TestContextBind(t *testing.T) {
 	}
 }
 
+type bindAddress struct {
+	City string `form:"city"`
+	Zip  *int   `form:"zip"`
+}
+
+type bindPagination struct {
+	Page    int `query:"page"`
+	PerPage int `query:"per_page"`
+}
+
+type bindProfile struct {
+	bindPagination
+	ID        int           `param:"id"`
+	RequestID string        `header:"X-Request-ID"`
+	Name      string        `form:"name" xml:"name" validate:"required|min:3"`
+	Email     string        `form:"email" xml:"email" validate:"email"`
+	Age       *int          `form:"age" xml:"age"`
+	Admin     bool          `form:"admin"`
+	Tags      []string      `form:"tags" xml:"tag"`
+	Scores    []float64     `form:"scores"`
+	Born      time.Time     `form:"born" time_format:"2006-01-02"`
+	Seen      *time.Time    `form:"seen"`
+	Timeout   time.Duration `form:"timeout"`
+	Address   bindAddress   `form:"address"`
+	Billing   *bindAddress  `form:"billing"`
+	Ignored   string        `form:"-"`
+}
+
+func newBindContext(req *http.Request) *Context {
+	ctx := NewContext(httptest.NewRecorder(), req, &mockApplication{})
+	ctx.SetParam("id", "42")
+	return ctx
+}
+
+func TestContextBindForm(t *testing.T) {
+	form := url.Values{
+		"name":         {"Ada"},
+		"email":        {"ada@example.com"},
+		"age":          {"36"},
+		"admin":        {"on"},
+		"tags[]":       {"math", "engines"},
+		"scores":       {"1.5", "2.5"},
+		"born":         {"1815-12-10"},
+		"seen":         {"2024-01-02T15:04:05Z"},
+		"timeout":      {"1m30s"},
+		"address.city": {"London"},
+		"address.zip":  {"12345"},
+		"Ignored":      {"nope"},
+	}
+	req := httptest.NewRequest("POST", "/profiles/42?page=3&per_page=25", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("X-Request-Id", "abc123")
+
+	var profile bindProfile
+	if err := newBindContext(req).Bind(&profile); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+
+	if profile.Name != "Ada" || profile.Email != "ada@example.com" || !profile.Admin {
+		t.Errorf("unexpected scalar fields: %+v", profile)
+	}
+	if profile.Age == nil || *profile.Age != 36 {
+		t.Errorf("expected age pointer 36, got %v", profile.Age)
+	}
+	if len(profile.Tags) != 2 || profile.Tags[1] != "engines" {
+		t.Errorf("expected tags from tags[], got %v", profile.Tags)
+	}
+	if len(profile.Scores) != 2 || profile.Scores[1] != 2.5 {
+		t.Errorf("unexpected scores %v", profile.Scores)
+	}
+	if !profile.Born.Equal(time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected born %v", profile.Born)
+	}
+	if profile.Seen == nil || profile.Seen.Hour() != 15 {
+		t.Errorf("unexpected seen %v", profile.Seen)
+	}
+	if profile.Timeout != 90*time.Second {
+		t.Errorf("unexpected timeout %v", profile.Timeout)
+	}
+	if profile.Address.City != "London" || profile.Address.Zip == nil || *profile.Address.Zip != 12345 {
+		t.Errorf("unexpected nested address %+v", profile.Address)
+	}
+	if profile.Billing != nil {
+		t.Errorf("expected billing to stay nil without values, got %+v", profile.Billing)
+	}
+	if profile.Ignored != "" {
+		t.Errorf("expected ignored field to stay empty")
+	}
+	if profile.Page != 3 || profile.PerPage != 25 {
+		t.Errorf("expected embedded query fields, got page=%d per_page=%d", profile.Page, profile.PerPage)
+	}
+	if profile.ID != 42 {
+		t.Errorf("expected param id=42, got %d", profile.ID)
+	}
+	if profile.RequestID != "abc123" {
+		t.Errorf("expected header value, got %q", profile.RequestID)
+	}
+}
+
+func TestContextBindMultipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("name", "Grace")
+	writer.WriteField("tags", "navy")
+	writer.WriteField("tags", "cobol")
+	writer.WriteField("billing.city", "Arlington")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/profiles", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var profile bindProfile
+	if err := newBindContext(req).Bind(&profile); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+
+	if profile.Name != "Grace" || len(profile.Tags) != 2 {
+		t.Errorf("unexpected multipart binding: %+v", profile)
+	}
+	if profile.Billing == nil || profile.Billing.City != "Arlington" {
+		t.Errorf("expected billing pointer to be allocated, got %+v", profile.Billing)
+	}
+}
+
+type bindCategory struct {
+	Name   string        `json:"name" form:"name" query:"name"`
+	Parent *bindCategory `json:"parent" form:"parent"`
+}
+
+func TestContextBindSelfReferencing(t *testing.T) {
+	req := httptest.NewRequest("POST", "/categories?name=override", strings.NewReader(`{"name":"child","parent":{"name":"root"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "abc123")
+
+	var category bindCategory
+	if err := newBindContext(req).Bind(&category); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+	if category.Name != "override" || category.Parent == nil || category.Parent.Name != "root" || category.Parent.Parent != nil {
+		t.Errorf("unexpected JSON binding: %+v", category)
+	}
+
+	form := url.Values{"name": {"leaf"}, "parent.name": {"branch"}, "parent.parent.name": {"root"}}
+	req = httptest.NewRequest("POST", "/categories", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	category = bindCategory{}
+	if err := newBindContext(req).Bind(&category); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+	if category.Parent == nil || category.Parent.Name != "branch" || category.Parent.Parent == nil ||
+		category.Parent.Parent.Name != "root" || category.Parent.Parent.Parent != nil {
+		t.Errorf("expected two parents from dotted keys, got %+v", category)
+	}
+}
+
+func TestContextBindXML(t *testing.T) {
+	body := `<profile><name>Linus</name><age>54</age><tag>kernel</tag><tag>git</tag></profile>`
+	req := httptest.NewRequest("PUT", "/profiles/42?page=2", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/xml")
+
+	var profile bindProfile
+	if err := newBindContext(req).Bind(&profile); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+
+	if profile.Name != "Linus" || profile.Age == nil || *profile.Age != 54 || len(profile.Tags) != 2 {
+		t.Errorf("unexpected XML binding: %+v", profile)
+	}
+	if profile.Page != 2 || profile.ID != 42 {
+		t.Errorf("expected query and param binding alongside XML, got page=%d id=%d", profile.Page, profile.ID)
+	}
+}
+
+func TestContextBindErrors(t *testing.T) {
+	form := url.Values{"name": {"Ada"}, "age": {"old"}, "scores": {"1", "x"}, "born": {"10/12/1815"}}
+	req := httptest.NewRequest("POST", "/profiles/42?page=first", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var profile bindProfile
+	err := newBindContext(req).Bind(&profile)
+
+	var fieldErrors validation.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		t.Fatalf("expected validation.ValidationErrors, got %v", err)
+	}
+	for _, field := range []string{"age", "scores", "born", "page"} {
+		if !fieldErrors.Has(field) {
+			t.Errorf("expected an error for %s, got %v", field, fieldErrors.ToMap())
+		}
+	}
+	if fieldErrors.First("age") != "the age field must be a valid integer" {
+		t.Errorf("unexpected message %q", fieldErrors.First("age"))
+	}
+	if profile.Name != "Ada" {
+		t.Errorf("expected valid fields to be bound despite errors")
+	}
+
+	req = httptest.NewRequest("POST", "/profiles", strings.NewReader("plain text"))
+	req.Header.Set("Content-Type", "text/plain")
+	if err := newBindContext(req).Bind(&profile); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("expected ErrUnsupportedContentType, got %v", err)
+	}
+
+	req = httptest.NewRequest("POST", "/profiles", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", "application/json")
+	if err := newBindContext(req).Bind(&profile); err == nil || errors.As(err, &fieldErrors) {
+		t.Errorf("expected a malformed body error, got %v", err)
+	}
+
+	if err := newBindContext(req).Bind(profile); err == nil {
+		t.Error("expected an error when binding into a non-pointer")
+	}
+}
+
+func TestContextBindAndValidate(t *testing.T) {
+	form := url.Values{"name": {"Al"}, "email": {"not-an-email"}, "age": {"young"}}
+	req := httptest.NewRequest("POST", "/profiles/42", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var profile bindProfile
+	result, err := newBindContext(req).BindAndValidate(&profile)
+	if err != nil {
+		t.Fatalf("BindAndValidate() returned error: %v", err)
+	}
+
+	for _, field := range []string{"name", "email", "age"} {
+		if !result.HasError(field) {
+			t.Errorf("expected an error for %s, got %v", field, result.GetErrors())
+		}
+	}
+
+	form = url.Values{"name": {"Alan"}, "email": {"alan@example.com"}}
+	req = httptest.NewRequest("POST", "/profiles/42", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	result, err = newBindContext(req).BindAndValidate(&profile)
+	if err != nil || result.Failed() {
+		t.Errorf("expected a valid result, got %v %v", result.GetErrors(), err)
+	}
+}
+
+func newUploadRequest(t *testing.T, files map[string]string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for field, value := range fields {
+		writer.WriteField(field, value)
+	}
+	for name, content := range files {
+		part, _ := writer.CreateFormFile("documents[]", name)
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestContextFiles(t *testing.T) {
+	req := newUploadRequest(t, map[string]string{"a.txt": "first", "b.txt": "second"}, map[string]string{"title": "Docs"})
+	ctx := newBindContext(req)
+	ctx.SetMaxMultipartMemory(1024)
+
+	files, err := ctx.Files("documents")
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Files() = %d files, %v", len(files), err)
+	}
+
+	file, err := ctx.File("documents")
+	if err != nil {
+		t.Fatalf("File() returned error: %v", err)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	if file.FieldName != "documents" || file.Size != int64(len(content)) || file.Extension != ".txt" {
+		t.Errorf("unexpected uploaded file %+v", file)
+	}
+
+	if _, err := ctx.File("missing"); !errors.Is(err, http.ErrMissingFile) {
+		t.Errorf("expected http.ErrMissingFile, got %v", err)
+	}
+
+	var form struct {
+		Title     string                  `form:"title"`
+		Documents []*storage.UploadedFile `form:"documents"`
+		Cover     *storage.UploadedFile   `form:"cover"`
+	}
+	if err := ctx.Bind(&form); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+	if form.Title != "Docs" || len(form.Documents) != 2 || form.Cover != nil {
+		t.Errorf("unexpected bound upload form %+v", form)
+	}
+}
+
+func TestContextStreamFiles(t *testing.T) {
+	req := newUploadRequest(t, map[string]string{"big.bin": strings.Repeat("x", 4096)}, map[string]string{"title": "Streamed"})
+	ctx := newBindContext(req)
+
+	var streamed []string
+	values, err := ctx.StreamFiles(func(file *storage.UploadedFile) error {
+		data, err := io.ReadAll(file.Content)
+		streamed = append(streamed, fmt.Sprintf("%s:%s:%d", file.FieldName, file.OriginalName, len(data)))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("StreamFiles() returned error: %v", err)
+	}
+	if len(streamed) != 1 || streamed[0] != "documents[]:big.bin:4096" {
+		t.Errorf("unexpected streamed files %v", streamed)
+	}
+	if values.Get("title") != "Streamed" {
+		t.Errorf("expected form values to be collected, got %v", values)
+	}
+
+	// Form values beyond the memory limit are rejected
+	req = newUploadRequest(t, nil, map[string]string{"notes": strings.Repeat("n", 100)})
+	ctx = newBindContext(req)
+	ctx.SetMaxMultipartMemory(10)
+	if _, err := ctx.StreamFiles(func(*storage.UploadedFile) error { return nil }); !errors.Is(err, multipart.ErrMessageTooLarge) {
+		t.Errorf("expected multipart.ErrMessageTooLarge, got %v", err)
+	}
+}
+
 func TestContextBody(t *testing.T) {
 	bodyContent := "test body content"
 	req := httptest.NewRequest("POST", "/test", strings.NewReader(bodyContent))
@@ -506,6 +857,49 @@ func TestContextRedirect(t *testing.T) {
 	}
 }
 
+type mockURLGenerator struct{}
+
+func (m *mockURLGenerator) URL(name string, params map[string]string, query url.Values) (string, error) {
+	if name != "users.show" {
+		return "", fmt.Errorf("route %q not defined", name)
+	}
+	result := "/users/" + params["id"]
+	if len(query) > 0 {
+		result += "?" + query.Encode()
+	}
+	return result, nil
+}
+
+func TestContextRedirectToRoute(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	ctx := NewContext(w, req, &mockApplication{})
+
+	if _, err := ctx.RouteURL("users.show", nil, nil); err == nil {
+		t.Error("expected error when no URL generator is configured")
+	}
+
+	ctx.SetURLGenerator(&mockURLGenerator{})
+
+	err := ctx.RedirectToRoute(303, "users.show", map[string]string{"id": "5"}, url.Values{"ok": {"1"}})
+	if err != nil {
+		t.Fatalf("RedirectToRoute() returned error: %v", err)
+	}
+
+	if w.Code != 303 {
+		t.Errorf("expected status code 303, got %d", w.Code)
+	}
+
+	if w.Header().Get("Location") != "/users/5?ok=1" {
+		t.Errorf("expected Location header '/users/5?ok=1', got '%s'", w.Header().Get("Location"))
+	}
+
+	if err := ctx.RedirectToRoute(302, "missing", nil, nil); err == nil {
+		t.Error("expected error for unknown route")
+	}
+}
+
 func TestContextPostForm(t *testing.T) {
 	form := url.Values{}
 	form.Add("username", "testuser")
@@ -557,4 +951,96 @@ func TestContextApplication(t *testing.T) {
 	if ctx.Application() != app {
 		t.Error("Application() should return the application instance")
 	}
-}
\ No newline at end of file
+}
+
+func TestContextSSE(t *testing.T) {
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Last-Event-ID", "41")
+	w := httptest.NewRecorder()
+	ctx := NewContext(w, req, &mockApplication{})
+
+	stream, err := ctx.SSE()
+	if err != nil {
+		t.Fatalf("SSE() returned error: %v", err)
+	}
+	defer stream.Close()
+
+	if stream.LastEventID() != "41" {
+		t.Errorf("expected Last-Event-ID 41, got %q", stream.LastEventID())
+	}
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", w.Header().Get("Content-Type"))
+	}
+	if !w.Flushed {
+		t.Error("expected headers to be flushed immediately")
+	}
+
+	stream.Retry(3 * time.Second)
+	stream.Send(SSEEvent{ID: "42", Event: "update", Data: map[string]int{"count": 1}})
+	stream.Data("line one\nline two")
+	stream.Comment("keep\nalive")
+
+	expected := "retry: 3000\n\n" +
+		"id: 42\nevent: update\ndata: {\"count\":1}\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		": keepalive\n\n"
+	if w.Body.String() != expected {
+		t.Errorf("unexpected event stream:\n%q\nwant:\n%q", w.Body.String(), expected)
+	}
+
+	stream.Close()
+	if err := stream.Data("late"); err == nil {
+		t.Error("expected error sending on a closed stream")
+	}
+}
+
+func TestContextSSEClientDisconnect(t *testing.T) {
+	reqCtx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(reqCtx)
+	w := httptest.NewRecorder()
+	ctx := NewContext(w, req, &mockApplication{})
+
+	stream, err := ctx.SSE()
+	if err != nil {
+		t.Fatalf("SSE() returned error: %v", err)
+	}
+	defer stream.Close()
+
+	cancel()
+
+	select {
+	case <-stream.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected Done to be closed after disconnect")
+	}
+	if err := stream.Data("gone"); err == nil {
+		t.Error("expected error sending to a disconnected client")
+	}
+}
+
+func TestContextStream(t *testing.T) {
+	req := httptest.NewRequest("GET", "/stream", nil)
+	w := httptest.NewRecorder()
+	ctx := NewContext(w, req, &mockApplication{})
+
+	chunks := []string{"a", "b", "c"}
+	i := 0
+	err := ctx.Stream(200, "text/plain", func(out io.Writer) bool {
+		io.WriteString(out, chunks[i])
+		i++
+		return i < len(chunks)
+	})
+	if err != nil {
+		t.Fatalf("Stream() returned error: %v", err)
+	}
+
+	if w.Body.String() != "abc" {
+		t.Errorf("expected body abc, got %q", w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("expected text/plain, got %q", w.Header().Get("Content-Type"))
+	}
+	if !w.Flushed {
+		t.Error("expected stream to be flushed")
+	}
+}
@@ -0,0 +1,229 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned when the response writer cannot flush
+var ErrStreamingUnsupported = errors.New("response writer does not support streaming")
+
+// Stream sends a streaming response. step is called repeatedly and whatever it
+// writes is flushed to the client immediately; returning false ends the
+// stream. Streaming also stops when the client disconnects.
+func (c *Context) Stream(code int, contentType string, step func(w io.Writer) bool) error {
+	controller := http.NewResponseController(c.ResponseWriter())
+
+	c.SetHeader("Content-Type", contentType)
+	c.SetHeader("Cache-Control", "no-cache")
+	c.SetHeader("X-Accel-Buffering", "no")
+	c.Status(code)
+
+	// Long-lived responses must outlive the server's WriteTimeout
+	controller.SetWriteDeadline(time.Time{})
+
+	if err := controller.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrStreamingUnsupported, err)
+	}
+
+	done := c.request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		keepOpen := step(c.ResponseWriter())
+		if err := controller.Flush(); err != nil {
+			return err
+		}
+		if !keepOpen {
+			return nil
+		}
+	}
+}
+
+// SSEEvent is a single Server-Sent Event. Data that is not a string or byte
+// slice is encoded as JSON.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// SSEStream writes Server-Sent Events to the client. It is safe for
+// concurrent use, so events may be sent from other goroutines.
+type SSEStream struct {
+	writer      http.ResponseWriter
+	controller  *http.ResponseController
+	done        <-chan struct{}
+	lastEventID string
+
+	mutex  sync.Mutex
+	closed bool
+	stop   chan struct{}
+}
+
+// SSE starts a Server-Sent Events response and returns the stream to send
+// events on. Client disconnects are reported by Done. Close the stream before
+// the handler returns, typically with defer, so a running Heartbeat stops.
+func (c *Context) SSE() (*SSEStream, error) {
+	controller := http.NewResponseController(c.ResponseWriter())
+
+	c.SetHeader("Content-Type", "text/event-stream")
+	c.SetHeader("Cache-Control", "no-cache")
+	c.SetHeader("Connection", "keep-alive")
+	c.SetHeader("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Long-lived responses must outlive the server's WriteTimeout
+	controller.SetWriteDeadline(time.Time{})
+
+	if err := controller.Flush(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStreamingUnsupported, err)
+	}
+
+	return &SSEStream{
+		writer:      c.ResponseWriter(),
+		controller:  controller,
+		done:        c.request.Context().Done(),
+		lastEventID: c.Header("Last-Event-ID"),
+		stop:        make(chan struct{}),
+	}, nil
+}
+
+// LastEventID returns the Last-Event-ID sent by a reconnecting client, so the
+// handler can resume after the last event it delivered
+func (s *SSEStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done is closed when the client disconnects
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes an event and flushes it to the client
+func (s *SSEStream) Send(event SSEEvent) error {
+	var buf strings.Builder
+
+	if event.ID != "" {
+		buf.WriteString("id: " + sseField(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		buf.WriteString("event: " + sseField(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", event.Retry.Milliseconds())
+	}
+
+	if event.Data != nil {
+		data, err := sseData(event.Data)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(data, "\n") {
+			buf.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+		}
+	}
+
+	buf.WriteString("\n")
+	return s.write(buf.String())
+}
+
+// Data sends an unnamed event carrying only data
+func (s *SSEStream) Data(data interface{}) error {
+	return s.Send(SSEEvent{Data: data})
+}
+
+// Retry tells the client how long to wait before reconnecting
+func (s *SSEStream) Retry(d time.Duration) error {
+	return s.Send(SSEEvent{Retry: d})
+}
+
+// Comment sends a comment line, which clients ignore
+func (s *SSEStream) Comment(text string) error {
+	return s.write(": " + sseField(text) + "\n\n")
+}
+
+// Heartbeat sends a comment every interval until the stream is closed or the
+// client disconnects, keeping idle connections open through proxies
+func (s *SSEStream) Heartbeat(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.write(":\n\n"); err != nil {
+					return
+				}
+			case <-s.done:
+				return
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close ends the stream. Further sends return an error.
+func (s *SSEStream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+}
+
+func (s *SSEStream) write(payload string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return errors.New("sse stream closed")
+	}
+
+	select {
+	case <-s.done:
+		return errors.New("sse client disconnected")
+	default:
+	}
+
+	if _, err := io.WriteString(s.writer, payload); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// sseField strips line breaks, which would end the field early
+func sseField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// sseData renders event data as text
+func sseData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode event data: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,107 @@
+package context
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"github.com/onyx-go/framework/internal/storage"
+)
+
+// DefaultMaxMultipartMemory is how much of a multipart form is held in memory;
+// larger file parts are stored in temporary files
+const DefaultMaxMultipartMemory = 32 << 20
+
+// SetMaxMultipartMemory sets how much of a multipart form File, Files and Bind
+// keep in memory, and how much form data StreamFiles accepts
+func (c *Context) SetMaxMultipartMemory(bytes int64) {
+	c.maxMultipartMemory = bytes
+}
+
+// File returns the first file uploaded under name, or http.ErrMissingFile
+func (c *Context) File(name string) (*storage.UploadedFile, error) {
+	files, err := c.Files(name)
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// Files returns every file uploaded under name, also accepting the "name[]"
+// convention, or http.ErrMissingFile when there are none
+func (c *Context) Files(name string) ([]*storage.UploadedFile, error) {
+	form, err := c.multipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := form.File[name]
+	if len(headers) == 0 {
+		headers = form.File[name+"[]"]
+	}
+	if len(headers) == 0 {
+		return nil, http.ErrMissingFile
+	}
+
+	files := make([]*storage.UploadedFile, len(headers))
+	for i, header := range headers {
+		files[i] = storage.NewUploadedFile(name, header)
+	}
+	return files, nil
+}
+
+// StreamFiles reads a multipart body part by part instead of buffering it,
+// for uploads too large to hold in memory or temporary files. fn is called
+// for each file part with a file whose content streams from the request and
+// can be read once, typically straight into an Uploader. Other form fields
+// are collected, up to the multipart memory limit, and returned.
+func (c *Context) StreamFiles(fn func(file *storage.UploadedFile) error) (url.Values, error) {
+	reader, err := c.request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+	remaining := c.maxMultipartMemory
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return values, err
+		}
+
+		if part.FileName() == "" {
+			data, err := io.ReadAll(io.LimitReader(part, remaining+1))
+			part.Close()
+			if err != nil {
+				return values, err
+			}
+			remaining -= int64(len(data))
+			if remaining < 0 {
+				return values, multipart.ErrMessageTooLarge
+			}
+			values.Add(part.FormName(), string(data))
+			continue
+		}
+
+		err = fn(storage.NewStreamedFile(part))
+		part.Close()
+		if err != nil {
+			return values, err
+		}
+	}
+}
+
+// multipartForm parses the multipart form once, within the memory limit
+func (c *Context) multipartForm() (*multipart.Form, error) {
+	if c.request.MultipartForm == nil {
+		if err := c.request.ParseMultipartForm(c.maxMultipartMemory); err != nil {
+			return nil, err
+		}
+	}
+	return c.request.MultipartForm, nil
+}
@@ -0,0 +1,29 @@
+package http
+
+import "fmt"
+
+// StatusError is an error that should be answered with a specific HTTP
+// status. Internal middleware returns it so the application's ErrorHandler
+// can render the right response without depending on its error types.
+type StatusError struct {
+	Code    int
+	Message string
+	Err     error
+}
+
+// NewStatusError creates a StatusError wrapping err
+func NewStatusError(code int, message string, err error) *StatusError {
+	return &StatusError{Code: code, Message: message, Err: err}
+}
+
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("[%d] %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
@@ -1,12 +1,20 @@
 package http
 
 import (
+	"io/fs"
 	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/onyx-go/framework/internal/http/websocket"
 )
 
 // HandlerFunc defines the signature for HTTP handlers
 type HandlerFunc func(Context) error
 
+// WebSocketHandler handles an upgraded WebSocket connection
+type WebSocketHandler func(c Context, conn *websocket.Conn) error
+
 // MiddlewareFunc defines the signature for middleware functions
 type MiddlewareFunc func(Context) error
 
@@ -21,7 +29,10 @@ type Context interface {
 	Param(key string) string
 	Header(key string) string
 	RemoteIP() string
+	Scheme() string
+	Host() string
 	UserAgent() string
+	RequestID() string
 	
 	// Response methods
 	Status(code int)
@@ -58,50 +69,90 @@ type Context interface {
 // Router interface defines the contract for HTTP routers
 type Router interface {
 	// HTTP method handlers
-	GET(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	POST(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	PUT(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	DELETE(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	PATCH(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	OPTIONS(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	HEAD(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	ANY(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
+	GET(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	POST(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	PUT(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	DELETE(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	PATCH(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	OPTIONS(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	HEAD(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	ANY(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	
+	// WebSocket endpoints
+	WS(pattern string, handler WebSocketHandler, middleware ...MiddlewareFunc) RouteDefinition
+	
+	// Static files
+	Static(prefix string, fsys fs.FS, options ...StaticOption) RouteDefinition
 	
 	// Middleware
 	Use(middleware ...MiddlewareFunc)
 	
 	// Route groups
 	Group(prefix string, middleware ...MiddlewareFunc) RouteGroup
+	Domain(pattern string, middleware ...MiddlewareFunc) RouteGroup
 	
 	// Configuration
 	SetNotFound(handler HandlerFunc)
+	SetMethodNotAllowed(handler HandlerFunc)
 	
 	// Route introspection
 	GetRoutes() []Route
 	
+	// Named routes
+	URLGenerator
+	Err() error
+	
 	// HTTP server integration
 	http.Handler
 }
 
 // RouteGroup interface defines the contract for grouped routes
 type RouteGroup interface {
-	GET(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	POST(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	PUT(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	DELETE(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
-	PATCH(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
+	GET(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	POST(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	PUT(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	DELETE(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	PATCH(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) RouteDefinition
+	WS(pattern string, handler WebSocketHandler, middleware ...MiddlewareFunc) RouteDefinition
+	Static(prefix string, fsys fs.FS, options ...StaticOption) RouteDefinition
 	
 	// Nested groups
 	Group(prefix string, middleware ...MiddlewareFunc) RouteGroup
+	Domain(pattern string, middleware ...MiddlewareFunc) RouteGroup
+	
+	// Name prefix for routes in the group
+	Name(prefix string) RouteGroup
 	
 	// Middleware
 	Use(middleware ...MiddlewareFunc)
 }
 
+// RouteDefinition allows a route to be configured after registration
+type RouteDefinition interface {
+	// Name assigns a unique name used for URL generation
+	Name(name string) RouteDefinition
+	
+	// WithTrashed lets route model binding resolve soft-deleted models
+	WithTrashed() RouteDefinition
+	
+	// Timeout gives the handler a deadline through its request context
+	Timeout(timeout time.Duration) RouteDefinition
+	
+	// MaxBodyBytes rejects larger request bodies with 413
+	MaxBodyBytes(n int64) RouteDefinition
+}
+
+// URLGenerator builds URLs for named routes
+type URLGenerator interface {
+	URL(name string, params map[string]string, query url.Values) (string, error)
+}
+
 // Route represents a single HTTP route
 type Route interface {
 	Method() string
 	Pattern() string
+	Domain() string
+	Name() string
 	Handler() HandlerFunc
 	Middleware() []MiddlewareFunc
 	ParamNames() []string
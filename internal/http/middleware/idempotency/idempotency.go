@@ -0,0 +1,280 @@
+// Package idempotency lets clients retry unsafe requests without repeating
+// their effects. The first response to a request carrying an Idempotency-Key
+// header is stored and replayed for retries with the same key.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/onyx-go/framework/internal/cache"
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+// HeaderName is the request header carrying the key
+const HeaderName = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed from the store
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength bounds the keys clients may send
+const maxKeyLength = 255
+
+// Config configures the middleware
+type Config struct {
+	// Store keeps responses and in-flight locks. Use a shared store, such as
+	// a file store on shared disk, when several processes serve the
+	// application. An in-memory store is created when nil.
+	Store cache.Store
+
+	// TTL is how long a response is replayed; 24 hours when zero
+	TTL time.Duration
+
+	// LockTimeout is how long a request may hold its key before a retry is
+	// let through; one minute when zero
+	LockTimeout time.Duration
+
+	// Methods are the methods the middleware applies to; POST and PATCH
+	// when empty
+	Methods []string
+
+	// MaxBodyBytes is the largest request body that is hashed and buffered
+	// for the handler; larger bodies get 413. The middleware runs before
+	// route body limits, so this bounds what it reads on their behalf.
+	// 1 MiB when zero.
+	MaxBodyBytes int64
+
+	// Scope, when set, namespaces keys, e.g. by the authenticated user, so
+	// one client cannot replay another's response
+	Scope func(c httpInternal.Context) string
+}
+
+// record is a stored response
+type record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// contextSetter is implemented by contexts whose response writer can be
+// replaced for the rest of the chain
+type contextSetter interface {
+	SetResponseWriter(w http.ResponseWriter)
+}
+
+// Middleware stores the first response to each Idempotency-Key and replays
+// it for identical retries. A retry arriving while the first request is
+// still running gets 409, and a key reused for a different request gets 422.
+// Requests without the header pass straight through. Responses are only
+// stored when the handler succeeds with a status below 500, so failed
+// attempts can be retried.
+func Middleware(config Config) httpInternal.MiddlewareFunc {
+	if config.Store == nil {
+		config.Store, _ = cache.NewMemoryStore(cache.MemoryConfig{Size: 10000})
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = time.Minute
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 1 << 20
+	}
+
+	return func(c httpInternal.Context) error {
+		key := c.Header(HeaderName)
+		if key == "" || !contains(config.Methods, c.Method()) {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			c.Abort()
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "Idempotency-Key must be at most 255 characters",
+			})
+		}
+		setter, ok := c.(contextSetter)
+		if !ok {
+			return c.Next()
+		}
+
+		ctx := c.Request().Context()
+		if config.Scope != nil {
+			key = config.Scope(c) + ":" + key
+		}
+		storeKey := "idempotency:" + key
+		lockKey := storeKey + ":lock"
+
+		fingerprint, err := fingerprint(c.Request(), c.ResponseWriter(), config.MaxBodyBytes)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Abort()
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]interface{}{
+				"error": fmt.Sprintf("Request body exceeds %d bytes", config.MaxBodyBytes),
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		if stored := load(c, config.Store, storeKey); stored != nil {
+			return replay(c, stored, fingerprint)
+		}
+
+		// Each request gets its own owner token, so a request whose lock
+		// expired cannot release the lock of the retry that took it over
+		lock := cache.NewLock(config.Store)
+		acquired, err := lock.Acquire(ctx, lockKey, config.LockTimeout)
+		if err != nil {
+			return err
+		}
+		if !acquired {
+			c.Abort()
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error": "A request with this Idempotency-Key is still being processed",
+			})
+		}
+		defer lock.Release(ctx, lockKey)
+
+		// The first request may have finished between the lookup and the lock
+		if stored := load(c, config.Store, storeKey); stored != nil {
+			return replay(c, stored, fingerprint)
+		}
+
+		w := c.ResponseWriter()
+		recorder := &recorder{ResponseWriter: w}
+		setter.SetResponseWriter(recorder)
+		err = c.Next()
+		setter.SetResponseWriter(w)
+
+		if err != nil || recorder.status == 0 || recorder.status >= 500 {
+			return err
+		}
+
+		data, err := json.Marshal(record{
+			Fingerprint: fingerprint,
+			Status:      recorder.status,
+			Header:      recorder.header,
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			return nil
+		}
+		// Stored as a string so it survives stores that serialize values
+		config.Store.Put(ctx, storeKey, &cache.Item{
+			Key:       storeKey,
+			Value:     string(data),
+			ExpiresAt: time.Now().Add(config.TTL),
+		})
+		return nil
+	}
+}
+
+// fingerprint identifies a request by method, URL and body. The body is put
+// back for the handler. Bodies over limit fail with *http.MaxBytesError
+// before more than limit bytes are read.
+func fingerprint(req *http.Request, w http.ResponseWriter, limit int64) (string, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.ContentLength > limit {
+			return "", &http.MaxBytesError{Limit: limit}
+		}
+		var err error
+		if body, err = io.ReadAll(http.MaxBytesReader(w, req.Body, limit)); err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// load returns the response stored under key, if any
+func load(c httpInternal.Context, store cache.Store, key string) *record {
+	item, err := store.Get(c.Request().Context(), key)
+	if err != nil {
+		return nil
+	}
+	data, ok := item.Value.(string)
+	if !ok {
+		return nil
+	}
+	var stored record
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil
+	}
+	return &stored
+}
+
+// replay writes a stored response, or 422 if it answered a different
+// request, in place of the rest of the chain
+func replay(c httpInternal.Context, stored *record, fingerprint string) error {
+	c.Abort()
+	if stored.Fingerprint != fingerprint {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": "Idempotency-Key was already used for a different request",
+		})
+	}
+
+	w := c.ResponseWriter()
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	_, err := w.Write(stored.Body)
+	return err
+}
+
+// recorder captures the response while passing it through
+type recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = r.ResponseWriter.Header().Clone()
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package idempotency
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onyx-go/framework/internal/cache"
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	contextImpl "github.com/onyx-go/framework/internal/http/context"
+)
+
+func run(middleware httpInternal.MiddlewareFunc, handler httpInternal.MiddlewareFunc, method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderName, key)
+	}
+	w := httptest.NewRecorder()
+	c := contextImpl.NewContext(w, req, nil)
+	c.AddMiddleware(middleware, handler)
+	c.Next()
+	return w
+}
+
+func TestMiddleware_Replay(t *testing.T) {
+	file, err := cache.NewFileStore(cache.FileConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	for name, store := range map[string]cache.Store{"memory": nil, "file": file} {
+		t.Run(name, func(t *testing.T) {
+			middleware := Middleware(Config{Store: store})
+
+			var calls atomic.Int32
+			handler := func(c httpInternal.Context) error {
+				body, _ := c.Body()
+				n := calls.Add(1)
+				c.SetHeader("X-Order", string(rune('0'+n)))
+				return c.JSON(http.StatusCreated, map[string]string{"created": string(body)})
+			}
+
+			first := run(middleware, handler, "POST", "abc", "pizza")
+			if first.Code != http.StatusCreated || first.Header().Get(ReplayedHeader) != "" {
+				t.Fatalf("Expected the first request to run, got %d", first.Code)
+			}
+
+			retry := run(middleware, handler, "POST", "abc", "pizza")
+			if calls.Load() != 1 {
+				t.Fatalf("Expected the retry not to run the handler, ran %d times", calls.Load())
+			}
+			if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() ||
+				retry.Header().Get("X-Order") != "1" || retry.Header().Get(ReplayedHeader) != "true" {
+				t.Errorf("Expected the first response replayed, got %d %v %s", retry.Code, retry.Header(), retry.Body.String())
+			}
+
+			if w := run(middleware, handler, "POST", "abc", "salad"); w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Expected 422 for a different request with the same key, got %d", w.Code)
+			}
+
+			run(middleware, handler, "POST", "", "pizza")
+			run(middleware, handler, "GET", "abc", "")
+			if calls.Load() != 3 {
+				t.Errorf("Expected requests without a key or with other methods to pass through, ran %d times", calls.Load())
+			}
+		})
+	}
+}
+
+func TestMiddleware_InFlight(t *testing.T) {
+	middleware := Middleware(Config{})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := func(c httpInternal.Context) error {
+		close(started)
+		<-release
+		return c.String(http.StatusOK, "done")
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- run(middleware, handler, "POST", "abc", "pizza") }()
+	<-started
+
+	if w := run(middleware, handler, "POST", "abc", "pizza"); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the first request runs, got %d", w.Code)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Fatalf("Expected the first request to finish, got %d", w.Code)
+	}
+	if w := run(middleware, handler, "POST", "abc", "pizza"); w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Errorf("Expected the finished response replayed, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddleware_ExpiredLock(t *testing.T) {
+	middleware := Middleware(Config{LockTimeout: 20 * time.Millisecond})
+
+	// Each attempt blocks until released and fails, so nothing is stored. A
+	// third attempt should never run, so it is not held up.
+	var attempts atomic.Int32
+	started := make(chan int, 3)
+	releases := []chan struct{}{make(chan struct{}), make(chan struct{}), make(chan struct{})}
+	close(releases[2])
+	handler := func(c httpInternal.Context) error {
+		n := int(attempts.Add(1)) - 1
+		started <- n
+		<-releases[n]
+		return c.String(http.StatusServiceUnavailable, "failed")
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- run(middleware, handler, "POST", "abc", "pizza") }()
+	<-started
+
+	// The first request outlives its lock, so a retry takes the key over
+	time.Sleep(40 * time.Millisecond)
+	second := make(chan *httptest.ResponseRecorder)
+	go func() { second <- run(middleware, handler, "POST", "abc", "pizza") }()
+	<-started
+
+	// The first request finishing must not release the retry's lock
+	close(releases[0])
+	<-first
+	if w := run(middleware, handler, "POST", "abc", "pizza"); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the retry holds the key, got %d", w.Code)
+	}
+
+	close(releases[1])
+	<-second
+}
+
+func TestMiddleware_BodyLimit(t *testing.T) {
+	middleware := Middleware(Config{MaxBodyBytes: 8})
+
+	var calls atomic.Int32
+	handler := func(c httpInternal.Context) error {
+		calls.Add(1)
+		body, _ := c.Body()
+		return c.String(http.StatusOK, string(body))
+	}
+
+	if w := run(middleware, handler, "POST", "abc", "pizza"); w.Code != http.StatusOK || w.Body.String() != "pizza" {
+		t.Errorf("Expected a small body to reach the handler, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := run(middleware, handler, "POST", "def", strings.Repeat("x", 9)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a body over the limit, got %d", w.Code)
+	}
+
+	// Without a Content-Length the limit applies while reading
+	req := httptest.NewRequest("POST", "/orders", io.MultiReader(strings.NewReader(strings.Repeat("x", 9))))
+	req.ContentLength = -1
+	req.Header.Set(HeaderName, "ghi")
+	w := httptest.NewRecorder()
+	c := contextImpl.NewContext(w, req, nil)
+	c.AddMiddleware(middleware, handler)
+	c.Next()
+	if w.Code != http.StatusRequestEntityTooLarge || calls.Load() != 1 {
+		t.Errorf("Expected 413 for a streamed body over the limit, got %d after %d calls", w.Code, calls.Load())
+	}
+}
+
+func TestMiddleware_ServerErrorsNotStored(t *testing.T) {
+	middleware := Middleware(Config{})
+
+	var calls atomic.Int32
+	handler := func(c httpInternal.Context) error {
+		if calls.Add(1) == 1 {
+			return c.String(http.StatusServiceUnavailable, "try again")
+		}
+		return c.String(http.StatusOK, "ok")
+	}
+
+	run(middleware, handler, "POST", "abc", "")
+	if w := run(middleware, handler, "POST", "abc", ""); w.Code != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("Expected a retry after a 5xx to run again, got %d after %d calls", w.Code, calls.Load())
+	}
+
+	if w := run(middleware, handler, "POST", strings.Repeat("k", 256), ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an overlong key, got %d", w.Code)
+	}
+}
+
+func TestFingerprint_RestoresBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders?x=1", strings.NewReader("pizza"))
+	first, _ := fingerprint(req, nil, 1<<20)
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "pizza" {
+		t.Errorf("Expected the body to be readable again, got %q", body)
+	}
+
+	other, _ := fingerprint(httptest.NewRequest("POST", "/orders?x=2", strings.NewReader("pizza")), nil, 1<<20)
+	if first == other {
+		t.Error("Expected the query string to change the fingerprint")
+	}
+}
@@ -0,0 +1,183 @@
+// Package limits provides middleware bounding how long a handler may run and
+// how large a request body may be.
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+var (
+	// ErrRequestTimeout is wrapped by the 503 returned when a handler
+	// exceeds its deadline
+	ErrRequestTimeout = errors.New("request timed out")
+
+	// ErrUpstreamTimeout is wrapped by the 504 returned when a handler fails
+	// because a call it made ran out of time
+	ErrUpstreamTimeout = errors.New("upstream timed out")
+
+	// ErrBodyTooLarge is wrapped by the 413 returned when a request body
+	// exceeds its limit
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// responseGrace is how long past a deadline the response may still be written
+const responseGrace = 5 * time.Second
+
+// contextSetter is implemented by contexts whose request and response
+// writer can be replaced for the rest of the chain
+type contextSetter interface {
+	SetRequest(req *http.Request)
+	SetResponseWriter(w http.ResponseWriter)
+}
+
+// Timeout gives the rest of the chain a deadline. The deadline is set on
+// c.Request().Context(), so database queries, queue dispatches and outgoing
+// calls made with it are cancelled when time runs out. The connection's read
+// and write deadlines follow it, overriding the server's ReadTimeout and
+// WriteTimeout for this request.
+//
+// When the deadline passes before a response is started, anything the
+// handler writes afterwards is discarded and a 503 wrapping ErrRequestTimeout
+// is returned for the ErrorHandler. A handler that fails with
+// context.DeadlineExceeded before its own deadline, because a call it made
+// had a shorter one, gets a 504 wrapping ErrUpstreamTimeout. Responses that
+// have already started are left alone.
+func Timeout(timeout time.Duration) httpInternal.MiddlewareFunc {
+	return func(c httpInternal.Context) error {
+		setter, ok := c.(contextSetter)
+		if !ok || timeout <= 0 {
+			return c.Next()
+		}
+
+		req, w := c.Request(), c.ResponseWriter()
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		// Replace the server-wide deadlines so a long route is not cut off by
+		// WriteTimeout, leaving time to send the error response
+		deadline, _ := ctx.Deadline()
+		controller := http.NewResponseController(w)
+		controller.SetReadDeadline(deadline)
+		controller.SetWriteDeadline(deadline.Add(responseGrace))
+
+		tw := &timeoutWriter{w: w, ctx: ctx, header: make(http.Header)}
+		setter.SetRequest(req.WithContext(ctx))
+		setter.SetResponseWriter(tw)
+
+		err := c.Next()
+
+		// The error response, if any, goes to the real writer
+		setter.SetRequest(req)
+		setter.SetResponseWriter(w)
+
+		if tw.started {
+			return err
+		}
+
+		if tw.timedOut || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return httpInternal.NewStatusError(http.StatusServiceUnavailable, "Request timed out",
+				errors.Join(ErrRequestTimeout, err))
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return httpInternal.NewStatusError(http.StatusGatewayTimeout, "Upstream timed out",
+				errors.Join(ErrUpstreamTimeout, err))
+		}
+		return err
+	}
+}
+
+// timeoutWriter holds back headers until the response starts and refuses to
+// start it once the deadline has passed
+type timeoutWriter struct {
+	w        http.ResponseWriter
+	ctx      context.Context
+	header   http.Header
+	started  bool
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	if tw.start() {
+		tw.w.WriteHeader(code)
+	}
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.start() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return tw.w.Write(b)
+}
+
+// Flush starts the response and flushes it
+func (tw *timeoutWriter) Flush() {
+	if tw.start() {
+		http.NewResponseController(tw.w).Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// start copies the held headers to the real writer the first time the
+// response is written, reporting false once the deadline has passed
+func (tw *timeoutWriter) start() bool {
+	if tw.started {
+		return true
+	}
+	if tw.timedOut || errors.Is(tw.ctx.Err(), context.DeadlineExceeded) {
+		tw.timedOut = true
+		return false
+	}
+
+	header := tw.w.Header()
+	for key, values := range tw.header {
+		header[key] = values
+	}
+	tw.started = true
+	return true
+}
+
+// MaxBodyBytes rejects request bodies larger than limit with a 413 wrapping
+// ErrBodyTooLarge. A declared Content-Length over the limit is refused
+// before the body is read; otherwise reading stops at the limit and the
+// error from Body, Bind or the form parsers becomes the 413.
+func MaxBodyBytes(limit int64) httpInternal.MiddlewareFunc {
+	return func(c httpInternal.Context) error {
+		req := c.Request()
+		if limit <= 0 || req.Body == nil || req.Body == http.NoBody {
+			return c.Next()
+		}
+
+		if req.ContentLength > limit {
+			return bodyTooLarge(limit, nil)
+		}
+		req.Body = http.MaxBytesReader(c.ResponseWriter(), req.Body, limit)
+
+		err := c.Next()
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return bodyTooLarge(limit, err)
+		}
+		return err
+	}
+}
+
+// bodyTooLarge builds the 413 error for a body over limit
+func bodyTooLarge(limit int64, err error) error {
+	return httpInternal.NewStatusError(http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("Request body exceeds %d bytes", limit), errors.Join(ErrBodyTooLarge, err))
+}
@@ -0,0 +1,145 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	contextImpl "github.com/onyx-go/framework/internal/http/context"
+)
+
+// run sends req through middleware and handler, returning the chain's error
+func run(req *http.Request, middleware httpInternal.MiddlewareFunc, handler httpInternal.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	w := httptest.NewRecorder()
+	c := contextImpl.NewContext(w, req, nil)
+	c.AddMiddleware(middleware, httpInternal.MiddlewareFunc(handler))
+	return w, c.Next()
+}
+
+// statusOf returns the status carried by err, or 0
+func statusOf(err error) int {
+	var statusErr *httpInternal.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+	return 0
+}
+
+func TestTimeout(t *testing.T) {
+	timeout := Timeout(20 * time.Millisecond)
+
+	// A handler finishing in time is untouched
+	w, err := run(httptest.NewRequest("GET", "/", nil), timeout, func(c httpInternal.Context) error {
+		if _, ok := c.Request().Context().Deadline(); !ok {
+			t.Error("expected the request context to carry a deadline")
+		}
+		c.SetHeader("X-Handler", "yes")
+		return c.String(200, "ok")
+	})
+	if err != nil || w.Code != 200 || w.Body.String() != "ok" || w.Header().Get("X-Handler") != "yes" {
+		t.Errorf("expected the handler response, got %d %q %v", w.Code, w.Body.String(), err)
+	}
+
+	// A handler waiting on its context gets a 503
+	w, err = run(httptest.NewRequest("GET", "/", nil), timeout, func(c httpInternal.Context) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	})
+	if statusOf(err) != http.StatusServiceUnavailable || !errors.Is(err, ErrRequestTimeout) {
+		t.Errorf("expected a 503 wrapping ErrRequestTimeout, got %v", err)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", w.Body.String())
+	}
+
+	// A handler ignoring its context has its late response discarded
+	w, err = run(httptest.NewRequest("GET", "/", nil), timeout, func(c httpInternal.Context) error {
+		time.Sleep(40 * time.Millisecond)
+		c.SetHeader("Content-Length", "4")
+		if err := c.String(200, "late"); !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("expected writes after the deadline to fail, got %v", err)
+		}
+		return nil
+	})
+	if statusOf(err) != http.StatusServiceUnavailable {
+		t.Errorf("expected a 503 for a late response, got %v", err)
+	}
+	if w.Body.Len() != 0 || w.Header().Get("Content-Length") != "" {
+		t.Errorf("expected the late response and its headers to be discarded, got %q %v", w.Body.String(), w.Header())
+	}
+
+	// A call with a shorter deadline failing inside the handler gets a 504
+	_, err = run(httptest.NewRequest("GET", "/", nil), Timeout(time.Second), func(c httpInternal.Context) error {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+		return errors.Join(errors.New("query failed"), ctx.Err())
+	})
+	if statusOf(err) != http.StatusGatewayTimeout || !errors.Is(err, ErrUpstreamTimeout) {
+		t.Errorf("expected a 504 wrapping ErrUpstreamTimeout, got %v", err)
+	}
+
+	// A response that already started is not replaced
+	w, err = run(httptest.NewRequest("GET", "/", nil), timeout, func(c httpInternal.Context) error {
+		c.Status(200)
+		<-c.Request().Context().Done()
+		return nil
+	})
+	if err != nil || w.Code != 200 {
+		t.Errorf("expected the started response to stand, got %d %v", w.Code, err)
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	limit := MaxBodyBytes(8)
+
+	// A declared length over the limit is refused before the handler runs
+	called := false
+	_, err := run(httptest.NewRequest("POST", "/", strings.NewReader("0123456789")), limit, func(c httpInternal.Context) error {
+		called = true
+		return nil
+	})
+	if called || statusOf(err) != http.StatusRequestEntityTooLarge || !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("expected a 413 before the handler, got called=%v %v", called, err)
+	}
+
+	// A body without a declared length is cut off while reading
+	req := httptest.NewRequest("POST", "/", io.MultiReader(strings.NewReader("01234"), strings.NewReader("56789")))
+	req.ContentLength = -1
+	_, err = run(req, limit, func(c httpInternal.Context) error {
+		_, err := c.Body()
+		return err
+	})
+	if statusOf(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected a 413 from reading the body, got %v", err)
+	}
+
+	// Bind errors caused by the limit become 413 as well
+	req = httptest.NewRequest("POST", "/", io.MultiReader(strings.NewReader(`{"name":`), strings.NewReader(`"a long name"}`)))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	_, err = run(req, limit, func(c httpInternal.Context) error {
+		var payload struct{ Name string }
+		return c.Bind(&payload)
+	})
+	if statusOf(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected a 413 from Bind, got %v", err)
+	}
+
+	w, err := run(httptest.NewRequest("POST", "/", strings.NewReader("small")), limit, func(c httpInternal.Context) error {
+		body, err := c.Body()
+		if err != nil {
+			return err
+		}
+		return c.String(200, string(body))
+	})
+	if err != nil || w.Body.String() != "small" {
+		t.Errorf("expected a body within the limit to be read, got %q %v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,341 @@
+// Package maintenance puts an application into maintenance mode. The state
+// lives in a file, so every process serving the application sees it as soon
+// as a deploy script or "onyx down" writes it.
+package maintenance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+// DefaultPath is where the state is kept, relative to the working directory
+const DefaultPath = "storage/framework/down"
+
+// CookieName is the cookie that lets a browser through after visiting the
+// secret URL
+const CookieName = "onyx_maintenance"
+
+// bypassLifetime is how long a bypass cookie stays valid
+const bypassLifetime = 12 * time.Hour
+
+// State describes a maintenance window
+type State struct {
+	// Time is when the application went down
+	Time time.Time `json:"time"`
+
+	// Retry is sent as Retry-After, in seconds, when positive
+	Retry int `json:"retry,omitempty"`
+
+	// Secret, when set, makes "/<secret>" set a cookie that bypasses
+	// maintenance mode for the browser visiting it
+	Secret string `json:"secret,omitempty"`
+
+	// Allowed are IP addresses and CIDR ranges that bypass maintenance mode
+	Allowed []string `json:"allowed,omitempty"`
+
+	// Except are paths served as usual; a trailing "*" matches a prefix
+	Except []string `json:"except,omitempty"`
+
+	// Template names the view rendered for the 503 page instead of the
+	// built-in one
+	Template string `json:"template,omitempty"`
+
+	// Message is shown on the 503 page
+	Message string `json:"message,omitempty"`
+}
+
+// Down writes state to path, creating its directory. The file is replaced
+// atomically so readers never see it half written.
+func Down(path string, state State) error {
+	if state.Time.IsZero() {
+		state.Time = time.Now().UTC()
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".down-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Up removes the state at path. It is not an error if the application is
+// not down.
+func Up(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Read returns the state at path, or nil when the application is not down
+func Read(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid maintenance state in %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Config configures the middleware
+type Config struct {
+	// Path is the state file; DefaultPath when empty
+	Path string
+
+	// Except are paths always served, in addition to those in the state,
+	// such as health checks. A trailing "*" matches a prefix.
+	Except []string
+
+	// ExceptFunc, when set, reports further paths always served. It is asked
+	// on every request, for paths only known once routes are registered.
+	ExceptFunc func(path string) bool
+}
+
+// Middleware answers requests with 503 while the state file exists. Requests
+// from allowed addresses, to excepted paths or carrying a valid bypass
+// cookie are passed on. The file is only re-read when it changes.
+func Middleware(config Config) httpInternal.MiddlewareFunc {
+	if config.Path == "" {
+		config.Path = DefaultPath
+	}
+	watcher := &watcher{path: config.Path}
+
+	return func(c httpInternal.Context) error {
+		state := watcher.state()
+		if state == nil || matchPath(c.Path(), config.Except) || matchPath(c.Path(), state.Except) ||
+			(config.ExceptFunc != nil && config.ExceptFunc(c.Path())) {
+			return c.Next()
+		}
+
+		if state.Secret != "" {
+			if c.Path() == "/"+state.Secret {
+				return grantBypass(c, state)
+			}
+			if cookie, err := c.Cookie(CookieName); err == nil && validBypass(cookie.Value, state.Secret, time.Now()) {
+				return c.Next()
+			}
+		}
+		if allowedIP(c.RemoteIP(), state.Allowed) {
+			return c.Next()
+		}
+
+		return respond(c, state)
+	}
+}
+
+// watcher caches the parsed state until the file's modification time or
+// size changes
+type watcher struct {
+	path    string
+	modTime time.Time
+	size    int64
+	cached  *State
+	mutex   sync.Mutex
+}
+
+func (w *watcher) state() *State {
+	info, err := os.Stat(w.path)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err != nil {
+		w.cached, w.modTime, w.size = nil, time.Time{}, 0
+		return nil
+	}
+	if w.cached != nil && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return w.cached
+	}
+
+	state, err := Read(w.path)
+	if err != nil {
+		// A state file that cannot be parsed still means down
+		state = &State{}
+	}
+	w.cached, w.modTime, w.size = state, info.ModTime(), info.Size()
+	return state
+}
+
+// grantBypass sets the bypass cookie and sends the browser to the home page
+func grantBypass(c httpInternal.Context, state *State) error {
+	c.Abort()
+	expires := time.Now().Add(bypassLifetime)
+	c.SetCookie(&http.Cookie{
+		Name:     CookieName,
+		Value:    bypassToken(state.Secret, expires),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.SetHeader("Location", "/")
+	c.Status(http.StatusFound)
+	return nil
+}
+
+// bypassToken signs an expiry time with the secret. Changing the secret
+// invalidates every cookie issued for the old one.
+func bypassToken(secret string, expires time.Time) string {
+	unix := strconv.FormatInt(expires.Unix(), 10)
+	return unix + "." + sign(secret, unix)
+}
+
+func validBypass(token, secret string, now time.Time) bool {
+	unix, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(sign(secret, unix)))
+}
+
+func sign(secret, value string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// matchPath reports whether path is one of patterns
+func matchPath(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedIP reports whether ip is one of the addresses or ranges in allowed
+func allowedIP(ip string, allowed []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if allowedAddr := net.ParseIP(entry); allowedAddr != nil && allowedAddr.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// applicationContext is implemented by contexts that can reach the
+// application's template engine
+type applicationContext interface {
+	Application() httpInternal.Application
+}
+
+// respond sends the 503, as JSON to API clients and as a page otherwise, in
+// place of the rest of the chain
+func respond(c httpInternal.Context, state *State) error {
+	c.Abort()
+	if state.Retry > 0 {
+		c.SetHeader("Retry-After", strconv.Itoa(state.Retry))
+	}
+	c.SetHeader("Cache-Control", "no-store")
+
+	message := state.Message
+	if message == "" {
+		message = "We are down for maintenance and will be back shortly."
+	}
+
+	if strings.Contains(c.Header("Accept"), "application/json") {
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"error":   "Service Unavailable",
+			"message": message,
+		})
+	}
+
+	data := map[string]interface{}{
+		"Message": message,
+		"Retry":   state.Retry,
+		"Since":   state.Time,
+	}
+	if state.Template != "" {
+		if ac, ok := c.(applicationContext); ok && ac.Application() != nil {
+			if engine := ac.Application().TemplateEngine(); engine != nil {
+				if html, err := engine.Render(state.Template, data); err == nil {
+					return c.HTML(http.StatusServiceUnavailable, html)
+				}
+			}
+		}
+	}
+
+	var page strings.Builder
+	if err := defaultPage.Execute(&page, data); err != nil {
+		return c.String(http.StatusServiceUnavailable, message)
+	}
+	return c.HTML(http.StatusServiceUnavailable, page.String())
+}
+
+var defaultPage = template.Must(template.New("maintenance").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Down for maintenance</title>
+<style>
+body { font-family: system-ui, sans-serif; color: #333; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { max-width: 32rem; padding: 2rem; text-align: center; }
+h1 { font-size: 1.5rem; }
+</style>
+</head>
+<body>
+<main>
+<h1>Down for maintenance</h1>
+<p>{{.Message}}</p>
+{{if .Retry}}<p>Please try again in {{.Retry}} seconds.</p>{{end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,142 @@
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	contextImpl "github.com/onyx-go/framework/internal/http/context"
+)
+
+func TestMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "framework", "down")
+	middleware := Middleware(Config{
+		Path:       path,
+		Except:     []string{"/health/*"},
+		ExceptFunc: func(path string) bool { return strings.HasPrefix(path, "/status/") },
+	})
+
+	run := func(target string, prepare func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		if prepare != nil {
+			prepare(req)
+		}
+		w := httptest.NewRecorder()
+		c := contextImpl.NewContext(w, req, nil)
+		handled := false
+		c.AddMiddleware(middleware, func(c httpInternal.Context) error {
+			handled = true
+			return c.String(200, "ok")
+		})
+		if err := c.Next(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if handled != (w.Code == 200) {
+			t.Fatalf("Expected the handler to run only for 200 responses, got %d", w.Code)
+		}
+		return w
+	}
+
+	if w := run("/", nil); w.Code != 200 {
+		t.Fatalf("Expected requests to pass while up, got %d", w.Code)
+	}
+
+	err := Down(path, State{
+		Retry:   60,
+		Secret:  "letmein",
+		Allowed: []string{"10.0.0.0/8", "2001:db8::1"},
+		Except:  []string{"/webhooks/stripe"},
+		Message: "Upgrading the <database>",
+	})
+	if err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+
+	w := run("/orders", nil)
+	if w.Code != 503 || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("Expected 503 with Retry-After while down, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if body := w.Body.String(); !strings.Contains(body, "Upgrading the &lt;database&gt;") || !strings.Contains(body, "60 seconds") {
+		t.Errorf("Expected the escaped message on the maintenance page, got %s", body)
+	}
+
+	if w := run("/orders", func(req *http.Request) { req.Header.Set("Accept", "application/json") }); w.Code != 503 || !strings.Contains(w.Body.String(), `"error":"Service Unavailable"`) {
+		t.Errorf("Expected a JSON 503 for API clients, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Exempt paths and allowed addresses pass
+	for _, target := range []string{"/health/ready", "/status/live", "/webhooks/stripe"} {
+		if w := run(target, nil); w.Code != 200 {
+			t.Errorf("Expected %s to be exempt, got %d", target, w.Code)
+		}
+	}
+	for _, addr := range []string{"10.1.2.3:4000", "[2001:db8::1]:4000"} {
+		if w := run("/orders", func(req *http.Request) { req.RemoteAddr = addr }); w.Code != 200 {
+			t.Errorf("Expected %s to be allowed, got %d", addr, w.Code)
+		}
+	}
+
+	// The secret URL hands out a bypass cookie
+	w = run("/letmein", nil)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/" {
+		t.Fatalf("Expected a redirect from the secret URL, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CookieName || !cookies[0].HttpOnly {
+		t.Fatalf("Expected an HttpOnly bypass cookie, got %+v", cookies)
+	}
+	if w := run("/orders", func(req *http.Request) { req.AddCookie(cookies[0]) }); w.Code != 200 {
+		t.Errorf("Expected the bypass cookie to let the request through, got %d", w.Code)
+	}
+	if w := run("/orders", func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: CookieName, Value: bypassToken("guessed", time.Now().Add(time.Hour))})
+	}); w.Code != 503 {
+		t.Errorf("Expected a cookie signed with another secret to be rejected, got %d", w.Code)
+	}
+
+	// A new secret invalidates cookies issued for the old one
+	time.Sleep(10 * time.Millisecond)
+	if err := Down(path, State{Secret: "rotated"}); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if w := run("/orders", func(req *http.Request) { req.AddCookie(cookies[0]) }); w.Code != 503 {
+		t.Errorf("Expected the old bypass cookie to be rejected, got %d", w.Code)
+	}
+
+	if err := Up(path); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if w := run("/orders", nil); w.Code != 200 {
+		t.Errorf("Expected requests to pass after Up, got %d", w.Code)
+	}
+	if err := Up(path); err != nil {
+		t.Errorf("Expected Up to succeed when already up, got %v", err)
+	}
+}
+
+func TestValidBypass(t *testing.T) {
+	now := time.Now()
+	token := bypassToken("secret", now.Add(time.Hour))
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		valid bool
+	}{
+		{"valid", token, now, true},
+		{"expired", token, now.Add(2 * time.Hour), false},
+		{"tampered expiry", strings.Replace(token, token[:3], "999", 1), now, false},
+		{"malformed", "not-a-token", now, false},
+	}
+
+	for _, tt := range tests {
+		if got := validBypass(tt.token, "secret", tt.now); got != tt.valid {
+			t.Errorf("%s: validBypass = %v, want %v", tt.name, got, tt.valid)
+		}
+	}
+}
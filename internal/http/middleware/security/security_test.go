@@ -196,6 +196,18 @@ func (m *mockContext) RemoteIP() string {
 	return "192.168.1.1"
 }
 
+func (m *mockContext) Scheme() string {
+	return "http"
+}
+
+func (m *mockContext) Host() string {
+	return m.request.Host
+}
+
+func (m *mockContext) RequestID() string {
+	return ""
+}
+
 func (m *mockContext) UserAgent() string {
 	return m.request.UserAgent()
 }
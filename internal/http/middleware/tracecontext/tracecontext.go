@@ -0,0 +1,50 @@
+// Package tracecontext provides middleware that gives every request an ID
+// and a W3C trace context, so log lines, queued jobs, mail and outgoing
+// calls made while handling it can be linked back to it.
+package tracecontext
+
+import (
+	"net/http"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	"github.com/onyx-go/framework/internal/tracing"
+)
+
+// requestSetter is implemented by contexts whose request can be replaced for
+// the rest of the chain
+type requestSetter interface {
+	SetRequest(req *http.Request)
+}
+
+// Middleware accepts the X-Request-ID, traceparent and tracestate headers of
+// an incoming request, generating whatever is missing or malformed, and
+// stores them on c.Request().Context() for the rest of the chain. The
+// request runs in a child span of the caller's, or in a new trace, unless a
+// span was already started for it. The request ID is echoed in the
+// X-Request-ID response header and stored under "request_id" with c.Set.
+func Middleware() httpInternal.MiddlewareFunc {
+	return func(c httpInternal.Context) error {
+		setter, ok := c.(requestSetter)
+		if !ok {
+			return c.Next()
+		}
+
+		req := c.Request()
+		requestID, remote := tracing.Extract(req.Header)
+		if requestID == "" {
+			requestID = tracing.NewRequestID()
+		}
+
+		// The router has already started the request span when tracing is on
+		ctx := tracing.WithRequestID(req.Context(), requestID)
+		if _, ok := tracing.SpanContextFrom(ctx); !ok {
+			ctx = tracing.WithSpanContext(ctx, remote.Child())
+		}
+		setter.SetRequest(req.WithContext(ctx))
+
+		c.Set(tracing.RequestIDKey, requestID)
+		c.SetHeader(tracing.RequestIDHeader, requestID)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package tracecontext
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	contextImpl "github.com/onyx-go/framework/internal/http/context"
+	"github.com/onyx-go/framework/internal/tracing"
+)
+
+func TestMiddleware(t *testing.T) {
+	run := func(headers map[string]string) (*httptest.ResponseRecorder, *contextImpl.Context) {
+		req := httptest.NewRequest("GET", "/", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		c := contextImpl.NewContext(w, req, nil)
+		c.AddMiddleware(Middleware(), func(c httpInternal.Context) error {
+			return c.String(200, "ok")
+		})
+		if err := c.Next(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return w, c
+	}
+
+	// Incoming IDs are kept and the request joins the caller's trace
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	w, c := run(map[string]string{"X-Request-ID": "req-123", "traceparent": traceparent})
+
+	if c.RequestID() != "req-123" || w.Header().Get("X-Request-ID") != "req-123" {
+		t.Errorf("Expected the incoming request ID, got %q and header %q", c.RequestID(), w.Header().Get("X-Request-ID"))
+	}
+	if value, _ := c.Get("request_id"); value != "req-123" {
+		t.Errorf("Expected request_id to be stored on the context, got %v", value)
+	}
+	sc := c.SpanContext()
+	if sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.ParentID != "00f067aa0ba902b7" {
+		t.Errorf("Expected a child span of the caller, got %+v", sc)
+	}
+
+	// Missing or malformed values are generated
+	w, c = run(map[string]string{"X-Request-ID": "bad id", "traceparent": "garbage"})
+
+	if c.RequestID() == "" || c.RequestID() == "bad id" || w.Header().Get("X-Request-ID") != c.RequestID() {
+		t.Errorf("Expected a generated request ID, got %q", c.RequestID())
+	}
+	if sc := c.SpanContext(); !sc.IsValid() || sc.ParentID != "" {
+		t.Errorf("Expected a new trace, got %+v", sc)
+	}
+	if tracing.RequestID(c.Request().Context()) != c.RequestID() {
+		t.Error("Expected the request ID on the request context")
+	}
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies lists the networks whose forwarding headers are believed.
+// Forwarded, X-Forwarded-For, X-Forwarded-Proto, X-Forwarded-Host and
+// X-Real-IP are ignored unless the connecting peer is in the list, so a
+// client talking to the server directly cannot choose its own address.
+type TrustedProxies struct {
+	networks []*net.IPNet
+}
+
+// ClientInfo is the client address, scheme and host of a request after
+// forwarding headers from trusted proxies have been applied
+type ClientInfo struct {
+	IP     string
+	Scheme string
+	Host   string
+}
+
+// proxyAliases name common groups of networks usable in a trusted proxy list
+var proxyAliases = map[string][]string{
+	"loopback": {"127.0.0.0/8", "::1/128"},
+	"private":  {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+}
+
+// NewTrustedProxies parses a list of CIDR ranges or single IP addresses.
+// The aliases "loopback" and "private" expand to the matching ranges.
+func NewTrustedProxies(proxies ...string) (*TrustedProxies, error) {
+	tp := &TrustedProxies{}
+
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		ranges, isAlias := proxyAliases[strings.ToLower(proxy)]
+		if !isAlias {
+			ranges = []string{proxy}
+		}
+
+		for _, value := range ranges {
+			if !strings.Contains(value, "/") {
+				ip := net.ParseIP(value)
+				if ip == nil {
+					return nil, fmt.Errorf("invalid trusted proxy %q", value)
+				}
+				bits := 128
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				tp.networks = append(tp.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			tp.networks = append(tp.networks, network)
+		}
+	}
+
+	return tp, nil
+}
+
+// Trusts reports whether ip belongs to a trusted proxy
+func (tp *TrustedProxies) Trusts(ip string) bool {
+	if tp == nil {
+		return false
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range tp.networks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve determines the client of r. The forwarding chain is walked from
+// the nearest hop outwards and the first address that is not a trusted proxy
+// is the client. Scheme and host come from the same hop as the address.
+func (tp *TrustedProxies) Resolve(r *http.Request) ClientInfo {
+	info := ClientInfo{IP: stripPort(r.RemoteAddr), Scheme: "http", Host: r.Host}
+	if r.TLS != nil {
+		info.Scheme = "https"
+	}
+
+	if !tp.Trusts(info.IP) {
+		return info
+	}
+
+	hops := forwardedHops(r.Header)
+	if len(hops) == 0 {
+		if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+			info.IP = realIP
+		}
+		return info
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hops[i]
+		if net.ParseIP(hop.ip) == nil {
+			// An unknown or obfuscated node ends the chain at the last proxy
+			break
+		}
+
+		info.IP = hop.ip
+		if hop.scheme != "" {
+			info.Scheme = hop.scheme
+		}
+		if hop.host != "" {
+			info.Host = hop.host
+		}
+
+		if !tp.Trusts(hop.ip) {
+			break
+		}
+	}
+
+	return info
+}
+
+// forwardedHop is one entry of the forwarding chain
+type forwardedHop struct {
+	ip     string
+	scheme string
+	host   string
+}
+
+// forwardedHops reads the chain from the RFC 7239 Forwarded header, or from
+// the X-Forwarded-* headers when Forwarded is absent. X-Forwarded-Proto and
+// X-Forwarded-Host apply to the nearest hop unless they list one value per hop.
+func forwardedHops(header http.Header) []forwardedHop {
+	if values := header.Values("Forwarded"); len(values) > 0 {
+		return parseForwarded(strings.Join(values, ","))
+	}
+
+	addresses := splitHeaderList(header.Values("X-Forwarded-For"))
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	hops := make([]forwardedHop, len(addresses))
+	for i, address := range addresses {
+		hops[i].ip = stripPort(address)
+	}
+
+	schemes := splitHeaderList(header.Values("X-Forwarded-Proto"))
+	hosts := splitHeaderList(header.Values("X-Forwarded-Host"))
+	last := len(hops) - 1
+
+	switch len(schemes) {
+	case 0:
+	case len(hops):
+		for i := range hops {
+			hops[i].scheme = strings.ToLower(schemes[i])
+		}
+	default:
+		hops[last].scheme = strings.ToLower(schemes[len(schemes)-1])
+	}
+
+	switch len(hosts) {
+	case 0:
+	case len(hops):
+		for i := range hops {
+			hops[i].host = hosts[i]
+		}
+	default:
+		hops[last].host = hosts[len(hosts)-1]
+	}
+
+	return hops
+}
+
+// parseForwarded parses the elements of a Forwarded header value, e.g.
+// `for=192.0.2.60;proto=https;host=example.com, for="[2001:db8::1]:4711"`
+func parseForwarded(value string) []forwardedHop {
+	var hops []forwardedHop
+
+	for _, element := range splitQuoted(value, ',') {
+		var hop forwardedHop
+		for _, pair := range splitQuoted(element, ';') {
+			key, val, found := strings.Cut(pair, "=")
+			if !found {
+				continue
+			}
+			val = strings.Trim(strings.TrimSpace(val), `"`)
+
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "for":
+				hop.ip = stripPort(val)
+			case "proto":
+				hop.scheme = strings.ToLower(val)
+			case "host":
+				hop.host = val
+			}
+		}
+		hops = append(hops, hop)
+	}
+
+	return hops
+}
+
+// splitQuoted splits s on sep outside double quotes
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	inQuotes, start := false, 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case sep:
+			if !inQuotes {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// splitHeaderList flattens comma separated header values
+func splitHeaderList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// stripPort removes an optional port and IPv6 brackets from an address
+func stripPort(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewTrustedProxies(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8", "203.0.113.7", "loopback", "2001:db8::/32")
+	if err != nil {
+		t.Fatalf("NewTrustedProxies failed: %v", err)
+	}
+
+	for ip, trusted := range map[string]bool{
+		"10.1.2.3":    true,
+		"203.0.113.7": true,
+		"203.0.113.8": false,
+		"127.0.0.1":   true,
+		"::1":         true,
+		"2001:db8::5": true,
+		"192.168.1.1": false,
+		"not-an-ip":   false,
+	} {
+		if proxies.Trusts(ip) != trusted {
+			t.Errorf("Trusts(%s): expected %v", ip, trusted)
+		}
+	}
+
+	for _, invalid := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := NewTrustedProxies(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+
+	var none *TrustedProxies
+	if none.Trusts("127.0.0.1") {
+		t.Error("expected a nil list to trust nothing")
+	}
+}
+
+func TestTrustedProxiesResolve(t *testing.T) {
+	proxies, _ := NewTrustedProxies("10.0.0.0/8")
+
+	tests := []struct {
+		name    string
+		remote  string
+		tls     bool
+		headers map[string]string
+		want    ClientInfo
+	}{
+		{
+			name:    "untrusted peer ignores headers",
+			remote:  "198.51.100.9:5000",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.test", "X-Real-IP": "1.2.3.4"},
+			want:    ClientInfo{IP: "198.51.100.9", Scheme: "http", Host: "example.com"},
+		},
+		{
+			name:   "direct TLS connection",
+			remote: "198.51.100.9:5000",
+			tls:    true,
+			want:   ClientInfo{IP: "198.51.100.9", Scheme: "https", Host: "example.com"},
+		},
+		{
+			name:    "trusted proxy with X-Forwarded headers",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.5", "X-Forwarded-Proto": "https", "X-Forwarded-Host": "shop.test"},
+			want:    ClientInfo{IP: "203.0.113.5", Scheme: "https", Host: "shop.test"},
+		},
+		{
+			name:    "spoofed entries left of the client are skipped",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"X-Forwarded-For": "6.6.6.6, 203.0.113.5, 10.0.0.9"},
+			want:    ClientInfo{IP: "203.0.113.5", Scheme: "http", Host: "example.com"},
+		},
+		{
+			name:    "chain of trusted proxies",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"X-Forwarded-For": "10.0.0.7, 10.0.0.9"},
+			want:    ClientInfo{IP: "10.0.0.7", Scheme: "http", Host: "example.com"},
+		},
+		{
+			name:    "X-Real-IP from a trusted proxy",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"X-Real-IP": "203.0.113.5"},
+			want:    ClientInfo{IP: "203.0.113.5", Scheme: "http", Host: "example.com"},
+		},
+		{
+			name:    "Forwarded header",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"Forwarded": `for=6.6.6.6;proto=http, for="[2001:db8::1]:4711";proto=https;host="shop.test", for=10.0.0.9`},
+			want:    ClientInfo{IP: "2001:db8::1", Scheme: "https", Host: "shop.test"},
+		},
+		{
+			name:    "Forwarded takes precedence over X-Forwarded-For",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"Forwarded": "for=203.0.113.5", "X-Forwarded-For": "198.51.100.1"},
+			want:    ClientInfo{IP: "203.0.113.5", Scheme: "http", Host: "example.com"},
+		},
+		{
+			name:    "obfuscated node stops at the last proxy",
+			remote:  "10.0.0.2:4000",
+			headers: map[string]string{"Forwarded": "for=_hidden, for=10.0.0.9"},
+			want:    ClientInfo{IP: "10.0.0.9", Scheme: "http", Host: "example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "http://example.com/", nil)
+		req.RemoteAddr = tt.remote
+		if tt.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		for key, value := range tt.headers {
+			req.Header.Set(key, value)
+		}
+
+		if got := proxies.Resolve(req); got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package router
+
+import (
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+// BinderFunc resolves the raw value of a route parameter into a richer value,
+// typically a model loaded from the database. withTrashed reports whether the
+// route opted in to resolving soft-deleted models. Returning an error aborts
+// the request and hands the error to the application's ErrorHandler.
+type BinderFunc func(c httpInternal.Context, value string, withTrashed bool) (interface{}, error)
+
+// Bind registers a binder for every route parameter with the given name. The
+// resolved value is stored on the context under the parameter name before the
+// route handler runs.
+func (r *Router) Bind(param string, binder BinderFunc) {
+	r.binders[param] = binder
+}
+
+// WithTrashed lets route model binding resolve soft-deleted models for this route
+func (d *RouteDefinition) WithTrashed() httpInternal.RouteDefinition {
+	for _, rt := range d.routes {
+		rt.withTrashed = true
+	}
+	return d
+}
+
+// resolveBindings runs the registered binders for the route's parameters
+func (r *Router) resolveBindings(c httpInternal.Context, rt *route) error {
+	if len(r.binders) == 0 {
+		return nil
+	}
+
+	names := rt.paramNames
+	if rt.domain != nil {
+		names = append(rt.domain.paramNames(), names...)
+	}
+
+	for _, name := range names {
+		binder, exists := r.binders[name]
+		if !exists {
+			continue
+		}
+
+		value, err := binder(c, c.Param(name), rt.withTrashed)
+		if err != nil {
+			return err
+		}
+
+		c.Set(name, value)
+	}
+
+	return nil
+}
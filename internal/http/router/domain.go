@@ -0,0 +1,224 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+// domain is a host pattern such as "api.example.com" or
+// "{tenant}.example.com" together with the routes registered under it. Each
+// "."-separated label is matched like a path segment, so "{name}" and
+// "{name:constraint}" capture labels into route parameters.
+type domain struct {
+	pattern string
+	labels  []patternSegment
+	tree    *node
+}
+
+// newDomain parses a host pattern. Static labels match case-insensitively.
+func newDomain(pattern string) *domain {
+	pattern = strings.TrimSuffix(strings.TrimSpace(pattern), ".")
+
+	labels := parsePattern("/" + strings.ReplaceAll(pattern, ".", "/"))
+	for i := range labels {
+		if labels[i].kind == staticSegment {
+			labels[i].value = strings.ToLower(labels[i].value)
+		}
+	}
+
+	return &domain{pattern: pattern, labels: labels, tree: newNode()}
+}
+
+// isStatic reports whether the pattern has no parameters
+func (d *domain) isStatic() bool {
+	for _, label := range d.labels {
+		if label.kind != staticSegment {
+			return false
+		}
+	}
+	return true
+}
+
+// paramNames returns the names of the parameters captured from the host
+func (d *domain) paramNames() []string {
+	var names []string
+	for _, label := range d.labels {
+		if label.kind != staticSegment {
+			names = append(names, label.value)
+		}
+	}
+	return names
+}
+
+// match reports whether host, without port, matches the pattern and returns
+// the captured parameters
+func (d *domain) match(host string) (map[string]string, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) != len(d.labels) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, label := range d.labels {
+		if label.kind == staticSegment {
+			if parts[i] != label.value {
+				return nil, false
+			}
+			continue
+		}
+
+		matcher := constraintMatcher(label.constraint)
+		if matcher == nil {
+			matcher = isNonEmpty
+		}
+		if !matcher(parts[i]) {
+			return nil, false
+		}
+
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[label.value] = parts[i]
+	}
+
+	return params, true
+}
+
+// host builds the host name for the given parameters
+func (d *domain) host(routeName string, params map[string]string) (string, error) {
+	labels := make([]string, len(d.labels))
+	for i, label := range d.labels {
+		if label.kind == staticSegment {
+			labels[i] = label.value
+			continue
+		}
+
+		value := params[label.value]
+		if value == "" {
+			return "", fmt.Errorf("%w: %q is required by the domain of route %q (%s)",
+				ErrMissingRouteParameter, label.value, routeName, d.pattern)
+		}
+		if matcher := constraintMatcher(label.constraint); matcher != nil && !matcher(value) {
+			return "", fmt.Errorf("%w: %q=%q does not satisfy the %s constraint of the domain of route %q",
+				ErrInvalidRouteParameter, label.value, value, label.constraint, routeName)
+		}
+		labels[i] = strings.ToLower(value)
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+// Domain creates a route group whose routes only match requests for hosts
+// matching pattern, e.g. Domain("{tenant}.example.com"). Host parameters are
+// available through c.Param like path parameters. Routes outside any domain
+// group keep matching every host.
+func (r *Router) Domain(pattern string, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteGroup {
+	return &RouteGroup{
+		router:     r,
+		domain:     r.domainFor(pattern),
+		middleware: middleware,
+	}
+}
+
+// domainFor returns the domain registered for pattern, creating it if needed.
+// Static domains are kept ahead of parameterised ones so that
+// "admin.example.com" wins over "{tenant}.example.com".
+func (r *Router) domainFor(pattern string) *domain {
+	d := newDomain(pattern)
+	for _, existing := range r.domains {
+		if existing.pattern == d.pattern {
+			return existing
+		}
+	}
+
+	pos := len(r.domains)
+	if d.isStatic() {
+		for i, existing := range r.domains {
+			if !existing.isStatic() {
+				pos = i
+				break
+			}
+		}
+	}
+	r.domains = append(r.domains, nil)
+	copy(r.domains[pos+1:], r.domains[pos:])
+	r.domains[pos] = d
+
+	return d
+}
+
+// domainPattern returns the route's host pattern, or "" when it matches every host
+func (rt *route) domainPattern() string {
+	if rt.domain == nil {
+		return ""
+	}
+	return rt.domain.pattern
+}
+
+// hostname strips the port and any trailing dot from a Host value
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	return strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+}
+
+// requestURLs generates route URLs during a request. Domain routes become
+// absolute URLs using the request's scheme and port, and parameters of the
+// current domain are filled in when not given, so a handler under
+// "{tenant}.example.com" can link to its siblings without repeating the tenant.
+type requestURLs struct {
+	router   *Router
+	scheme   string
+	port     string
+	defaults map[string]string
+}
+
+// requestURLs returns the URL generator for a request to host that matched
+// current, which may be nil
+func (r *Router) requestURLs(current *route, host string, client httpInternal.ClientInfo) *requestURLs {
+	urls := &requestURLs{router: r, scheme: client.Scheme}
+	_, urls.port, _ = net.SplitHostPort(client.Host)
+	if current != nil && current.domain != nil {
+		urls.defaults, _ = current.domain.match(host)
+	}
+	return urls
+}
+
+// URL generates the URL for a named route
+func (u *requestURLs) URL(name string, params map[string]string, query url.Values) (string, error) {
+	rt, exists := u.router.names[name]
+	if !exists || rt.domain == nil {
+		return u.router.URL(name, params, query)
+	}
+
+	merged := make(map[string]string, len(params)+len(u.defaults))
+	for _, param := range rt.domain.paramNames() {
+		if value, ok := u.defaults[param]; ok {
+			merged[param] = value
+		}
+	}
+	for key, value := range params {
+		merged[key] = value
+	}
+
+	location, err := u.router.URL(name, merged, query)
+	if err != nil {
+		return "", err
+	}
+
+	// Router.URL returns "//host/path" for domain routes
+	host, path := location[2:], ""
+	if idx := strings.IndexAny(host, "/?"); idx >= 0 {
+		host, path = host[:idx], host[idx:]
+	}
+	if u.port != "" {
+		host = net.JoinHostPort(host, u.port)
+	}
+
+	return u.scheme + "://" + host + path, nil
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"io/fs"
 	"strings"
 
 	httpInternal "github.com/onyx-go/framework/internal/http"
@@ -10,63 +11,75 @@ import (
 type RouteGroup struct {
 	router     *Router
 	Prefix_    string // Export with underscore to avoid conflicts
+	namePrefix string
+	domain     *domain
 	middleware []httpInternal.MiddlewareFunc
 }
 
-// GET registers a GET route in the group
-func (g *RouteGroup) GET(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
+// add registers a route on the router with the group's prefix, middleware and name prefix
+func (g *RouteGroup) add(method, pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) *RouteDefinition {
 	fullPattern := g.Prefix_ + pattern
 	allMiddleware := append(g.middleware, middleware...)
-	g.router.GET(fullPattern, handler, allMiddleware...)
+	
+	var definition *RouteDefinition
+	if method == "ANY" {
+		definition = g.router.addDomainAny(g.domain, fullPattern, handler, allMiddleware...)
+	} else {
+		definition = g.router.addDomainRoute(g.domain, method, fullPattern, handler, allMiddleware...)
+	}
+	definition.namePrefix = g.namePrefix
+	return definition
+}
+
+// GET registers a GET route in the group
+func (g *RouteGroup) GET(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("GET", pattern, handler, middleware...)
 }
 
 // POST registers a POST route in the group
-func (g *RouteGroup) POST(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.POST(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) POST(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("POST", pattern, handler, middleware...)
 }
 
 // PUT registers a PUT route in the group
-func (g *RouteGroup) PUT(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.PUT(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) PUT(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("PUT", pattern, handler, middleware...)
 }
 
 // DELETE registers a DELETE route in the group
-func (g *RouteGroup) DELETE(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.DELETE(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) DELETE(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("DELETE", pattern, handler, middleware...)
 }
 
 // PATCH registers a PATCH route in the group
-func (g *RouteGroup) PATCH(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.PATCH(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) PATCH(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("PATCH", pattern, handler, middleware...)
 }
 
 // OPTIONS registers an OPTIONS route in the group
-func (g *RouteGroup) OPTIONS(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.OPTIONS(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) OPTIONS(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("OPTIONS", pattern, handler, middleware...)
 }
 
 // HEAD registers a HEAD route in the group
-func (g *RouteGroup) HEAD(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.HEAD(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) HEAD(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("HEAD", pattern, handler, middleware...)
 }
 
 // ANY registers a route for all HTTP methods in the group
-func (g *RouteGroup) ANY(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	fullPattern := g.Prefix_ + pattern
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.ANY(fullPattern, handler, allMiddleware...)
+func (g *RouteGroup) ANY(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("ANY", pattern, handler, middleware...)
+}
+
+// WS registers a WebSocket route in the group, upgrading GET requests and
+// passing the connection to handler
+func (g *RouteGroup) WS(pattern string, handler httpInternal.WebSocketHandler, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.add("GET", pattern, g.router.websocketHandler(handler), middleware...)
+}
+
+// Static serves the files of fsys below prefix within the group
+func (g *RouteGroup) Static(prefix string, fsys fs.FS, options ...httpInternal.StaticOption) httpInternal.RouteDefinition {
+	return g.add("GET", staticPattern(prefix), g.router.staticHandler(fsys, options...))
 }
 
 // Group creates a nested route group with additional prefix and middleware
@@ -77,10 +90,31 @@ func (g *RouteGroup) Group(prefix string, middleware ...httpInternal.MiddlewareF
 	return &RouteGroup{
 		router:     g.router,
 		Prefix_:    fullPrefix,
+		namePrefix: g.namePrefix,
+		domain:     g.domain,
 		middleware: allMiddleware,
 	}
 }
 
+// Domain creates a nested group whose routes only match hosts matching
+// pattern, keeping the group's prefix, name prefix and middleware
+func (g *RouteGroup) Domain(pattern string, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteGroup {
+	return &RouteGroup{
+		router:     g.router,
+		Prefix_:    g.Prefix_,
+		namePrefix: g.namePrefix,
+		domain:     g.router.domainFor(pattern),
+		middleware: append(append([]httpInternal.MiddlewareFunc(nil), g.middleware...), middleware...),
+	}
+}
+
+// Name sets the name prefix for routes in the group, composed with any
+// prefix inherited from a parent group (e.g. "admin." + "users." + "index")
+func (g *RouteGroup) Name(prefix string) httpInternal.RouteGroup {
+	g.namePrefix += prefix
+	return g
+}
+
 // Use adds middleware to the route group
 func (g *RouteGroup) Use(middleware ...httpInternal.MiddlewareFunc) {
 	g.middleware = append(g.middleware, middleware...)
@@ -96,14 +130,36 @@ func (g *RouteGroup) Middleware() []httpInternal.MiddlewareFunc {
 	return g.middleware
 }
 
-// Resource creates RESTful routes for a resource
+// Resource creates RESTful routes for a resource, named "<name>.index",
+// "<name>.show", etc. after the group's name prefix. A group without one uses
+// its path instead, so "/api/v1" gives "api.v1.users.index". Names that are
+// already taken are left off rather than reported as duplicates.
 func (g *RouteGroup) Resource(name string, controller ResourceController) {
 	// Implement RESTful routing convention
-	g.GET("/"+name, controller.Index)           // GET /resource
-	g.GET("/"+name+"/{id}", controller.Show)    // GET /resource/{id}
-	g.POST("/"+name, controller.Store)          // POST /resource
-	g.PUT("/"+name+"/{id}", controller.Update)  // PUT /resource/{id}
-	g.DELETE("/"+name+"/{id}", controller.Destroy) // DELETE /resource/{id}
+	g.nameResource(g.GET("/"+name, controller.Index), name, "index")              // GET /resource
+	g.nameResource(g.GET("/"+name+"/{id}", controller.Show), name, "show")        // GET /resource/{id}
+	g.nameResource(g.POST("/"+name, controller.Store), name, "store")             // POST /resource
+	g.nameResource(g.PUT("/"+name+"/{id}", controller.Update), name, "update")    // PUT /resource/{id}
+	g.nameResource(g.DELETE("/"+name+"/{id}", controller.Destroy), name, "destroy") // DELETE /resource/{id}
+}
+
+// nameResource names one of the routes Resource registers, unless the name is
+// already in use
+func (g *RouteGroup) nameResource(definition httpInternal.RouteDefinition, resource, action string) {
+	var name string
+	if g.namePrefix == "" {
+		// Only the static segments of the path, e.g. "/teams/{team}/api" gives "teams.api."
+		for _, segment := range parsePattern(g.Prefix_) {
+			if segment.kind == staticSegment {
+				name += segment.value + "."
+			}
+		}
+	}
+	name += resource + "." + action
+	if g.router.HasRoute(g.namePrefix + name) {
+		return
+	}
+	definition.Name(name)
 }
 
 // ResourceController interface for RESTful controllers
@@ -116,22 +172,22 @@ type ResourceController interface {
 }
 
 // Backward compatibility methods with lowercase names for API compatibility
-func (g *RouteGroup) Get(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	g.GET(pattern, handler, middleware...)
+func (g *RouteGroup) Get(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.GET(pattern, handler, middleware...)
 }
 
-func (g *RouteGroup) Post(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	g.POST(pattern, handler, middleware...)
+func (g *RouteGroup) Post(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.POST(pattern, handler, middleware...)
 }
 
-func (g *RouteGroup) Put(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	g.PUT(pattern, handler, middleware...)
+func (g *RouteGroup) Put(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.PUT(pattern, handler, middleware...)
 }
 
-func (g *RouteGroup) Delete(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	g.DELETE(pattern, handler, middleware...)
+func (g *RouteGroup) Delete(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.DELETE(pattern, handler, middleware...)
 }
 
-func (g *RouteGroup) Patch(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	g.PATCH(pattern, handler, middleware...)
+func (g *RouteGroup) Patch(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return g.PATCH(pattern, handler, middleware...)
 }
\ No newline at end of file
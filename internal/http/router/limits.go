@@ -0,0 +1,39 @@
+package router
+
+import (
+	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	"github.com/onyx-go/framework/internal/http/middleware/limits"
+)
+
+// Timeout gives the route's middleware and handler a deadline, carried by
+// c.Request().Context(). Exceeding it answers 503, and a call inside the
+// handler running out of time answers 504.
+func (d *RouteDefinition) Timeout(timeout time.Duration) httpInternal.RouteDefinition {
+	for _, rt := range d.routes {
+		rt.timeout = timeout
+	}
+	return d
+}
+
+// MaxBodyBytes rejects request bodies larger than n bytes with 413 before
+// Bind or Body read them
+func (d *RouteDefinition) MaxBodyBytes(n int64) httpInternal.RouteDefinition {
+	for _, rt := range d.routes {
+		rt.maxBodyBytes = n
+	}
+	return d
+}
+
+// limits returns the middleware enforcing the route's body limit and deadline
+func (rt *route) limits() []httpInternal.MiddlewareFunc {
+	var middleware []httpInternal.MiddlewareFunc
+	if rt.maxBodyBytes > 0 {
+		middleware = append(middleware, limits.MaxBodyBytes(rt.maxBodyBytes))
+	}
+	if rt.timeout > 0 {
+		middleware = append(middleware, limits.Timeout(rt.timeout))
+	}
+	return middleware
+}
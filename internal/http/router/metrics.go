@@ -0,0 +1,71 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/onyx-go/framework/internal/metrics"
+)
+
+// requestDuration is labelled by route pattern rather than path, so that
+// /users/1 and /users/2 share a series
+var requestDuration = metrics.Default().NewHistogram(
+	"onyx_http_request_duration_seconds",
+	"Time spent handling HTTP requests, by route pattern and status.",
+	nil, "method", "route", "status",
+)
+
+// knownMethods are recorded as sent; any other method is recorded as
+// "_OTHER" so clients cannot create series at will
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// observeRequest records a request duration. Requests that matched no route
+// are recorded with an empty route.
+func observeRequest(method string, route *route, status int, duration time.Duration) {
+	if !knownMethods[method] {
+		method = "_OTHER"
+	}
+	pattern := ""
+	if route != nil {
+		pattern = route.pattern
+	}
+	requestDuration.With(method, pattern, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// statusWriter remembers the status code sent through it
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 && code >= http.StatusOK {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Status returns the status code sent, or 200 when nothing was written
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+var (
+	// ErrRouteNotDefined is returned when generating a URL for an unknown route name
+	ErrRouteNotDefined = errors.New("route not defined")
+
+	// ErrDuplicateRouteName is reported when two routes are registered with the same name
+	ErrDuplicateRouteName = errors.New("duplicate route name")
+
+	// ErrMissingRouteParameter is returned when a required route parameter was not supplied
+	ErrMissingRouteParameter = errors.New("missing route parameter")
+
+	// ErrInvalidRouteParameter is returned when a parameter does not satisfy its constraint
+	ErrInvalidRouteParameter = errors.New("invalid route parameter")
+)
+
+// RouteDefinition is returned when a route is registered so it can be
+// configured further, for example by giving it a name
+type RouteDefinition struct {
+	router     *Router
+	routes     []*route
+	namePrefix string
+}
+
+// Name assigns a name to the route. Group name prefixes are prepended.
+// Registering a name twice is recorded and reported by Router.Err.
+func (d *RouteDefinition) Name(name string) httpInternal.RouteDefinition {
+	d.router.nameRoute(d.namePrefix+name, d.routes)
+	return d
+}
+
+// nameRoute records a route name, reporting duplicates
+func (r *Router) nameRoute(name string, routes []*route) {
+	if len(routes) == 0 {
+		return
+	}
+
+	if existing, exists := r.names[name]; exists {
+		r.errs = append(r.errs, fmt.Errorf("%w: %q is already registered for %s %s, cannot reuse it for %s %s",
+			ErrDuplicateRouteName, name, existing.method, existing.pattern, routes[0].method, routes[0].pattern))
+		return
+	}
+
+	r.names[name] = routes[0]
+	for _, rt := range routes {
+		rt.name = name
+	}
+}
+
+// Err returns the registration errors collected so far, such as duplicate route names
+func (r *Router) Err() error {
+	return errors.Join(r.errs...)
+}
+
+// HasRoute reports whether a route with the given name is registered
+func (r *Router) HasRoute(name string) bool {
+	_, exists := r.names[name]
+	return exists
+}
+
+// URL generates the path for a named route, substituting params into the
+// pattern and appending query as the query string. Routes registered under a
+// Domain group produce a scheme-relative URL including the host; during a
+// request, Context.RouteURL makes it absolute.
+func (r *Router) URL(name string, params map[string]string, query url.Values) (string, error) {
+	rt, exists := r.names[name]
+	if !exists {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotDefined, name)
+	}
+
+	var path strings.Builder
+	for _, seg := range parsePattern(rt.pattern) {
+		path.WriteString("/")
+
+		if seg.kind == staticSegment {
+			path.WriteString(seg.value)
+			continue
+		}
+
+		value, ok := params[seg.value]
+		if seg.kind == wildcardSegment {
+			segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			path.WriteString(strings.Join(segments, "/"))
+			continue
+		}
+		if !ok || value == "" {
+			return "", fmt.Errorf("%w: %q is required by route %q (%s)", ErrMissingRouteParameter, seg.value, name, rt.pattern)
+		}
+
+		if matcher := constraintMatcher(seg.constraint); matcher != nil && !matcher(value) {
+			return "", fmt.Errorf("%w: %q=%q does not satisfy the %s constraint of route %q",
+				ErrInvalidRouteParameter, seg.value, value, seg.constraint, name)
+		}
+
+		path.WriteString(url.PathEscape(value))
+	}
+
+	result := path.String()
+	if result == "" {
+		result = "/"
+	}
+
+	// Domain routes are scheme-relative, e.g. "//acme.example.com/dashboard"
+	if rt.domain != nil {
+		host, err := rt.domain.host(name, params)
+		if err != nil {
+			return "", err
+		}
+		result = "//" + host + result
+	}
+
+	if len(query) > 0 {
+		result += "?" + query.Encode()
+	}
+
+	return result, nil
+}
+
+// RouteFunc returns a template function that generates named route URLs.
+// Parameters are given either as a single map or as alternating key/value
+// pairs, e.g. {{ route "users.show" "id" .User.ID }}.
+func (r *Router) RouteFunc() func(name string, args ...interface{}) (string, error) {
+	return func(name string, args ...interface{}) (string, error) {
+		params := make(map[string]string)
+
+		if len(args) == 1 {
+			switch m := args[0].(type) {
+			case map[string]string:
+				return r.URL(name, m, nil)
+			case map[string]interface{}:
+				for key, value := range m {
+					params[key] = fmt.Sprint(value)
+				}
+				return r.URL(name, params, nil)
+			}
+		}
+
+		if len(args)%2 != 0 {
+			return "", fmt.Errorf("route %q: parameters must be key/value pairs", name)
+		}
+
+		for i := 0; i < len(args); i += 2 {
+			key, ok := args[i].(string)
+			if !ok {
+				return "", fmt.Errorf("route %q: parameter name must be a string, got %T", name, args[i])
+			}
+			params[key] = fmt.Sprint(args[i+1])
+		}
+
+		return r.URL(name, params, nil)
+	}
+}
+
+// Ensure RouteDefinition implements the RouteDefinition interface
+var _ httpInternal.RouteDefinition = (*RouteDefinition)(nil)
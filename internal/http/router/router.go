@@ -3,35 +3,58 @@ package router
 import (
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	httpInternal "github.com/onyx-go/framework/internal/http"
 	"github.com/onyx-go/framework/internal/http/context"
+	"github.com/onyx-go/framework/internal/http/websocket"
 )
 
 // route represents a single HTTP route
 type route struct {
-	method      string
-	pattern     string
-	handler     httpInternal.HandlerFunc
-	regex       *regexp.Regexp
-	paramNames  []string
-	middleware  []httpInternal.MiddlewareFunc
+	method       string
+	pattern      string
+	name         string
+	withTrashed  bool
+	domain       *domain
+	timeout      time.Duration
+	maxBodyBytes int64
+	handler      httpInternal.HandlerFunc
+	paramNames   []string
+	middleware   []httpInternal.MiddlewareFunc
 }
 
-// Router implements the HTTP router with pattern matching and middleware support
+// Router implements the HTTP router with pattern matching and middleware support.
+// Routes are kept in registration order for introspection and indexed in a
+// prefix tree for lookup.
 type Router struct {
 	routes     []*route
+	tree       *node
+	domains    []*domain
+	names      map[string]*route
+	binders    map[string]BinderFunc
+	errs       []error
 	middleware []httpInternal.MiddlewareFunc
 	notFound   httpInternal.HandlerFunc
+	notAllowed httpInternal.HandlerFunc
+	websocket  websocket.Config
 	app        httpInternal.Application
+	proxies    *httpInternal.TrustedProxies
+	
+	maxMultipartMemory int64
 }
 
 // NewRouter creates a new HTTP router
 func NewRouter() *Router {
 	return &Router{
 		routes:     make([]*route, 0),
+		tree:       newNode(),
+		names:      make(map[string]*route),
+		binders:    make(map[string]BinderFunc),
 		middleware: make([]httpInternal.MiddlewareFunc, 0),
+		websocket:  websocket.DefaultConfig(),
 		notFound: func(c httpInternal.Context) error {
 			// Create a not found error and let the error handler deal with it
 			// This allows proper JSON responses when requested
@@ -48,6 +71,19 @@ func NewRouter() *Router {
 				return c.String(404, "Not Found")
 			}
 		},
+		notAllowed: func(c httpInternal.Context) error {
+			acceptHeader := c.Request().Header.Get("Accept")
+			if strings.Contains(acceptHeader, "application/json") {
+				return c.JSON(405, map[string]interface{}{
+					"error": map[string]interface{}{
+						"status_code": 405,
+						"message":     "Method Not Allowed",
+						"type":        "error",
+					},
+				})
+			}
+			return c.String(405, "Method Not Allowed")
+		},
 	}
 }
 
@@ -62,59 +98,91 @@ func (r *Router) Use(middleware ...httpInternal.MiddlewareFunc) {
 }
 
 // addRoute adds a route with the specified method, pattern, and handler
-func (r *Router) addRoute(method, pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	route := &route{
+func (r *Router) addRoute(method, pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) *RouteDefinition {
+	return r.addDomainRoute(nil, method, pattern, handler, middleware...)
+}
+
+// addDomainRoute adds a route that only matches hosts of the given domain,
+// or every host when d is nil
+func (r *Router) addDomainRoute(d *domain, method, pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) *RouteDefinition {
+	newRoute := &route{
 		method:     strings.ToUpper(method),
 		pattern:    pattern,
+		domain:     d,
 		handler:    handler,
 		middleware: middleware,
 	}
 	
-	route.regex, route.paramNames = r.compilePattern(pattern)
-	r.routes = append(r.routes, route)
+	segments := parsePattern(pattern)
+	for _, seg := range segments {
+		if seg.kind != staticSegment {
+			newRoute.paramNames = append(newRoute.paramNames, seg.value)
+		}
+	}
+	
+	tree := r.tree
+	if d != nil {
+		tree = d.tree
+	}
+	tree.insert(segments, newRoute)
+	r.routes = append(r.routes, newRoute)
+	
+	return &RouteDefinition{router: r, routes: []*route{newRoute}}
 }
 
 // GET registers a GET route
-func (r *Router) GET(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("GET", pattern, handler, middleware...)
+func (r *Router) GET(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("GET", pattern, handler, middleware...)
 }
 
 // POST registers a POST route
-func (r *Router) POST(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("POST", pattern, handler, middleware...)
+func (r *Router) POST(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("POST", pattern, handler, middleware...)
 }
 
 // PUT registers a PUT route
-func (r *Router) PUT(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("PUT", pattern, handler, middleware...)
+func (r *Router) PUT(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("PUT", pattern, handler, middleware...)
 }
 
 // DELETE registers a DELETE route
-func (r *Router) DELETE(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("DELETE", pattern, handler, middleware...)
+func (r *Router) DELETE(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("DELETE", pattern, handler, middleware...)
 }
 
 // PATCH registers a PATCH route
-func (r *Router) PATCH(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("PATCH", pattern, handler, middleware...)
+func (r *Router) PATCH(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("PATCH", pattern, handler, middleware...)
 }
 
 // OPTIONS registers an OPTIONS route
-func (r *Router) OPTIONS(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("OPTIONS", pattern, handler, middleware...)
+func (r *Router) OPTIONS(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("OPTIONS", pattern, handler, middleware...)
 }
 
 // HEAD registers a HEAD route
-func (r *Router) HEAD(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
-	r.addRoute("HEAD", pattern, handler, middleware...)
+func (r *Router) HEAD(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("HEAD", pattern, handler, middleware...)
 }
 
 // ANY registers a route for all HTTP methods
-func (r *Router) ANY(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) {
+func (r *Router) ANY(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addAny(pattern, handler, middleware...)
+}
+
+// addAny registers the route for every method and returns a single definition covering all of them
+func (r *Router) addAny(pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) *RouteDefinition {
+	return r.addDomainAny(nil, pattern, handler, middleware...)
+}
+
+// addDomainAny registers a route for all HTTP methods under the given domain
+func (r *Router) addDomainAny(d *domain, pattern string, handler httpInternal.HandlerFunc, middleware ...httpInternal.MiddlewareFunc) *RouteDefinition {
+	definition := &RouteDefinition{router: r}
 	methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
 	for _, method := range methods {
-		r.addRoute(method, pattern, handler, middleware...)
+		definition.routes = append(definition.routes, r.addDomainRoute(d, method, pattern, handler, middleware...).routes...)
 	}
+	return definition
 }
 
 // Group creates a new route group with the specified prefix and middleware
@@ -131,7 +199,38 @@ func (r *Router) SetNotFound(handler httpInternal.HandlerFunc) {
 	r.notFound = handler
 }
 
-// compilePattern compiles a route pattern into a regex and extracts parameter names
+// SetMethodNotAllowed sets the handler for 405 Method Not Allowed responses.
+// The Allow header is already set on the response when the handler runs.
+func (r *Router) SetMethodNotAllowed(handler httpInternal.HandlerFunc) {
+	r.notAllowed = handler
+}
+
+// SetMaxMultipartMemory sets how much of a multipart form each request keeps
+// in memory before file parts spill to temporary files
+func (r *Router) SetMaxMultipartMemory(bytes int64) {
+	r.maxMultipartMemory = bytes
+}
+
+// SetTrustedProxies sets the proxies, as CIDR ranges or IP addresses, whose
+// Forwarded and X-Forwarded-* headers determine the client IP, scheme and
+// host. With no trusted proxies those headers are ignored.
+func (r *Router) SetTrustedProxies(proxies ...string) error {
+	trusted, err := httpInternal.NewTrustedProxies(proxies...)
+	if err != nil {
+		return err
+	}
+	r.proxies = trusted
+	return nil
+}
+
+// TrustedProxies returns the configured trusted proxies, or nil when none are set
+func (r *Router) TrustedProxies() *httpInternal.TrustedProxies {
+	return r.proxies
+}
+
+// compilePattern compiles a route pattern into a regex and extracts parameter names.
+// Lookup goes through the prefix tree; the regex form is kept for tooling that
+// needs a standalone matcher for a single pattern.
 func (r *Router) compilePattern(pattern string) (*regexp.Regexp, []string) {
 	var paramNames []string
 	
@@ -146,6 +245,12 @@ func (r *Router) compilePattern(pattern string) (*regexp.Regexp, []string) {
 		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
 			paramName := part[1 : len(part)-1]
 			
+			if strings.HasSuffix(paramName, "...") {
+				regexPattern += "/(.*)"
+				paramNames = append(paramNames, strings.TrimSuffix(paramName, "..."))
+				break
+			}
+			
 			if strings.Contains(paramName, ":") {
 				parts := strings.Split(paramName, ":")
 				paramName = parts[0]
@@ -177,37 +282,119 @@ func (r *Router) compilePattern(pattern string) (*regexp.Regexp, []string) {
 	return regex, paramNames
 }
 
-// match finds a matching route for the given method and path
-func (r *Router) match(method, path string) (*route, map[string]string) {
-	for _, route := range r.routes {
-		if route.method != method {
-			continue
-		}
-		
-		matches := route.regex.FindStringSubmatch(path)
-		if matches == nil {
+// match finds a matching route for the given method and path. Routes of
+// domains matching host are tried first, then the routes without a domain.
+func (r *Router) match(host, method, path string) (*route, map[string]string) {
+	for _, d := range r.domains {
+		hostParams, ok := d.match(host)
+		if !ok {
 			continue
 		}
 		
-		params := make(map[string]string)
-		for i, name := range route.paramNames {
-			if i+1 < len(matches) {
-				params[name] = matches[i+1]
+		if route, params := matchTree(d.tree, method, path); route != nil {
+			for key, value := range hostParams {
+				params[key] = value
 			}
+			return route, params
 		}
-		
-		return route, params
 	}
 	
-	return nil, nil
+	return matchTree(r.tree, method, path)
+}
+
+// matchTree finds a matching route in a single tree
+func matchTree(tree *node, method, path string) (*route, map[string]string) {
+	route, values := tree.lookup(method, path)
+	if route == nil {
+		return nil, nil
+	}
+	
+	params := make(map[string]string, len(route.paramNames))
+	for i, name := range route.paramNames {
+		if i < len(values) {
+			params[name] = values[i]
+		}
+	}
+	
+	return route, params
+}
+
+// allowedMethods returns the sorted list of methods that can be served for path.
+// HEAD is implied by GET and OPTIONS is always answered when any method matches.
+func (r *Router) allowedMethods(host, path string) []string {
+	if path == "/" {
+		path = ""
+	}
+	
+	trees := []*node{r.tree}
+	for _, d := range r.domains {
+		if _, ok := d.match(host); ok {
+			trees = append(trees, d.tree)
+		}
+	}
+	
+	seen := make(map[string]bool)
+	for _, tree := range trees {
+		tree.search(path, nil, func(candidate *node) bool {
+			for method := range candidate.routes {
+				seen[method] = true
+			}
+			// Keep searching so every matching branch contributes its methods
+			return false
+		})
+	}
+	
+	if len(seen) == 0 {
+		return nil
+	}
+	
+	if seen["GET"] {
+		seen["HEAD"] = true
+	}
+	seen["OPTIONS"] = true
+	
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
 }
 
 // ServeHTTP implements the http.Handler interface
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	path := req.URL.Path
 	method := req.Method
+	var err error
+	
+	// The host is only resolved when domain routes need it
+	var client httpInternal.ClientInfo
+	host := ""
+	if len(r.domains) > 0 {
+		client = r.proxies.Resolve(req)
+		host = hostname(client.Host)
+	}
 	
-	route, params := r.match(method, path)
+	route, params := r.match(host, method, path)
+	
+	// Serve HEAD from the GET handler unless HEAD was registered explicitly
+	if route == nil && method == "HEAD" {
+		if route, params = r.match(host, "GET", path); route != nil {
+			w = &headResponseWriter{ResponseWriter: w}
+		}
+	}
+	
+	// Record the request duration, and a span when tracing is enabled
+	start := time.Now()
+	sw := &statusWriter{ResponseWriter: w}
+	w = sw
+	req, span := r.startSpan(req, route)
+	defer func() {
+		observeRequest(method, route, sw.Status(), time.Since(start))
+		if span != nil {
+			endSpan(span, sw.Status(), err)
+		}
+	}()
 	
 	// Create context from the context package
 	ctx := context.NewContext(w, req, r.app)
@@ -216,24 +403,52 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	for key, value := range params {
 		ctx.SetParam(key, value)
 	}
+	if len(r.domains) > 0 {
+		ctx.SetURLGenerator(r.requestURLs(route, host, client))
+	} else {
+		ctx.SetURLGenerator(r)
+	}
+	ctx.SetTrustedProxies(r.proxies)
+	if r.maxMultipartMemory > 0 {
+		ctx.SetMaxMultipartMemory(r.maxMultipartMemory)
+	}
 	
-	if route == nil {
-		// No matching route found
-		ctx.AddMiddleware(r.middleware...)
+	ctx.AddMiddleware(r.middleware...)
+	
+	if route != nil {
+		// Route found, add limits, middleware and handler
+		ctx.AddMiddleware(route.limits()...)
+		ctx.AddMiddleware(route.middleware...)
 		ctx.AddMiddleware(func(c httpInternal.Context) error {
-			return r.notFound(c)
+			if err := r.resolveBindings(c, route); err != nil {
+				return err
+			}
+			return route.handler(c)
 		})
+	} else if allowed := r.allowedMethods(host, path); len(allowed) > 0 {
+		// The path exists under other methods
+		allow := strings.Join(allowed, ", ")
+		if method == "OPTIONS" {
+			ctx.AddMiddleware(func(c httpInternal.Context) error {
+				c.SetHeader("Allow", allow)
+				c.Status(http.StatusNoContent)
+				return nil
+			})
+		} else {
+			ctx.AddMiddleware(func(c httpInternal.Context) error {
+				c.SetHeader("Allow", allow)
+				return r.notAllowed(c)
+			})
+		}
 	} else {
-		// Route found, add middleware and handler
-		ctx.AddMiddleware(r.middleware...)
-		ctx.AddMiddleware(route.middleware...)
+		// No matching route found
 		ctx.AddMiddleware(func(c httpInternal.Context) error {
-			return route.handler(c)
+			return r.notFound(c)
 		})
 	}
 	
 	// Execute middleware chain
-	if err := ctx.Next(); err != nil {
+	if err = ctx.Next(); err != nil {
 		// Handle error through application error handler
 		if !ctx.IsAborted() && r.app != nil {
 			r.app.ErrorHandler().Handle(ctx, err)
@@ -241,6 +456,20 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// headResponseWriter discards the response body so GET handlers can answer HEAD requests
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // GetRoutes returns all registered routes (for debugging/introspection)
 func (r *Router) GetRoutes() []httpInternal.Route {
 	routes := make([]httpInternal.Route, len(r.routes))
@@ -248,6 +477,8 @@ func (r *Router) GetRoutes() []httpInternal.Route {
 		routes[i] = &routeInfo{
 			method:     route.method,
 			pattern:    route.pattern,
+			domain:     route.domainPattern(),
+			name:       route.name,
 			paramNames: route.paramNames,
 			handler:    route.handler,
 			middleware: route.middleware,
@@ -260,6 +491,8 @@ func (r *Router) GetRoutes() []httpInternal.Route {
 type routeInfo struct {
 	method     string
 	pattern    string
+	domain     string
+	name       string
 	paramNames []string
 	handler    httpInternal.HandlerFunc
 	middleware []httpInternal.MiddlewareFunc
@@ -273,6 +506,14 @@ func (r *routeInfo) Pattern() string {
 	return r.pattern
 }
 
+func (r *routeInfo) Domain() string {
+	return r.domain
+}
+
+func (r *routeInfo) Name() string {
+	return r.name
+}
+
 func (r *routeInfo) Handler() httpInternal.HandlerFunc {
 	return r.handler
 }
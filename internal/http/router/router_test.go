@@ -1,9 +1,23 @@
 package router
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	httpInternal "github.com/onyx-go/framework/internal/http"
+	"github.com/onyx-go/framework/internal/http/websocket"
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 func TestNewRouter(t *testing.T) {
@@ -188,7 +202,7 @@ func TestRouterMatch(t *testing.T) {
 	router.POST("/users", handler)
 	
 	// Test successful match
-	route, params := router.match("GET", "/users/123")
+	route, params := router.match("", "GET", "/users/123")
 	if route == nil {
 		t.Error("expected to find matching route")
 	}
@@ -197,13 +211,13 @@ func TestRouterMatch(t *testing.T) {
 	}
 	
 	// Test no match for wrong method
-	route, params = router.match("POST", "/users/123")
+	route, params = router.match("", "POST", "/users/123")
 	if route != nil {
 		t.Error("expected no match for POST /users/123")
 	}
 	
 	// Test successful match for different route
-	route, params = router.match("POST", "/users")
+	route, params = router.match("", "POST", "/users")
 	if route == nil {
 		t.Error("expected to find matching route for POST /users")
 	}
@@ -315,4 +329,978 @@ func TestGetRoutes(t *testing.T) {
 	if len(routes[0].ParamNames()) != 1 || routes[0].ParamNames()[0] != "id" {
 		t.Errorf("expected param names [id], got %v", routes[0].ParamNames())
 	}
-}
\ No newline at end of file
+}
+
+func TestRouterStaticBeatsParam(t *testing.T) {
+	router := NewRouter()
+	
+	var matched string
+	handlerFor := func(name string) httpInternal.HandlerFunc {
+		return func(c httpInternal.Context) error {
+			matched = name
+			return nil
+		}
+	}
+	
+	// Parameter route registered before the static one
+	router.GET("/users/{id}", handlerFor("param"))
+	router.GET("/users/new", handlerFor("static"))
+	router.GET("/users/{id:int}/edit", handlerFor("int-edit"))
+	router.GET("/users/{name}/edit", handlerFor("name-edit"))
+	
+	tests := []struct {
+		path     string
+		expected string
+		params   map[string]string
+	}{
+		{"/users/new", "static", map[string]string{}},
+		{"/users/42", "param", map[string]string{"id": "42"}},
+		{"/users/42/edit", "int-edit", map[string]string{"id": "42"}},
+		{"/users/bob/edit", "name-edit", map[string]string{"name": "bob"}},
+	}
+	
+	for _, test := range tests {
+		route, params := router.match("", "GET", test.path)
+		if route == nil {
+			t.Errorf("path %s: expected a match", test.path)
+			continue
+		}
+		
+		matched = ""
+		route.handler(nil)
+		if matched != test.expected {
+			t.Errorf("path %s: expected %s route, got %s", test.path, test.expected, matched)
+		}
+		
+		if len(params) != len(test.params) {
+			t.Errorf("path %s: expected params %v, got %v", test.path, test.params, params)
+		}
+		for key, value := range test.params {
+			if params[key] != value {
+				t.Errorf("path %s: expected param %s=%s, got %s", test.path, key, value, params[key])
+			}
+		}
+	}
+}
+
+func TestRouterMatchBacktracking(t *testing.T) {
+	router := NewRouter()
+	
+	handler := func(c httpInternal.Context) error {
+		return nil
+	}
+	
+	// The static branch has no route for /files/archive/{id}, so lookup must
+	// fall back to the parameter branch.
+	router.GET("/files/archive", handler)
+	router.GET("/files/{folder}/{id:int}", handler)
+	
+	route, params := router.match("", "GET", "/files/archive/7")
+	if route == nil {
+		t.Fatal("expected backtracking into the parameter branch")
+	}
+	if params["folder"] != "archive" || params["id"] != "7" {
+		t.Errorf("unexpected params %v", params)
+	}
+	
+	// A static route registered only for POST must not shadow a GET param route
+	router.POST("/files/upload", handler)
+	route, params = router.match("", "GET", "/files/upload/3")
+	if route == nil || params["folder"] != "upload" {
+		t.Errorf("expected GET to resolve through the parameter route, got %v", params)
+	}
+}
+
+func TestRouterMatchEdgeCases(t *testing.T) {
+	router := NewRouter()
+	
+	handler := func(c httpInternal.Context) error {
+		return nil
+	}
+	
+	router.GET("/", handler)
+	router.GET("/users", handler)
+	router.GET("/codes/{code:alphanum}", handler)
+	router.GET("/legacy/{id:unknown}", handler)
+	
+	tests := map[string]bool{
+		"/":             true,
+		"":              true,
+		"/users":        true,
+		"/users/":       false,
+		"//users":       false,
+		"/codes/ab12":   true,
+		"/codes/ab-12":  false,
+		"/legacy/a-b.c": true,
+		"/missing":      false,
+	}
+	
+	for path, shouldMatch := range tests {
+		route, _ := router.match("", "GET", path)
+		if (route != nil) != shouldMatch {
+			t.Errorf("path %q: expected match=%v, got %v", path, shouldMatch, route != nil)
+		}
+	}
+}
+
+func TestRouterDuplicateRouteFirstWins(t *testing.T) {
+	router := NewRouter()
+	
+	var matched string
+	router.GET("/dup", func(c httpInternal.Context) error {
+		matched = "first"
+		return nil
+	})
+	router.GET("/dup", func(c httpInternal.Context) error {
+		matched = "second"
+		return nil
+	})
+	
+	route, _ := router.match("", "GET", "/dup")
+	if route == nil {
+		t.Fatal("expected a match")
+	}
+	route.handler(nil)
+	if matched != "first" {
+		t.Errorf("expected first registered route to win, got %s", matched)
+	}
+	
+	if len(router.GetRoutes()) != 2 {
+		t.Errorf("expected both routes to be listed, got %d", len(router.GetRoutes()))
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	
+	handler := func(c httpInternal.Context) error {
+		return c.String(200, "ok")
+	}
+	
+	router.GET("/users/{id}", handler)
+	router.DELETE("/users/new", handler)
+	router.PUT("/users/{id:int}", handler)
+	
+	req := httptest.NewRequest("POST", "/users/new", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+	
+	// Both the static and the parameter branch contribute methods
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("unexpected Allow header %q", allow)
+	}
+	
+	req = httptest.NewRequest("POST", "/users/5", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS, PUT" {
+		t.Errorf("unexpected Allow header %q", allow)
+	}
+	
+	// Unknown paths are still 404
+	req = httptest.NewRequest("POST", "/nothing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown path, got %d", w.Code)
+	}
+}
+
+func TestRouterCustomMethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	router.POST("/login", func(c httpInternal.Context) error { return nil })
+	router.SetMethodNotAllowed(func(c httpInternal.Context) error {
+		return c.String(405, "use "+c.ResponseWriter().Header().Get("Allow"))
+	})
+	
+	req := httptest.NewRequest("GET", "/login", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	if w.Body.String() != "use OPTIONS, POST" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestRouterAutomaticOptions(t *testing.T) {
+	router := NewRouter()
+	
+	handler := func(c httpInternal.Context) error {
+		return c.String(200, "ok")
+	}
+	
+	router.GET("/items", handler)
+	router.POST("/items", handler)
+	
+	req := httptest.NewRequest("OPTIONS", "/items", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS, POST" {
+		t.Errorf("unexpected Allow header %q", allow)
+	}
+	
+	// An explicit OPTIONS route takes precedence
+	router.OPTIONS("/items", func(c httpInternal.Context) error {
+		return c.String(200, "custom")
+	})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/items", nil))
+	if w.Code != 200 || w.Body.String() != "custom" {
+		t.Errorf("expected explicit OPTIONS handler, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouterHeadFallsBackToGet(t *testing.T) {
+	router := NewRouter()
+	
+	router.GET("/page/{id}", func(c httpInternal.Context) error {
+		c.SetHeader("X-Page", c.Param("id"))
+		return c.String(200, "page body")
+	})
+	
+	req := httptest.NewRequest("HEAD", "/page/9", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	if w.Code != 200 {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+	if w.Header().Get("X-Page") != "9" {
+		t.Errorf("expected GET handler headers, got %v", w.Header())
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body for HEAD, got %q", w.Body.String())
+	}
+	
+	// An explicit HEAD route takes precedence
+	router.HEAD("/page/{id}", func(c httpInternal.Context) error {
+		c.SetHeader("X-Head", "explicit")
+		c.Status(200)
+		return nil
+	})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("HEAD", "/page/9", nil))
+	if w.Header().Get("X-Head") != "explicit" {
+		t.Error("expected explicit HEAD handler to run")
+	}
+}
+
+func TestNamedRoutes(t *testing.T) {
+	router := NewRouter()
+	
+	handler := func(c httpInternal.Context) error {
+		return nil
+	}
+	
+	router.GET("/", handler).Name("home")
+	router.GET("/users/{id:int}", handler).Name("users.show")
+	
+	admin := router.Group("/admin").Name("admin.")
+	admin.GET("/dashboard", handler).Name("dashboard")
+	reports := admin.Group("/reports/{year:int}").Name("reports.")
+	reports.GET("/{slug}", handler).Name("show")
+	
+	tests := []struct {
+		name     string
+		params   map[string]string
+		query    url.Values
+		expected string
+	}{
+		{"home", nil, nil, "/"},
+		{"users.show", map[string]string{"id": "42"}, nil, "/users/42"},
+		{"users.show", map[string]string{"id": "42"}, url.Values{"tab": {"posts"}}, "/users/42?tab=posts"},
+		{"admin.dashboard", nil, nil, "/admin/dashboard"},
+		{"admin.reports.show", map[string]string{"year": "2024", "slug": "q1 sales"}, nil, "/admin/reports/2024/q1%20sales"},
+	}
+	
+	for _, test := range tests {
+		result, err := router.URL(test.name, test.params, test.query)
+		if err != nil {
+			t.Errorf("route %s: unexpected error %v", test.name, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("route %s: expected %s, got %s", test.name, test.expected, result)
+		}
+	}
+	
+	routes := router.GetRoutes()
+	if routes[1].Name() != "users.show" {
+		t.Errorf("expected route name users.show, got %q", routes[1].Name())
+	}
+	
+	if err := router.Err(); err != nil {
+		t.Errorf("unexpected registration error: %v", err)
+	}
+}
+
+func TestNamedRouteErrors(t *testing.T) {
+	router := NewRouter()
+	
+	handler := func(c httpInternal.Context) error {
+		return nil
+	}
+	
+	router.GET("/users/{id:int}", handler).Name("users.show")
+	router.GET("/people/{id}", handler).Name("users.show")
+	
+	if err := router.Err(); !errors.Is(err, ErrDuplicateRouteName) {
+		t.Errorf("expected duplicate route name error, got %v", err)
+	}
+	
+	if _, err := router.URL("missing", nil, nil); !errors.Is(err, ErrRouteNotDefined) {
+		t.Errorf("expected route not defined error, got %v", err)
+	}
+	
+	if _, err := router.URL("users.show", nil, nil); !errors.Is(err, ErrMissingRouteParameter) {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+	
+	if _, err := router.URL("users.show", map[string]string{"id": "abc"}, nil); !errors.Is(err, ErrInvalidRouteParameter) {
+		t.Errorf("expected invalid parameter error, got %v", err)
+	}
+}
+
+type stubResourceController struct{}
+
+func (stubResourceController) Index(httpInternal.Context) error   { return nil }
+func (stubResourceController) Show(httpInternal.Context) error    { return nil }
+func (stubResourceController) Store(httpInternal.Context) error   { return nil }
+func (stubResourceController) Update(httpInternal.Context) error  { return nil }
+func (stubResourceController) Destroy(httpInternal.Context) error { return nil }
+
+func TestResourceRouteNames(t *testing.T) {
+	router := NewRouter()
+	group := router.Group("/api").Name("api.").(*RouteGroup)
+	group.Resource("posts", stubResourceController{})
+	
+	result, err := router.URL("api.posts.update", map[string]string{"id": "3"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "/api/posts/3" {
+		t.Errorf("expected /api/posts/3, got %s", result)
+	}
+}
+
+func TestResourceRouteNamesFromPath(t *testing.T) {
+	router := NewRouter()
+	router.Group("/api/v1").(*RouteGroup).Resource("users", stubResourceController{})
+	router.Group("/api/v2").(*RouteGroup).Resource("users", stubResourceController{})
+	router.Group("/api/v2").(*RouteGroup).Resource("users", stubResourceController{})
+	
+	if err := router.Err(); err != nil {
+		t.Fatalf("unexpected registration error: %v", err)
+	}
+	result, err := router.URL("api.v2.users.show", map[string]string{"id": "3"}, nil)
+	if err != nil || result != "/api/v2/users/3" {
+		t.Errorf("expected /api/v2/users/3, got %s (%v)", result, err)
+	}
+	if !router.HasRoute("api.v1.users.index") {
+		t.Error("expected the v1 resource to be named after its path")
+	}
+}
+
+func TestRouteFunc(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/{id}/posts/{post}", func(c httpInternal.Context) error { return nil }).Name("posts.show")
+	
+	routeFunc := router.RouteFunc()
+	
+	result, err := routeFunc("posts.show", "id", 7, "post", "hello")
+	if err != nil || result != "/users/7/posts/hello" {
+		t.Errorf("expected /users/7/posts/hello, got %s (%v)", result, err)
+	}
+	
+	result, err = routeFunc("posts.show", map[string]interface{}{"id": 1, "post": 2})
+	if err != nil || result != "/users/1/posts/2" {
+		t.Errorf("expected /users/1/posts/2, got %s (%v)", result, err)
+	}
+	
+	if _, err := routeFunc("posts.show", "id"); err == nil {
+		t.Error("expected error for odd number of arguments")
+	}
+}
+
+func TestRouteBinding(t *testing.T) {
+	router := NewRouter()
+	errNoSuchUser := errors.New("no such user")
+	
+	var captured error
+	router.Use(func(c httpInternal.Context) error {
+		captured = c.Next()
+		return nil
+	})
+	
+	router.Bind("user", func(c httpInternal.Context, value string, withTrashed bool) (interface{}, error) {
+		if value == "0" {
+			return nil, errNoSuchUser
+		}
+		return fmt.Sprintf("user:%s:%t", value, withTrashed), nil
+	})
+	
+	handler := func(c httpInternal.Context) error {
+		user, _ := c.Get("user")
+		return c.String(200, fmt.Sprint(user))
+	}
+	router.GET("/users/{user}", handler)
+	router.GET("/archive/users/{user}", handler).WithTrashed()
+	router.GET("/posts/{post}", func(c httpInternal.Context) error {
+		_, bound := c.Get("post")
+		return c.String(200, fmt.Sprint(bound))
+	})
+	
+	tests := []struct {
+		path string
+		body string
+		err  error
+	}{
+		{"/users/7", "user:7:false", nil},
+		{"/archive/users/7", "user:7:true", nil},
+		{"/users/0", "", errNoSuchUser},
+		{"/posts/3", "false", nil},
+	}
+	
+	for _, tt := range tests {
+		captured = nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		
+		if !errors.Is(captured, tt.err) {
+			t.Errorf("%s: expected error %v, got %v", tt.path, tt.err, captured)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+// linearRoute and linearMatch reproduce the previous regex-per-route matcher
+// so the prefix tree can be benchmarked against it.
+type linearRoute struct {
+	method     string
+	regex      *regexp.Regexp
+	paramNames []string
+}
+
+func linearMatch(routes []linearRoute, method, path string) (*linearRoute, map[string]string) {
+	for i := range routes {
+		if routes[i].method != method {
+			continue
+		}
+		
+		matches := routes[i].regex.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+		
+		params := make(map[string]string)
+		for j, name := range routes[i].paramNames {
+			if j+1 < len(matches) {
+				params[name] = matches[j+1]
+			}
+		}
+		return &routes[i], params
+	}
+	return nil, nil
+}
+
+// benchmarkRoutes builds a route table shaped like a large REST API
+func benchmarkRoutes(resources int) []string {
+	patterns := make([]string, 0, resources*6)
+	for i := 0; i < resources; i++ {
+		base := fmt.Sprintf("/api/v1/resource%d", i)
+		patterns = append(patterns,
+			base,
+			base+"/{id:int}",
+			base+"/{id:int}/edit",
+			base+"/{id:int}/children/{childId}",
+			base+"/search",
+			base+"/{slug:alpha}/export",
+		)
+	}
+	return patterns
+}
+
+func benchmarkPaths(resources int) []string {
+	return []string{
+		"/api/v1/resource0",
+		fmt.Sprintf("/api/v1/resource%d/123", resources/2),
+		fmt.Sprintf("/api/v1/resource%d/123/children/abc", resources-1),
+		fmt.Sprintf("/api/v1/resource%d/search", resources-1),
+		"/api/v1/missing/1",
+	}
+}
+
+func BenchmarkRouterMatchTree(b *testing.B) {
+	for _, resources := range []int{10, 100} {
+		b.Run(fmt.Sprintf("routes=%d", resources*6), func(b *testing.B) {
+			router := NewRouter()
+			handler := func(c httpInternal.Context) error { return nil }
+			for _, pattern := range benchmarkRoutes(resources) {
+				router.GET(pattern, handler)
+			}
+			paths := benchmarkPaths(resources)
+			
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				router.match("", "GET", paths[i%len(paths)])
+			}
+		})
+	}
+}
+
+func BenchmarkRouterMatchLinear(b *testing.B) {
+	for _, resources := range []int{10, 100} {
+		b.Run(fmt.Sprintf("routes=%d", resources*6), func(b *testing.B) {
+			router := NewRouter()
+			var routes []linearRoute
+			for _, pattern := range benchmarkRoutes(resources) {
+				regex, names := router.compilePattern(pattern)
+				routes = append(routes, linearRoute{method: "GET", regex: regex, paramNames: names})
+			}
+			paths := benchmarkPaths(resources)
+			
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				linearMatch(routes, "GET", paths[i%len(paths)])
+			}
+		})
+	}
+}
+
+func TestWebSocketRoute(t *testing.T) {
+	router := NewRouter()
+	
+	requireToken := func(c httpInternal.Context) error {
+		if c.Header("Authorization") != "Bearer secret" {
+			c.Abort()
+			return c.String(401, "unauthorized")
+		}
+		c.Set("user", "alice")
+		return nil
+	}
+	
+	api := router.Group("/rooms")
+	api.WS("/{room}", func(c httpInternal.Context, conn *websocket.Conn) error {
+		user, _ := c.Get("user")
+		return conn.WriteText(fmt.Sprintf("%s joined %s", user, c.Param("room")))
+	}, requireToken)
+	
+	server := httptest.NewServer(router)
+	defer server.Close()
+	
+	handshake := func(authorization string) (*http.Response, *bufio.Reader, net.Conn) {
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		
+		fmt.Fprintf(conn, "GET /rooms/lobby HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\nAuthorization: %s\r\n\r\n",
+			server.Listener.Addr(), authorization)
+		
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatalf("handshake failed: %v", err)
+		}
+		return resp, reader, conn
+	}
+	
+	resp, _, conn := handshake("Bearer wrong")
+	conn.Close()
+	if resp.StatusCode != 401 {
+		t.Errorf("expected middleware to reject the upgrade with 401, got %d", resp.StatusCode)
+	}
+	
+	resp, reader, conn := handshake("Bearer secret")
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	
+	header := make([]byte, 2)
+	if _, err := reader.Read(header); err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+	payload := make([]byte, header[1]&0x7F)
+	if _, err := reader.Read(payload); err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+	if header[0]&0x0F != 0x1 || string(payload) != "alice joined lobby" {
+		t.Errorf("unexpected message %q", payload)
+	}
+	
+	// The handler returning closes the connection with a normal closure
+	if _, err := reader.Read(header); err != nil || header[0]&0x0F != 0x8 {
+		t.Errorf("expected close frame, got %v %v", header, err)
+	}
+}
+
+func TestWildcardRoute(t *testing.T) {
+	router := NewRouter()
+	
+	router.GET("/docs/{page}", func(c httpInternal.Context) error {
+		return c.String(200, "page:"+c.Param("page"))
+	})
+	router.GET("/docs/{rest...}", func(c httpInternal.Context) error {
+		return c.String(200, "rest:"+c.Param("rest"))
+	}).Name("docs")
+	
+	tests := []struct {
+		path     string
+		code     int
+		expected string
+	}{
+		{"/docs/intro", 200, "page:intro"},
+		{"/docs/guide/routing/groups", 200, "rest:guide/routing/groups"},
+		{"/docs/", 200, "rest:"},
+		{"/docs", 404, ""},
+	}
+	
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.code || (test.code == 200 && w.Body.String() != test.expected) {
+			t.Errorf("%s: expected %d %q, got %d %q", test.path, test.code, test.expected, w.Code, w.Body.String())
+		}
+	}
+	
+	result, err := router.URL("docs", map[string]string{"rest": "guide/first steps"}, nil)
+	if err != nil || result != "/docs/guide/first%20steps" {
+		t.Errorf("expected escaped wildcard URL, got %s %v", result, err)
+	}
+}
+
+func TestStaticFiles(t *testing.T) {
+	router := NewRouter()
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	
+	public := fstest.MapFS{
+		"index.html":         {Data: []byte("<h1>home</h1>"), ModTime: modified},
+		"css/app.css":        {Data: []byte("body { color: red; }"), ModTime: modified},
+		"css/app.css.br":     {Data: []byte("brotli"), ModTime: modified},
+		"css/app.css.gz":     {Data: []byte("gzip"), ModTime: modified},
+		"js/app.js":          {Data: []byte("console.log(1)"), ModTime: modified},
+		"docs/guide.txt":     {Data: []byte("0123456789"), ModTime: modified},
+		"docs/nested/a.html": {Data: []byte("nested"), ModTime: modified},
+	}
+	
+	router.Static("/assets/", public,
+		httpInternal.WithCacheControl("css/", "public, max-age=31536000, immutable"),
+		httpInternal.WithCacheControl("*.html", "no-cache"),
+	)
+	router.Group("/v1").Static("/public", public, httpInternal.WithoutPrecompressed())
+	
+	request := func(method, target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	
+	w := request("GET", "/assets/js/app.js", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "console.log(1)" {
+		t.Fatalf("expected file content, got %d %q", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, "W/") {
+		t.Errorf("expected a strong ETag, got %q", etag)
+	}
+	if w.Header().Get("Last-Modified") != modified.Format(http.TimeFormat) || w.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+	if w.Header().Get("Cache-Control") != "" {
+		t.Errorf("expected no Cache-Control without a matching rule, got %q", w.Header().Get("Cache-Control"))
+	}
+	
+	// Conditional requests
+	if w := request("GET", "/assets/js/app.js", map[string]string{"If-None-Match": etag}); w.Code != 304 || w.Body.Len() != 0 {
+		t.Errorf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+	if w := request("GET", "/assets/js/app.js", map[string]string{"If-None-Match": `"other"`}); w.Code != 200 {
+		t.Errorf("expected 200 for a stale ETag, got %d", w.Code)
+	}
+	if w := request("GET", "/assets/js/app.js", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}); w.Code != 304 {
+		t.Errorf("expected 304 for an unmodified file, got %d", w.Code)
+	}
+	
+	// Byte ranges
+	w = request("GET", "/assets/docs/guide.txt", map[string]string{"Range": "bytes=2-5"})
+	if w.Code != 206 || w.Body.String() != "2345" || w.Header().Get("Content-Range") != "bytes 2-5/10" {
+		t.Errorf("expected partial content, got %d %q %q", w.Code, w.Body.String(), w.Header().Get("Content-Range"))
+	}
+	if w := request("GET", "/assets/docs/guide.txt", map[string]string{"Range": "bytes=20-30"}); w.Code != 416 {
+		t.Errorf("expected 416 for an unsatisfiable range, got %d", w.Code)
+	}
+	
+	// Precompressed variants
+	encodings := []struct {
+		accept   string
+		encoding string
+		body     string
+	}{
+		{"gzip, deflate, br", "br", "brotli"},
+		{"gzip", "gzip", "gzip"},
+		{"br;q=0, gzip;q=0.5", "gzip", "gzip"},
+		{"*", "br", "brotli"},
+		{"identity", "", "body { color: red; }"},
+		{"", "", "body { color: red; }"},
+	}
+	for _, test := range encodings {
+		w := request("GET", "/assets/css/app.css", map[string]string{"Accept-Encoding": test.accept})
+		if w.Header().Get("Content-Encoding") != test.encoding || w.Body.String() != test.body {
+			t.Errorf("Accept-Encoding %q: expected %q, got %q %q", test.accept, test.encoding, w.Header().Get("Content-Encoding"), w.Body.String())
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: unexpected headers %v", test.accept, w.Header())
+		}
+		if w.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+			t.Errorf("expected the css/ cache rule, got %q", w.Header().Get("Cache-Control"))
+		}
+	}
+	if w := request("GET", "/v1/public/css/app.css", map[string]string{"Accept-Encoding": "br"}); w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("expected precompressed variants to be disabled, got %q", w.Header().Get("Content-Encoding"))
+	}
+	
+	// Index files and directories
+	w = request("GET", "/assets/", nil)
+	if w.Code != 200 || w.Body.String() != "<h1>home</h1>" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("expected the index file, got %d %q", w.Code, w.Body.String())
+	}
+	if w := request("GET", "/assets/docs", nil); w.Code != 301 || w.Header().Get("Location") != "/assets/docs/" {
+		t.Errorf("expected a redirect to the directory, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := request("GET", "/assets/docs/", nil); w.Code != 404 {
+		t.Errorf("expected 404 for a directory without index, got %d", w.Code)
+	}
+	
+	// HEAD requests send headers only
+	w = request("HEAD", "/assets/js/app.js", nil)
+	if w.Code != 200 || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "14" {
+		t.Errorf("expected headers only for HEAD, got %d %q", w.Code, w.Body.String())
+	}
+	
+	// Traversal and missing files
+	for _, target := range []string{
+		"/assets/../router.go",
+		"/assets/css/..%2f..%2frouter.go",
+		"/assets/css/%2e%2e/index.html",
+		"/assets/css\\app.css",
+		"/assets/missing.js",
+		"/v1/public/../public/index.html",
+	} {
+		// Set the decoded path directly, as the server would after unescaping
+		req := httptest.NewRequest("GET", "/", nil)
+		req.URL.Path, _ = url.PathUnescape(target)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != 404 {
+			t.Errorf("%s: expected 404, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	router := NewRouter()
+	router.GET("/whoami", func(c httpInternal.Context) error {
+		return c.String(200, c.RemoteIP()+" "+c.Scheme()+"://"+c.Host())
+	})
+	
+	request := func() string {
+		req := httptest.NewRequest("GET", "http://internal:8080/whoami", nil)
+		req.RemoteAddr = "127.0.0.1:51000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.5")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "example.com")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	
+	if got := request(); got != "127.0.0.1 http://internal:8080" {
+		t.Errorf("expected forwarding headers to be ignored by default, got %q", got)
+	}
+	
+	if err := router.SetTrustedProxies("loopback"); err != nil {
+		t.Fatalf("SetTrustedProxies failed: %v", err)
+	}
+	if got := request(); got != "203.0.113.5 https://example.com" {
+		t.Errorf("expected the forwarded client, got %q", got)
+	}
+	
+	if err := router.SetTrustedProxies("not a network"); err == nil {
+		t.Error("expected an error for an invalid proxy")
+	}
+}
+
+func TestDomainRouting(t *testing.T) {
+	router := NewRouter()
+	
+	router.GET("/", func(c httpInternal.Context) error {
+		return c.String(200, "global")
+	})
+	
+	api := router.Domain("api.example.com")
+	api.GET("/users", func(c httpInternal.Context) error {
+		return c.String(200, "api users")
+	}).Name("api.users")
+	
+	tenant := router.Domain("{tenant}.example.com").Name("tenant.")
+	tenant.GET("/dashboard", func(c httpInternal.Context) error {
+		urls := c.(interface {
+			RouteURL(string, map[string]string, url.Values) (string, error)
+		})
+		settings, err := urls.RouteURL("tenant.settings", nil, nil)
+		if err != nil {
+			return err
+		}
+		users, err := urls.RouteURL("api.users", nil, nil)
+		if err != nil {
+			return err
+		}
+		return c.String(200, c.Param("tenant")+" "+settings+" "+users)
+	}).Name("dashboard")
+	tenant.Group("/settings").GET("", func(c httpInternal.Context) error {
+		return c.String(200, "settings")
+	}).Name("settings")
+	
+	router.Domain("{id:int}.numbers.test").GET("/", func(c httpInternal.Context) error {
+		return c.String(200, "number "+c.Param("id"))
+	})
+	
+	request := func(method, host, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Host = host
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	
+	tests := []struct {
+		host     string
+		path     string
+		code     int
+		expected string
+	}{
+		{"api.example.com", "/users", 200, "api users"},
+		{"API.Example.com.", "/users", 200, "api users"},
+		{"acme.example.com", "/users", 404, ""},
+		{"acme.example.com:8080", "/dashboard", 200, "acme http://acme.example.com:8080/settings http://api.example.com:8080/users"},
+		{"acme.example.com", "/", 200, "global"},
+		{"example.com", "/dashboard", 404, ""},
+		{"deep.acme.example.com", "/dashboard", 404, ""},
+		{"42.numbers.test", "/", 200, "number 42"},
+		{"abc.numbers.test", "/", 200, "global"},
+	}
+	
+	for _, test := range tests {
+		w := request("GET", test.host, test.path)
+		if w.Code != test.code || (test.code == 200 && w.Body.String() != test.expected) {
+			t.Errorf("%s%s: expected %d %q, got %d %q", test.host, test.path, test.code, test.expected, w.Code, w.Body.String())
+		}
+	}
+	
+	if w := request("PUT", "acme.example.com", "/dashboard"); w.Code != 405 || !strings.Contains(w.Header().Get("Allow"), "GET") {
+		t.Errorf("expected 405 for another method on a domain route, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	if w := request("PUT", "other.test", "/dashboard"); w.Code != 404 {
+		t.Errorf("expected 404 for a domain route on another host, got %d", w.Code)
+	}
+	
+	// Outside a request domain routes are scheme-relative
+	result, err := router.URL("tenant.dashboard", map[string]string{"tenant": "Globex"}, url.Values{"tab": {"usage"}})
+	if err != nil || result != "//globex.example.com/dashboard?tab=usage" {
+		t.Errorf("expected a scheme-relative URL, got %s %v", result, err)
+	}
+	if _, err := router.URL("tenant.dashboard", nil, nil); !errors.Is(err, ErrMissingRouteParameter) {
+		t.Errorf("expected ErrMissingRouteParameter for a missing host parameter, got %v", err)
+	}
+	
+	// Host parameters take part in route model binding
+	router.Bind("tenant", func(c httpInternal.Context, value string, withTrashed bool) (interface{}, error) {
+		return "tenant:" + value, nil
+	})
+	router.Domain("{tenant}.example.com").GET("/whoami", func(c httpInternal.Context) error {
+		value, _ := c.Get("tenant")
+		return c.String(200, value.(string))
+	})
+	if w := request("GET", "acme.example.com", "/whoami"); w.Body.String() != "tenant:acme" {
+		t.Errorf("expected the bound tenant, got %q", w.Body.String())
+	}
+	
+	var domains []string
+	for _, route := range router.GetRoutes() {
+		domains = append(domains, route.Domain())
+	}
+	if domains[0] != "" || domains[1] != "api.example.com" || domains[2] != "{tenant}.example.com" {
+		t.Errorf("unexpected route domains %v", domains)
+	}
+}
+
+func TestRouterSpans(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(exporter)
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+	
+	router := NewRouter()
+	router.GET("/users/{id}", func(c httpInternal.Context) error {
+		_, span := tracing.Start(c.Request().Context(), "load user")
+		span.End()
+		return c.String(200, "user "+c.Param("id"))
+	})
+	router.GET("/fail", func(c httpInternal.Context) error {
+		return errors.New("boom")
+	})
+	
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	spans := exporter.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+	
+	handler, server := spans[0], spans[1]
+	if server.Name != "/users/{id}" || server.Kind != tracing.SpanKindServer {
+		t.Errorf("expected a server span named after the route, got %q kind %d", server.Name, server.Kind)
+	}
+	if server.SpanContext.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.SpanContext.ParentID != "00f067aa0ba902b7" {
+		t.Errorf("expected the server span to continue the caller's trace, got %+v", server.SpanContext)
+	}
+	if handler.SpanContext.ParentID != server.SpanContext.SpanID {
+		t.Errorf("expected handler spans to be children of the server span")
+	}
+	if server.Attributes["http.response.status_code"] != 200 || server.Attributes["http.route"] != "/users/{id}" {
+		t.Errorf("unexpected server span attributes %v", server.Attributes)
+	}
+	
+	if failed := spans[2]; failed.Status != tracing.StatusError || failed.StatusMessage != "boom" {
+		t.Errorf("expected the handler error to be recorded, got %v %q", failed.Status, failed.StatusMessage)
+	}
+	if missing := spans[3]; missing.Name != "GET" || missing.Status == tracing.StatusError || missing.Attributes["http.response.status_code"] != 404 {
+		t.Errorf("expected an unmatched request span named after the method, got %q %v %v", missing.Name, missing.Status, missing.Attributes)
+	}
+}
@@ -0,0 +1,237 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+// precompressedVariants are the sibling files tried for each request, in
+// order of preference
+var precompressedVariants = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Static serves the files of fsys below prefix, for example
+// Static("/assets", os.DirFS("public")) or an embed.FS. Responses carry a
+// strong ETag and Last-Modified, answer conditional and Range requests, and
+// use a .br or .gz sibling of the file when the client accepts it. Paths
+// containing ".." are refused.
+func (r *Router) Static(prefix string, fsys fs.FS, options ...httpInternal.StaticOption) httpInternal.RouteDefinition {
+	return r.addRoute("GET", staticPattern(prefix), r.staticHandler(fsys, options...))
+}
+
+// staticPattern is the route pattern capturing every path below prefix
+func staticPattern(prefix string) string {
+	return strings.TrimSuffix(prefix, "/") + "/{filepath...}"
+}
+
+// staticHandler serves files from fsys using the "filepath" route parameter
+func (r *Router) staticHandler(fsys fs.FS, options ...httpInternal.StaticOption) httpInternal.HandlerFunc {
+	opts := httpInternal.DefaultStaticOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	etags := &etagCache{entries: make(map[string]etagEntry)}
+
+	return func(c httpInternal.Context) error {
+		name, ok := cleanStaticPath(c.Param("filepath"))
+		if !ok {
+			return r.notFound(c)
+		}
+
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return r.notFound(c)
+		}
+
+		if info.IsDir() {
+			if opts.Index == "" {
+				return r.notFound(c)
+			}
+			// Relative links in the index only resolve below a trailing slash
+			if !strings.HasSuffix(c.Request().URL.Path, "/") {
+				http.Redirect(c.ResponseWriter(), c.Request(), c.Request().URL.Path+"/", http.StatusMovedPermanently)
+				return nil
+			}
+			name = path.Join(name, opts.Index)
+			if info, err = fs.Stat(fsys, name); err != nil || info.IsDir() {
+				return r.notFound(c)
+			}
+		}
+
+		header := c.ResponseWriter().Header()
+		servedName, encoding := name, ""
+
+		if opts.Precompressed {
+			hasVariant := false
+			for _, variant := range precompressedVariants {
+				variantInfo, err := fs.Stat(fsys, name+variant.extension)
+				if err != nil || variantInfo.IsDir() {
+					continue
+				}
+				hasVariant = true
+				if encoding == "" && acceptsEncoding(c.Header("Accept-Encoding"), variant.encoding) {
+					servedName, encoding, info = name+variant.extension, variant.encoding, variantInfo
+				}
+			}
+			if hasVariant {
+				header.Add("Vary", "Accept-Encoding")
+			}
+		}
+
+		file, err := fsys.Open(servedName)
+		if err != nil {
+			return r.notFound(c)
+		}
+		defer file.Close()
+
+		content, ok := file.(io.ReadSeeker)
+		if !ok {
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return err
+			}
+			content = bytes.NewReader(data)
+		}
+
+		etag, err := etags.get(servedName, info, content)
+		if err != nil {
+			return err
+		}
+		header.Set("ETag", etag)
+
+		if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+			header.Set("Content-Type", contentType)
+		} else if encoding != "" {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		if encoding != "" {
+			header.Set("Content-Encoding", encoding)
+		}
+		if cacheControl := matchCacheRule(opts.CacheControl, name); cacheControl != "" {
+			header.Set("Cache-Control", cacheControl)
+		}
+
+		http.ServeContent(c.ResponseWriter(), c.Request(), name, info.ModTime(), content)
+		return nil
+	}
+}
+
+// cleanStaticPath turns the captured path into an fs.FS name, refusing
+// anything that tries to leave the root
+func cleanStaticPath(raw string) (string, bool) {
+	if strings.ContainsAny(raw, "\\\x00") {
+		return "", false
+	}
+	for _, segment := range strings.Split(raw, "/") {
+		if segment == ".." {
+			return "", false
+		}
+	}
+
+	name := strings.Trim(path.Clean("/"+raw), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+// matchCacheRule returns the Cache-Control value of the first rule matching name
+func matchCacheRule(rules []httpInternal.CacheRule, name string) string {
+	for _, rule := range rules {
+		var matched bool
+		switch {
+		case strings.HasSuffix(rule.Pattern, "/"):
+			matched = strings.HasPrefix(name, strings.TrimPrefix(rule.Pattern, "/"))
+		case strings.Contains(rule.Pattern, "/"):
+			matched, _ = path.Match(strings.TrimPrefix(rule.Pattern, "/"), name)
+		default:
+			matched, _ = path.Match(rule.Pattern, path.Base(name))
+		}
+		if matched {
+			return rule.Value
+		}
+	}
+	return ""
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows encoding,
+// honouring q=0 and the "*" wildcard
+func acceptsEncoding(header, encoding string) bool {
+	accepted := false
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		token = strings.ToLower(strings.TrimSpace(token))
+		if token != encoding && token != "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			quality, _ = strconv.ParseFloat(value, 64)
+		}
+
+		if token == encoding {
+			return quality > 0
+		}
+		accepted = quality > 0
+	}
+	return accepted
+}
+
+// etagCache remembers content hashes so each file is hashed once per change
+type etagCache struct {
+	mutex   sync.Mutex
+	entries map[string]etagEntry
+}
+
+type etagEntry struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// get returns the strong ETag for a file, hashing its content when the file
+// is new or has changed. content is rewound afterwards.
+func (ec *etagCache) get(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	ec.mutex.Lock()
+	entry, exists := ec.entries[name]
+	ec.mutex.Unlock()
+
+	if exists && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.etag, nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+
+	ec.mutex.Lock()
+	ec.entries[name] = etagEntry{size: info.Size(), modTime: info.ModTime(), etag: etag}
+	ec.mutex.Unlock()
+
+	return etag, nil
+}
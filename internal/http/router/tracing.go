@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/onyx-go/framework/internal/tracing"
+)
+
+// startSpan records the request as a server span named after the matched
+// route pattern, continuing the caller's trace when traceparent is present.
+// Without a tracer the request is returned unchanged and the span is nil.
+func (r *Router) startSpan(req *http.Request, route *route) (*http.Request, *tracing.Span) {
+	if tracing.GetTracer() == nil {
+		return req, nil
+	}
+
+	ctx := req.Context()
+	if _, ok := tracing.SpanContextFrom(ctx); !ok {
+		if _, remote := tracing.Extract(req.Header); remote.IsValid() {
+			ctx = tracing.WithSpanContext(ctx, remote)
+		}
+	}
+
+	name := req.Method
+	attributes := map[string]interface{}{
+		"http.request.method": req.Method,
+		"url.path":            req.URL.Path,
+	}
+	if route != nil {
+		name = route.pattern
+		attributes["http.route"] = route.pattern
+	}
+	if userAgent := req.UserAgent(); userAgent != "" {
+		attributes["user_agent.original"] = userAgent
+	}
+
+	ctx, span := tracing.Start(ctx, name, tracing.WithKind(tracing.SpanKindServer), tracing.WithAttributes(attributes))
+	return req.WithContext(ctx), span
+}
+
+// endSpan records the response status and ends the span. Server errors mark
+// the span as failed; client errors do not.
+func endSpan(span *tracing.Span, status int, err error) {
+	span.SetAttribute("http.response.status_code", status)
+	if err != nil {
+		span.RecordError(err)
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(tracing.StatusError, http.StatusText(status))
+	}
+	span.End()
+}
@@ -0,0 +1,273 @@
+package router
+
+import (
+	"strings"
+)
+
+// segmentKind orders the children of a node during lookup. Lower kinds are
+// tried first, so static segments always win over parameters and constrained
+// parameters win over unconstrained ones, regardless of registration order.
+// A wildcard matches the rest of the path and is tried last.
+type segmentKind int
+
+const (
+	staticSegment segmentKind = iota
+	constrainedParamSegment
+	paramSegment
+	wildcardSegment
+)
+
+// patternSegment is a single parsed "/"-separated piece of a route pattern
+type patternSegment struct {
+	kind       segmentKind
+	value      string // literal text for static segments, parameter name otherwise
+	constraint string
+}
+
+// node is a single level of the route prefix tree. Each level corresponds to
+// one path segment; static children are looked up by exact text and parameter
+// children are tried in priority order with backtracking.
+type node struct {
+	static   map[string]*node
+	params   []*node
+	wildcard *node
+	name     string
+	kind     segmentKind
+	key      string
+	matcher  func(string) bool
+	routes   map[string]*route
+}
+
+// newNode creates an empty tree node
+func newNode() *node {
+	return &node{
+		static: make(map[string]*node),
+		routes: make(map[string]*route),
+	}
+}
+
+// parsePattern splits a route pattern into segments using the same
+// "{name}" / "{name:constraint}" syntax understood by compilePattern. A final
+// "{name...}" segment captures the rest of the path, slashes included.
+func parsePattern(pattern string) []patternSegment {
+	var segments []patternSegment
+
+	for _, part := range strings.Split(pattern, "/") {
+		if part == "" {
+			continue
+		}
+
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name := part[1 : len(part)-1]
+			if strings.HasSuffix(name, "...") {
+				segments = append(segments, patternSegment{kind: wildcardSegment, value: strings.TrimSuffix(name, "...")})
+				break
+			}
+
+			constraint := ""
+			if idx := strings.Index(name, ":"); idx >= 0 {
+				name, constraint = name[:idx], name[idx+1:]
+			}
+
+			kind := paramSegment
+			if constraintMatcher(constraint) != nil {
+				kind = constrainedParamSegment
+			}
+
+			segments = append(segments, patternSegment{kind: kind, value: name, constraint: constraint})
+			continue
+		}
+
+		segments = append(segments, patternSegment{kind: staticSegment, value: part})
+	}
+
+	return segments
+}
+
+// constraintMatcher returns the matcher for a known parameter constraint, or
+// nil when the constraint accepts any non-empty segment
+func constraintMatcher(constraint string) func(string) bool {
+	switch constraint {
+	case "int", "number":
+		return isDigits
+	case "alpha":
+		return isAlpha
+	case "alphanum":
+		return isAlphaNum
+	default:
+		return nil
+	}
+}
+
+// insert adds a route to the tree under the given parsed segments
+func (n *node) insert(segments []patternSegment, r *route) {
+	current := n
+
+	for _, seg := range segments {
+		if seg.kind == staticSegment {
+			child, exists := current.static[seg.value]
+			if !exists {
+				child = newNode()
+				current.static[seg.value] = child
+			}
+			current = child
+			continue
+		}
+
+		if seg.kind == wildcardSegment {
+			if current.wildcard == nil {
+				current.wildcard = newNode()
+				current.wildcard.name = seg.value
+				current.wildcard.kind = wildcardSegment
+			}
+			current = current.wildcard
+			continue
+		}
+
+		current = current.paramChild(seg)
+	}
+
+	// The first registration for a method wins, mirroring the previous
+	// first-match-in-registration-order behaviour.
+	if _, exists := current.routes[r.method]; !exists {
+		current.routes[r.method] = r
+	}
+}
+
+// paramChild returns the child for a parameter segment, creating it if needed
+func (n *node) paramChild(seg patternSegment) *node {
+	key := seg.value + ":" + seg.constraint
+	for _, child := range n.params {
+		if child.key == key {
+			return child
+		}
+	}
+
+	child := newNode()
+	child.name = seg.value
+	child.kind = seg.kind
+	child.key = key
+	child.matcher = constraintMatcher(seg.constraint)
+	if child.matcher == nil {
+		child.matcher = isNonEmpty
+	}
+
+	// Keep parameter children sorted by kind while preserving registration
+	// order within the same kind.
+	pos := len(n.params)
+	for i, existing := range n.params {
+		if existing.kind > child.kind {
+			pos = i
+			break
+		}
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[pos+1:], n.params[pos:])
+	n.params[pos] = child
+
+	return child
+}
+
+// search walks the tree for the given path. The path is either empty (fully
+// consumed) or starts with "/". Captured parameter values are appended to
+// values in path order. When accept returns false for a node the search
+// backtracks into lower-priority alternatives.
+func (n *node) search(path string, values []string, accept func(*node) bool) (*node, []string) {
+	if path == "" {
+		if accept(n) {
+			return n, values
+		}
+		return nil, values
+	}
+
+	if path[0] != '/' {
+		return nil, values
+	}
+
+	path = path[1:]
+	segment, rest := path, ""
+	if idx := strings.IndexByte(path, '/'); idx >= 0 {
+		segment, rest = path[:idx], path[idx:]
+	}
+
+	if child, exists := n.static[segment]; exists {
+		if found, vals := child.search(rest, values, accept); found != nil {
+			return found, vals
+		}
+	}
+
+	for _, child := range n.params {
+		if !child.matcher(segment) {
+			continue
+		}
+
+		if found, vals := child.search(rest, append(values, segment), accept); found != nil {
+			return found, vals
+		}
+	}
+
+	if n.wildcard != nil && accept(n.wildcard) {
+		return n.wildcard, append(values, path)
+	}
+
+	return nil, values
+}
+
+// lookup finds the route registered for method that matches path
+func (n *node) lookup(method, path string) (*route, []string) {
+	if path == "/" {
+		path = ""
+	}
+
+	found, values := n.search(path, nil, func(candidate *node) bool {
+		_, exists := candidate.routes[method]
+		return exists
+	})
+	if found == nil {
+		return nil, nil
+	}
+
+	return found.routes[method], values
+}
+
+func isNonEmpty(s string) bool {
+	return s != ""
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphaNum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+	"errors"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	"github.com/onyx-go/framework/internal/http/websocket"
+)
+
+// WS registers a WebSocket endpoint. The route and global middleware run
+// before the upgrade, so sessions, auth guards and rate limits apply as for
+// any GET route. The connection is closed when the handler returns; a
+// returned error closes it with code 1011.
+func (r *Router) WS(pattern string, handler httpInternal.WebSocketHandler, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.addRoute("GET", pattern, r.websocketHandler(handler), middleware...)
+}
+
+// SetWebSocketConfig sets the upgrade and connection settings for WS routes
+func (r *Router) SetWebSocketConfig(config websocket.Config) {
+	r.websocket = config
+}
+
+// websocketHandler upgrades the request and hands the connection to handler
+func (r *Router) websocketHandler(handler httpInternal.WebSocketHandler) httpInternal.HandlerFunc {
+	return func(c httpInternal.Context) error {
+		// Nothing may write to the response once the connection is upgraded,
+		// and a failed upgrade has already been answered
+		defer c.Abort()
+
+		conn, err := websocket.Upgrade(c.ResponseWriter(), c.Request(), r.websocket)
+		if err != nil {
+			return nil
+		}
+
+		// Errors cannot be rendered as HTTP responses on an upgraded
+		// connection, so they are reported to the client as a close code
+		if err := handler(c, conn); err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				conn.CloseWithReason(websocket.CloseInternalError, "internal error")
+				return nil
+			}
+		}
+
+		conn.Close()
+		return nil
+	}
+}
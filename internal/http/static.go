@@ -0,0 +1,54 @@
+package http
+
+// StaticOptions configures how Static serves a file system
+type StaticOptions struct {
+	// Index is the file served for directory requests. Empty disables it.
+	Index string
+
+	// CacheControl holds Cache-Control values by path pattern, first match wins
+	CacheControl []CacheRule
+
+	// Precompressed serves .br and .gz siblings to clients that accept them
+	Precompressed bool
+}
+
+// CacheRule sets the Cache-Control header for files matching Pattern. A
+// pattern ending in "/" matches every file below that directory, a pattern
+// containing "/" is matched against the whole path with path.Match, and any
+// other pattern, such as "*.css", is matched against the file name.
+type CacheRule struct {
+	Pattern string
+	Value   string
+}
+
+// StaticOption configures Static
+type StaticOption func(*StaticOptions)
+
+// DefaultStaticOptions returns the options Static starts from
+func DefaultStaticOptions() StaticOptions {
+	return StaticOptions{
+		Index:         "index.html",
+		Precompressed: true,
+	}
+}
+
+// WithCacheControl sets the Cache-Control header for files matching pattern
+func WithCacheControl(pattern, value string) StaticOption {
+	return func(opts *StaticOptions) {
+		opts.CacheControl = append(opts.CacheControl, CacheRule{Pattern: pattern, Value: value})
+	}
+}
+
+// WithIndexFile sets the file served for directory requests
+func WithIndexFile(name string) StaticOption {
+	return func(opts *StaticOptions) {
+		opts.Index = name
+	}
+}
+
+// WithoutPrecompressed disables serving .br and .gz siblings
+func WithoutPrecompressed() StaticOption {
+	return func(opts *StaticOptions) {
+		opts.Precompressed = false
+	}
+}
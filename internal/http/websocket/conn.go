@@ -0,0 +1,471 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of a data message
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+// Frame opcodes from RFC 6455 section 5.2
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes from RFC 6455 section 7.4.1
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseNoStatusReceived = 1005
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+)
+
+// maxControlPayload is the largest payload a control frame may carry
+const maxControlPayload = 125
+
+// closeTimeout is how long Close waits for the client to answer a close frame
+const closeTimeout = 5 * time.Second
+
+// CloseError is returned by ReadMessage when the client closes the connection
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Reason)
+}
+
+// protocolError is a violation of RFC 6455 by the client
+type protocolError string
+
+func (e protocolError) Error() string {
+	return "websocket: protocol error: " + string(e)
+}
+
+// frame is a single decoded WebSocket frame
+type frame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+// Conn is an upgraded WebSocket connection. One goroutine may read while
+// others write; writes are serialised internally.
+type Conn struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	writer      *bufio.Writer
+	subprotocol string
+	config      Config
+
+	writeMutex sync.Mutex
+	closeSent  bool
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newConn(netConn net.Conn, reader *bufio.Reader, writer *bufio.Writer, subprotocol string, config Config) *Conn {
+	c := &Conn{
+		conn:        netConn,
+		reader:      reader,
+		writer:      writer,
+		subprotocol: subprotocol,
+		config:      config,
+		done:        make(chan struct{}),
+	}
+
+	if config.PingInterval > 0 {
+		go c.keepalive()
+	}
+
+	return c
+}
+
+// Subprotocol returns the negotiated subprotocol, if any
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns the client's network address
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Done is closed once the underlying connection has been closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// ReadMessage reads the next complete data message, reassembling fragments
+// and answering pings along the way. When the client closes the connection
+// the close is acknowledged and a *CloseError is returned.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var messageType MessageType
+	var message []byte
+	started := false
+
+	for {
+		if c.config.PongTimeout > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.config.PongTimeout))
+		}
+
+		f, err := c.readFrame(c.maxMessageSize() - int64(len(message)))
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+
+		switch f.opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, f.payload); err != nil {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, c.handleClose(f.payload)
+		case opText, opBinary:
+			if started {
+				return 0, nil, c.fail(protocolError("new message started before the previous one finished"))
+			}
+			started = true
+			messageType = MessageType(f.opcode)
+		case opContinuation:
+			if !started {
+				return 0, nil, c.fail(protocolError("continuation frame without a message"))
+			}
+		default:
+			return 0, nil, c.fail(protocolError(fmt.Sprintf("unknown opcode %d", f.opcode)))
+		}
+
+		message = append(message, f.payload...)
+
+		if f.fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(errInvalidUTF8)
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteMessage sends a complete message in a single frame
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", messageType)
+	}
+	return c.writeFrame(byte(messageType), data)
+}
+
+// WriteText sends a text message
+func (c *Conn) WriteText(text string) error {
+	return c.writeFrame(opText, []byte(text))
+}
+
+// WriteJSON encodes v as JSON and sends it as a text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(opText, data)
+}
+
+// Ping sends a ping frame; the client's pong resets the read timeout
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return errors.New("websocket: ping payload too large")
+	}
+	return c.writeFrame(opPing, data)
+}
+
+// Close performs the closing handshake with a normal closure code
+func (c *Conn) Close() error {
+	return c.CloseWithReason(CloseNormalClosure, "")
+}
+
+// CloseWithReason sends a close frame, waits briefly for the client to
+// acknowledge it and closes the connection. It must not be called while
+// another goroutine is blocked in ReadMessage.
+func (c *Conn) CloseWithReason(code int, reason string) error {
+	if err := c.sendClose(code, reason); err != nil {
+		c.closeConn()
+		if errors.Is(err, ErrClosed) {
+			return nil
+		}
+		return err
+	}
+
+	// Wait for the client's close frame, discarding anything sent before it
+	// without buffering it
+	c.conn.SetReadDeadline(time.Now().Add(closeTimeout))
+	for {
+		f, length, _, err := c.readHeader()
+		if err != nil || f.opcode == opClose {
+			break
+		}
+		if _, err := io.CopyN(io.Discard, c.reader, length); err != nil {
+			break
+		}
+	}
+
+	c.closeConn()
+	return nil
+}
+
+// handleClose answers a client close frame and closes the connection
+func (c *Conn) handleClose(payload []byte) error {
+	code := CloseNoStatusReceived
+	reason := ""
+
+	switch {
+	case len(payload) == 1:
+		return c.fail(protocolError("invalid close frame payload"))
+	case len(payload) >= 2:
+		code = int(binary.BigEndian.Uint16(payload))
+		reason = string(payload[2:])
+		if !validCloseCode(code) {
+			return c.fail(protocolError(fmt.Sprintf("invalid close code %d", code)))
+		}
+		if !utf8.ValidString(reason) {
+			return c.fail(errInvalidUTF8)
+		}
+	}
+
+	replyCode := code
+	if replyCode == CloseNoStatusReceived {
+		replyCode = CloseNormalClosure
+	}
+	c.sendClose(replyCode, "")
+	c.closeConn()
+
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// fail closes the connection after a read error, telling the client why
+// when the error is its fault
+func (c *Conn) fail(err error) error {
+	var pe protocolError
+	switch {
+	case errors.As(err, &pe):
+		c.sendClose(CloseProtocolError, string(pe))
+	case errors.Is(err, errInvalidUTF8):
+		c.sendClose(CloseInvalidPayload, "invalid UTF-8")
+	case errors.Is(err, ErrMessageTooLarge):
+		c.sendClose(CloseMessageTooBig, "message too large")
+	}
+
+	c.closeConn()
+	return err
+}
+
+var errInvalidUTF8 = errors.New("websocket: invalid UTF-8 in text message")
+
+func (c *Conn) sendClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > maxControlPayload {
+		payload = payload[:maxControlPayload]
+	}
+	return c.writeFrame(opClose, payload)
+}
+
+func (c *Conn) closeConn() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// keepalive pings the client until the connection closes
+func (c *Conn) keepalive() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.writeFrame(opPing, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// maxMessageSize returns the configured message size limit, or the default
+// when none is set, so a client can never make the connection allocate an
+// arbitrary amount of memory
+func (c *Conn) maxMessageSize() int64 {
+	if c.config.MaxMessageSize > 0 {
+		return c.config.MaxMessageSize
+	}
+	return DefaultConfig().MaxMessageSize
+}
+
+// readFrame reads and unmasks one frame. A data frame longer than limit is
+// rejected before its payload is read.
+func (c *Conn) readFrame(limit int64) (frame, error) {
+	f, length, mask, err := c.readHeader()
+	if err != nil {
+		return frame{}, err
+	}
+	if f.opcode < opClose && length > limit {
+		return frame{}, ErrMessageTooLarge
+	}
+
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, f.payload); err != nil {
+		return frame{}, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+
+	return f, nil
+}
+
+// readHeader reads a frame header up to its payload, returning the payload
+// length and masking key. Control frames are checked against the 125 byte
+// limit here; data frame lengths are left to the caller.
+func (c *Conn) readHeader() (frame, int64, [4]byte, error) {
+	var mask [4]byte
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return frame{}, 0, mask, err
+	}
+
+	f := frame{
+		fin:    header[0]&0x80 != 0,
+		opcode: header[0] & 0x0F,
+	}
+
+	if header[0]&0x70 != 0 {
+		return frame{}, 0, mask, protocolError("reserved bits set without a negotiated extension")
+	}
+	if header[1]&0x80 == 0 {
+		return frame{}, 0, mask, protocolError("client frames must be masked")
+	}
+
+	length := int64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return frame{}, 0, mask, err
+		}
+		length = int64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return frame{}, 0, mask, err
+		}
+		if extended[0]&0x80 != 0 {
+			return frame{}, 0, mask, protocolError("invalid payload length")
+		}
+		length = int64(binary.BigEndian.Uint64(extended[:]))
+	}
+
+	if f.opcode >= opClose && (!f.fin || length > maxControlPayload) {
+		return frame{}, 0, mask, protocolError("control frames must be unfragmented and at most 125 bytes")
+	}
+
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return frame{}, 0, mask, err
+	}
+
+	return f, length, mask, nil
+}
+
+// writeFrame sends one unmasked, unfragmented frame
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+	if opcode == opClose {
+		c.closeSent = true
+	}
+
+	if c.config.WriteTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+
+	length := len(payload)
+	switch {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	if _, err := c.writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.writer.Write(payload); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// validCloseCode reports whether a client may send code in a close frame
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003:
+		return true
+	case code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
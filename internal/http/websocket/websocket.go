@@ -0,0 +1,196 @@
+// Package websocket implements the server side of the WebSocket protocol
+// (RFC 6455) on top of net/http, without extensions.
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// acceptGUID is the fixed GUID from RFC 6455 section 1.3
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// ErrBadHandshake is returned when the request is not a valid WebSocket upgrade
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+
+	// ErrOriginNotAllowed is returned when the Origin header fails the origin check
+	ErrOriginNotAllowed = errors.New("websocket: origin not allowed")
+
+	// ErrMessageTooLarge is returned when a message exceeds Config.MaxMessageSize
+	ErrMessageTooLarge = errors.New("websocket: message too large")
+
+	// ErrClosed is returned when writing to a connection that has been closed
+	ErrClosed = errors.New("websocket: connection closed")
+)
+
+// Config controls the upgrade and the behaviour of accepted connections
+type Config struct {
+	// MaxMessageSize is the largest message, after reassembling fragments,
+	// that will be read. Larger messages close the connection with 1009.
+	// Zero uses the default of 1 MiB.
+	MaxMessageSize int64
+
+	// PingInterval is how often a ping is sent to keep the connection
+	// alive. Zero disables keepalive pings.
+	PingInterval time.Duration
+
+	// PongTimeout is how long the connection may stay silent before it is
+	// considered dead. Any frame from the client, including pongs, resets it.
+	PongTimeout time.Duration
+
+	// WriteTimeout bounds each frame write
+	WriteTimeout time.Duration
+
+	// Subprotocols lists the supported subprotocols in order of preference
+	Subprotocols []string
+
+	// AllowedOrigins lists the origins, such as "https://app.example.com",
+	// allowed besides the request's own host. "*" allows any origin.
+	AllowedOrigins []string
+
+	// CheckOrigin replaces the default same-origin check when set. Rejecting
+	// cross-site origins protects against cross-site WebSocket hijacking.
+	CheckOrigin func(r *http.Request) bool
+}
+
+// DefaultConfig returns the settings used when none are given
+func DefaultConfig() Config {
+	return Config{
+		MaxMessageSize: 1 << 20,
+		PingInterval:   30 * time.Second,
+		PongTimeout:    60 * time.Second,
+		WriteTimeout:   10 * time.Second,
+	}
+}
+
+// IsUpgradeRequest reports whether r asks to switch to the WebSocket protocol
+func IsUpgradeRequest(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade completes the opening handshake and takes over the connection.
+// On failure an HTTP error response has already been written.
+func Upgrade(w http.ResponseWriter, r *http.Request, config Config) (*Conn, error) {
+	if r.Method != http.MethodGet || !IsUpgradeRequest(r) {
+		w.Header().Set("Upgrade", "websocket")
+		w.Header().Set("Connection", "Upgrade")
+		http.Error(w, "Upgrade Required", http.StatusUpgradeRequired)
+		return nil, ErrBadHandshake
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+
+	if !config.originAllowed(r) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return nil, ErrOriginNotAllowed
+	}
+
+	subprotocol := config.negotiateSubprotocol(r)
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket upgrade not supported", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	// Clear the deadlines the HTTP server set for the request
+	netConn.SetDeadline(time.Time{})
+
+	var response strings.Builder
+	response.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	response.WriteString("Upgrade: websocket\r\n")
+	response.WriteString("Connection: Upgrade\r\n")
+	response.WriteString("Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		response.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	response.WriteString("\r\n")
+
+	if _, err := rw.WriteString(response.String()); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	return newConn(netConn, rw.Reader, rw.Writer, subprotocol, config), nil
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept value for a client key
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// originAllowed applies CheckOrigin or the default same-origin policy.
+// Requests without an Origin header come from non-browser clients and are allowed.
+func (c Config) originAllowed(r *http.Request) bool {
+	if c.CheckOrigin != nil {
+		return c.CheckOrigin(r)
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsed.Host, r.Host)
+}
+
+// negotiateSubprotocol picks the first configured subprotocol the client offers
+func (c Config) negotiateSubprotocol(r *http.Request) string {
+	offered := make(map[string]bool)
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			offered[strings.TrimSpace(protocol)] = true
+		}
+	}
+
+	for _, protocol := range c.Subprotocols {
+		if offered[protocol] {
+			return protocol
+		}
+	}
+	return ""
+}
+
+// headerContainsToken reports whether a comma-separated header contains token
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,420 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClient speaks just enough of the client side of RFC 6455 for tests
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dial(t *testing.T, server *httptest.Server, headers map[string]string) (*testClient, *http.Response) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET /ws HTTP/1.1\r\n" +
+		"Host: " + server.Listener.Addr().String() + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n"
+	for name, value := range headers {
+		request += name + ": " + value + "\r\n"
+	}
+	request += "\r\n"
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatalf("handshake write failed: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("handshake read failed: %v", err)
+	}
+
+	return &testClient{conn: conn, reader: reader}, resp
+}
+
+func (tc *testClient) writeFrame(t *testing.T, fin bool, opcode byte, payload []byte, masked bool) {
+	t.Helper()
+
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	header := []byte{first, 0}
+
+	switch {
+	case len(payload) <= 125:
+		header[1] = byte(len(payload))
+	case len(payload) <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+
+	body := append([]byte(nil), payload...)
+	if masked {
+		header[1] |= 0x80
+		mask := []byte{0x12, 0x34, 0x56, 0x78}
+		header = append(header, mask...)
+		for i := range body {
+			body[i] ^= mask[i%4]
+		}
+	}
+
+	if _, err := tc.conn.Write(append(header, body...)); err != nil {
+		t.Fatalf("frame write failed: %v", err)
+	}
+}
+
+func (tc *testClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+
+	var header [2]byte
+	if _, err := tc.reader.Read(header[:1]); err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+	if _, err := tc.reader.Read(header[1:]); err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+
+	length := int(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		readFull(t, tc.reader, extended[:])
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		readFull(t, tc.reader, extended[:])
+		length = int(binary.BigEndian.Uint64(extended[:]))
+	}
+
+	payload := make([]byte, length)
+	readFull(t, tc.reader, payload)
+	return header[0] & 0x0F, payload
+}
+
+func readFull(t *testing.T, r *bufio.Reader, buf []byte) {
+	t.Helper()
+	for read := 0; read < len(buf); {
+		n, err := r.Read(buf[read:])
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		read += n
+	}
+}
+
+func closeCode(payload []byte) int {
+	if len(payload) < 2 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+// newEchoServer echoes every message back until the connection closes and
+// reports the error that ended the read loop
+func newEchoServer(t *testing.T, config Config) (*httptest.Server, chan error) {
+	t.Helper()
+
+	result := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, config)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				result <- err
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				result <- err
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, result
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept key %q", got)
+	}
+}
+
+func TestUpgradeHandshake(t *testing.T) {
+	config := DefaultConfig()
+	config.Subprotocols = []string{"chat.v2", "chat.v1"}
+	server, _ := newEchoServer(t, config)
+
+	client, resp := dial(t, server, map[string]string{"Sec-WebSocket-Protocol": "chat.v1, chat.v2"})
+	defer client.conn.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected Sec-WebSocket-Accept %q", resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+	if resp.Header.Get("Sec-WebSocket-Protocol") != "chat.v2" {
+		t.Errorf("expected server preference chat.v2, got %q", resp.Header.Get("Sec-WebSocket-Protocol"))
+	}
+}
+
+func TestUpgradeRejections(t *testing.T) {
+	config := DefaultConfig()
+	config.AllowedOrigins = []string{"https://app.example.com"}
+
+	tests := []struct {
+		name   string
+		setup  func(r *http.Request)
+		status int
+		err    error
+	}{
+		{"plain request", func(r *http.Request) {
+			r.Header.Del("Upgrade")
+		}, http.StatusUpgradeRequired, ErrBadHandshake},
+		{"wrong version", func(r *http.Request) {
+			r.Header.Set("Sec-WebSocket-Version", "8")
+		}, http.StatusBadRequest, ErrBadHandshake},
+		{"bad key", func(r *http.Request) {
+			r.Header.Set("Sec-WebSocket-Key", "short")
+		}, http.StatusBadRequest, ErrBadHandshake},
+		{"cross-site origin", func(r *http.Request) {
+			r.Header.Set("Origin", "https://evil.example.com")
+		}, http.StatusForbidden, ErrOriginNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/ws", nil)
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Connection", "keep-alive, Upgrade")
+			req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+			req.Header.Set("Sec-WebSocket-Version", "13")
+			tt.setup(req)
+			w := httptest.NewRecorder()
+
+			if _, err := Upgrade(w, req, config); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestOriginCheck(t *testing.T) {
+	config := Config{AllowedOrigins: []string{"https://app.example.com"}}
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},
+		{"http://example.com", true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "http://example.com/ws", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if got := config.originAllowed(req); got != tt.allowed {
+			t.Errorf("origin %q: expected %v, got %v", tt.origin, tt.allowed, got)
+		}
+	}
+}
+
+func TestEchoMessages(t *testing.T) {
+	server, _ := newEchoServer(t, DefaultConfig())
+	client, _ := dial(t, server, nil)
+	defer client.conn.Close()
+
+	client.writeFrame(t, true, opText, []byte("hello"), true)
+	if opcode, payload := client.readFrame(t); opcode != opText || string(payload) != "hello" {
+		t.Errorf("expected text echo, got opcode %d %q", opcode, payload)
+	}
+
+	large := []byte(strings.Repeat("x", 70000))
+	client.writeFrame(t, true, opBinary, large, true)
+	if opcode, payload := client.readFrame(t); opcode != opBinary || len(payload) != len(large) {
+		t.Errorf("expected binary echo of %d bytes, got opcode %d with %d bytes", len(large), opcode, len(payload))
+	}
+
+	// A fragmented message with a ping in the middle
+	client.writeFrame(t, false, opText, []byte("frag"), true)
+	client.writeFrame(t, true, opPing, []byte("p"), true)
+	client.writeFrame(t, true, opContinuation, []byte("mented"), true)
+
+	if opcode, payload := client.readFrame(t); opcode != opPong || string(payload) != "p" {
+		t.Errorf("expected pong with ping payload, got opcode %d %q", opcode, payload)
+	}
+	if opcode, payload := client.readFrame(t); opcode != opText || string(payload) != "fragmented" {
+		t.Errorf("expected reassembled message, got opcode %d %q", opcode, payload)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	server, result := newEchoServer(t, DefaultConfig())
+	client, _ := dial(t, server, nil)
+	defer client.conn.Close()
+
+	payload := binary.BigEndian.AppendUint16(nil, CloseGoingAway)
+	client.writeFrame(t, true, opClose, append(payload, "bye"...), true)
+
+	opcode, reply := client.readFrame(t)
+	if opcode != opClose || closeCode(reply) != CloseGoingAway {
+		t.Errorf("expected close reply with code 1001, got opcode %d code %d", opcode, closeCode(reply))
+	}
+
+	var closeErr *CloseError
+	if err := <-result; !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway || closeErr.Reason != "bye" {
+		t.Errorf("expected CloseError 1001 bye, got %v", err)
+	}
+}
+
+func TestProtocolViolations(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxMessageSize = 16
+
+	tests := []struct {
+		name string
+		send func(t *testing.T, client *testClient)
+		code int
+	}{
+		{"unmasked frame", func(t *testing.T, client *testClient) {
+			client.writeFrame(t, true, opText, []byte("hi"), false)
+		}, CloseProtocolError},
+		{"message too large", func(t *testing.T, client *testClient) {
+			client.writeFrame(t, true, opBinary, make([]byte, 17), true)
+		}, CloseMessageTooBig},
+		{"fragments too large", func(t *testing.T, client *testClient) {
+			client.writeFrame(t, false, opBinary, make([]byte, 10), true)
+			client.writeFrame(t, true, opContinuation, make([]byte, 10), true)
+		}, CloseMessageTooBig},
+		{"invalid utf-8", func(t *testing.T, client *testClient) {
+			client.writeFrame(t, true, opText, []byte{0xff, 0xfe}, true)
+		}, CloseInvalidPayload},
+		{"orphan continuation", func(t *testing.T, client *testClient) {
+			client.writeFrame(t, true, opContinuation, []byte("x"), true)
+		}, CloseProtocolError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, result := newEchoServer(t, config)
+			client, _ := dial(t, server, nil)
+			defer client.conn.Close()
+
+			tt.send(t, client)
+
+			opcode, payload := client.readFrame(t)
+			if opcode != opClose || closeCode(payload) != tt.code {
+				t.Errorf("expected close code %d, got opcode %d code %d", tt.code, opcode, closeCode(payload))
+			}
+			if err := <-result; err == nil {
+				t.Error("expected ReadMessage to fail")
+			}
+		})
+	}
+}
+
+func TestHugeFrameLength(t *testing.T) {
+	// A zero config must still cap what a client can make the server allocate
+	server, result := newEchoServer(t, Config{})
+	client, _ := dial(t, server, nil)
+	defer client.conn.Close()
+
+	header := []byte{0x80 | opBinary, 0x80 | 127}
+	header = binary.BigEndian.AppendUint64(header, 1<<62)
+	header = append(header, 0x12, 0x34, 0x56, 0x78)
+	if _, err := client.conn.Write(header); err != nil {
+		t.Fatalf("frame write failed: %v", err)
+	}
+
+	opcode, payload := client.readFrame(t)
+	if opcode != opClose || closeCode(payload) != CloseMessageTooBig {
+		t.Errorf("expected close code %d, got opcode %d code %d", CloseMessageTooBig, opcode, closeCode(payload))
+	}
+	if err := <-result; !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+}
+
+func TestKeepalivePing(t *testing.T) {
+	config := DefaultConfig()
+	config.PingInterval = 20 * time.Millisecond
+	server, _ := newEchoServer(t, config)
+
+	client, _ := dial(t, server, nil)
+	defer client.conn.Close()
+
+	if opcode, _ := client.readFrame(t); opcode != opPing {
+		t.Errorf("expected keepalive ping, got opcode %d", opcode)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, DefaultConfig())
+		if err != nil {
+			done <- err
+			return
+		}
+		conn.WriteText("goodbye")
+		done <- conn.CloseWithReason(ClosePolicyViolation, "policy")
+	}))
+	defer server.Close()
+
+	client, _ := dial(t, server, nil)
+	defer client.conn.Close()
+
+	if _, payload := client.readFrame(t); string(payload) != "goodbye" {
+		t.Errorf("expected message before close, got %q", payload)
+	}
+
+	opcode, payload := client.readFrame(t)
+	if opcode != opClose || closeCode(payload) != ClosePolicyViolation || string(payload[2:]) != "policy" {
+		t.Errorf("expected close 1008 policy, got opcode %d %q", opcode, payload)
+	}
+
+	// Data sent before the client's close is skipped, whatever its size
+	client.writeFrame(t, true, opBinary, make([]byte, 2<<20), true)
+	client.writeFrame(t, true, opClose, payload[:2], true)
+
+	if err := <-done; err != nil {
+		t.Errorf("expected clean close, got %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"runtime"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // channel represents a logging channel with specific configuration
@@ -134,24 +136,39 @@ func (c *channel) mergeContextWithRequestContext(ctx context.Context, contexts .
 	merged := c.mergeContext(contexts...)
 	
 	// Extract request context if available
-	if ctx != nil {
-		if requestID := ctx.Value("request_id"); requestID != nil {
-			merged["request_id"] = requestID
-		}
-		if userID := ctx.Value("user_id"); userID != nil {
-			merged["user_id"] = userID
-		}
-		if traceID := ctx.Value("trace_id"); traceID != nil {
-			merged["trace_id"] = traceID
-		}
-		if spanID := ctx.Value("span_id"); spanID != nil {
-			merged["span_id"] = spanID
-		}
+	for k, v := range requestContext(ctx) {
+		merged[k] = v
 	}
 	
 	return merged
 }
 
+// requestContext returns the request ID, user ID and trace identifiers
+// carried by ctx. Values set by the tracing package take precedence over
+// plain "request_id", "trace_id" and "span_id" context keys.
+func requestContext(ctx context.Context) map[string]interface{} {
+	values := make(map[string]interface{})
+	if ctx == nil {
+		return values
+	}
+	
+	for _, key := range []string{"request_id", "user_id", "trace_id", "span_id"} {
+		if value := ctx.Value(key); value != nil {
+			values[key] = value
+		}
+	}
+	
+	if requestID := tracing.RequestID(ctx); requestID != "" {
+		values["request_id"] = requestID
+	}
+	if sc, ok := tracing.SpanContextFrom(ctx); ok {
+		values["trace_id"] = sc.TraceID
+		values["span_id"] = sc.SpanID
+	}
+	
+	return values
+}
+
 func (c *channel) getExtraInfo() map[string]interface{} {
 	extra := make(map[string]interface{})
 	
@@ -126,13 +126,12 @@ func (fd *FileDriver) Write(ctx context.Context, entry LogEntry) error {
 	}
 	
 	// Add context values if available
-	if ctx != nil {
-		if requestID := ctx.Value("request_id"); requestID != nil {
-			logData["request_id"] = requestID
-		}
-		if traceID := ctx.Value("trace_id"); traceID != nil {
-			logData["trace_id"] = traceID
-		}
+	values := requestContext(ctx)
+	if requestID, ok := values["request_id"]; ok {
+		logData["request_id"] = requestID
+	}
+	if traceID, ok := values["trace_id"]; ok {
+		logData["trace_id"] = traceID
 	}
 	
 	jsonData, err := json.Marshal(logData)
@@ -242,24 +241,8 @@ func (jd *JSONDriver) Write(ctx context.Context, entry LogEntry) error {
 	}
 	
 	// Add context values if available
-	if ctx != nil {
-		contextData := make(map[string]interface{})
-		if requestID := ctx.Value("request_id"); requestID != nil {
-			contextData["request_id"] = requestID
-		}
-		if userID := ctx.Value("user_id"); userID != nil {
-			contextData["user_id"] = userID
-		}
-		if traceID := ctx.Value("trace_id"); traceID != nil {
-			contextData["trace_id"] = traceID
-		}
-		if spanID := ctx.Value("span_id"); spanID != nil {
-			contextData["span_id"] = spanID
-		}
-		
-		if len(contextData) > 0 {
-			logData["request_context"] = contextData
-		}
+	if contextData := requestContext(ctx); len(contextData) > 0 {
+		logData["request_context"] = contextData
 	}
 	
 	jsonData, err := json.Marshal(logData)
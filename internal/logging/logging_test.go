@@ -3,10 +3,13 @@ package logging
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 func TestLogLevels(t *testing.T) {
@@ -313,4 +316,33 @@ func TestFileDriver(t *testing.T) {
 	if !strings.Contains(string(content), "test file message") {
 		t.Errorf("Expected file content to contain 'test file message', got: %s", string(content))
 	}
-}
\ No newline at end of file
+}
+
+func TestChannelTraceContext(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	jsonDriver := NewJSONDriver(buffer)
+	
+	manager := NewManager()
+	manager.AddChannel("test", jsonDriver, DebugLevel)
+	
+	sc := tracing.NewSpanContext()
+	ctx := tracing.WithRequestID(context.Background(), "req-123")
+	ctx = tracing.WithSpanContext(ctx, sc)
+	
+	manager.Channel("test").InfoContext(ctx, "traced")
+	
+	var entry struct {
+		Context        map[string]interface{} `json:"context"`
+		RequestContext map[string]interface{} `json:"request_context"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+	
+	if entry.Context["request_id"] != "req-123" || entry.Context["trace_id"] != sc.TraceID || entry.Context["span_id"] != sc.SpanID {
+		t.Errorf("Expected request and trace IDs in the entry context, got %v", entry.Context)
+	}
+	if entry.RequestContext["trace_id"] != sc.TraceID {
+		t.Errorf("Expected trace ID in the request context, got %v", entry.RequestContext)
+	}
+}
@@ -1,8 +1,11 @@
 package mail
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 func TestAddress_String(t *testing.T) {
@@ -518,4 +521,30 @@ func contains(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+func TestManager_TraceHeaders(t *testing.T) {
+	manager := NewManager(DefaultConfig())
+	mailable := NewMailable().
+		From("sender@example.com", "Sender").
+		To("recipient@example.com", "Recipient").
+		Subject("Traced").
+		Text("Body").
+		Header("X-Custom", "custom-value")
+	
+	span := tracing.NewSpanContext()
+	ctx := tracing.WithSpanContext(tracing.WithRequestID(context.Background(), "req-123"), span)
+	
+	message, err := manager.buildMessage(ctx, mailable)
+	if err != nil {
+		t.Fatalf("Failed to build message: %v", err)
+	}
+	
+	headers := message.Envelope.Headers
+	if headers["X-Request-ID"] != "req-123" || headers["Traceparent"] != span.Traceparent() || headers["X-Custom"] != "custom-value" {
+		t.Errorf("Expected trace headers alongside custom headers, got %v", headers)
+	}
+	if _, exists := mailable.Envelope().Headers["Traceparent"]; exists {
+		t.Error("Expected the mailable's own headers to be left unchanged")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // DefaultManager implements the Manager interface
@@ -103,12 +105,19 @@ func (m *DefaultManager) Send(ctx context.Context, mailable Mailable, driverName
 	
 	driverNameStr := driver.GetName()
 	
+	ctx, span := tracing.Start(ctx, "mail.send", tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(map[string]interface{}{
+		"mail.driver": driverNameStr,
+	}))
+	defer span.End()
+	
 	// Check rate limiting
 	if m.config.RateLimit.Enabled {
 		if rateLimiter, exists := m.rateLimiters[driverNameStr]; exists {
 			if !rateLimiter.Allow() {
 				m.stats.RecordRateLimitHit(driverNameStr)
-				return fmt.Errorf("rate limit exceeded for driver %s", driverNameStr)
+				err := fmt.Errorf("rate limit exceeded for driver %s", driverNameStr)
+				span.RecordError(err)
+				return err
 			}
 		}
 	}
@@ -117,6 +126,7 @@ func (m *DefaultManager) Send(ctx context.Context, mailable Mailable, driverName
 	message, err := m.buildMessage(ctx, mailable)
 	if err != nil {
 		m.stats.RecordFailure(driverNameStr, time.Since(startTime))
+		span.RecordError(err)
 		return fmt.Errorf("failed to build message: %w", err)
 	}
 	
@@ -129,6 +139,7 @@ func (m *DefaultManager) Send(ctx context.Context, mailable Mailable, driverName
 	
 	if err != nil {
 		m.stats.RecordFailure(driverNameStr, duration)
+		span.RecordError(err)
 		if m.logger != nil {
 			m.logger.LogFailed(ctx, message, driverNameStr, err, duration)
 		}
@@ -351,6 +362,20 @@ func (m *DefaultManager) buildMessage(ctx context.Context, mailable Mailable) (*
 		message.Envelope.From = &m.config.From
 	}
 	
+	// Forward the request ID and trace context so the message can be linked
+	// to the request or job that sent it, without changing the mailable
+	if headers := tracing.Headers(ctx); len(headers) > 0 {
+		envelope := *message.Envelope
+		envelope.Headers = make(map[string]string, len(envelope.Headers)+len(headers))
+		for key, value := range message.Envelope.Headers {
+			envelope.Headers[key] = value
+		}
+		for key, value := range headers {
+			envelope.Headers[key] = value
+		}
+		message.Envelope = &envelope
+	}
+	
 	// Render templates if needed
 	if err := m.renderContent(ctx, message, mailable); err != nil {
 		return nil, fmt.Errorf("failed to render content: %w", err)
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes every family in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	for _, f := range r.Gather() {
+		writeFamily(buf, f)
+	}
+	return buf.Flush()
+}
+
+// Handler serves the registry in the Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteText(w)
+	})
+}
+
+// writeFamily writes the HELP and TYPE lines and the samples of f
+func writeFamily(w *bufio.Writer, f Family) {
+	if f.Help != "" {
+		w.WriteString("# HELP " + f.Name + " " + helpEscaper.Replace(f.Help) + "\n")
+	}
+	w.WriteString("# TYPE " + f.Name + " " + f.Type.String() + "\n")
+
+	samples := append([]Sample(nil), f.Samples...)
+	sort.Slice(samples, func(i, j int) bool {
+		return formatLabels(samples[i].Labels, "", 0) < formatLabels(samples[j].Labels, "", 0)
+	})
+
+	for _, s := range samples {
+		if f.Type != HistogramType {
+			writeSample(w, f.Name, formatLabels(s.Labels, "", 0), s.Value)
+			continue
+		}
+		for _, b := range s.Buckets {
+			writeSample(w, f.Name+"_bucket", formatLabels(s.Labels, "le", b.UpperBound), float64(b.Count))
+		}
+		writeSample(w, f.Name+"_bucket", formatLabels(s.Labels, "le", math.Inf(1)), float64(s.Count))
+		writeSample(w, f.Name+"_sum", formatLabels(s.Labels, "", 0), s.Sum)
+		writeSample(w, f.Name+"_count", formatLabels(s.Labels, "", 0), float64(s.Count))
+	}
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	w.WriteString(name + labels + " " + formatValue(value) + "\n")
+}
+
+// formatLabels renders labels sorted by name, adding the extra label when
+// it is named, e.g. a histogram bucket's "le"
+func formatLabels(labels map[string]string, extra string, extraValue float64) string {
+	if len(labels) == 0 && extra == "" {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name + `="` + labelEscaper.Replace(labels[name]) + `"`)
+	}
+	if extra != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extra + `="` + formatValue(extraValue) + `"`)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// formatValue renders a sample value, spelling infinities the Prometheus way
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
@@ -0,0 +1,409 @@
+// Package metrics provides counters, gauges and histograms that the
+// framework's subsystems report into, and serves them in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Type is the kind of a metric family
+type Type int
+
+const (
+	CounterType Type = iota
+	GaugeType
+	HistogramType
+)
+
+// String returns the type as written on a # TYPE line
+func (t Type) String() string {
+	switch t {
+	case CounterType:
+		return "counter"
+	case GaugeType:
+		return "gauge"
+	case HistogramType:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// DefaultBuckets are histogram upper bounds, in seconds, suited to request
+// and query latencies
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Family is a gathered metric with all of its labelled samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Sample is one labelled value of a family. Histogram samples carry their
+// buckets, count and sum instead of Value.
+type Sample struct {
+	Labels  map[string]string
+	Value   float64
+	Buckets []Bucket
+	Count   uint64
+	Sum     float64
+}
+
+// Bucket is the cumulative number of observations up to UpperBound
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Collector produces families when the registry is gathered, for values
+// that are already kept elsewhere, such as connection pool statistics
+type Collector interface {
+	Collect() []Family
+}
+
+// CollectorFunc adapts a function to the Collector interface
+type CollectorFunc func() []Family
+
+// Collect calls f
+func (f CollectorFunc) Collect() []Family {
+	return f()
+}
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Registry holds metric families and collectors
+type Registry struct {
+	families   map[string]*family
+	collectors map[string]Collector
+	mutex      sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		families:   make(map[string]*family),
+		collectors: make(map[string]Collector),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the registry the framework reports into
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// NewCounter returns the counter family name, creating it on first use.
+// It panics if name is already registered with another type or labels.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{r.family(name, help, CounterType, nil, labelNames)}
+}
+
+// NewGauge returns the gauge family name, creating it on first use
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{r.family(name, help, GaugeType, nil, labelNames)}
+}
+
+// NewHistogram returns the histogram family name, creating it on first use.
+// Nil buckets mean DefaultBuckets.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{r.family(name, help, HistogramType, buckets, labelNames)}
+}
+
+// Register adds a collector under name, replacing any collector registered
+// under the same name before
+func (r *Registry) Register(name string, collector Collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors[name] = collector
+}
+
+// Unregister removes the collector registered under name
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.collectors, name)
+}
+
+// Gather returns every family sorted by name. Families of the same name from
+// several collectors are merged.
+func (r *Registry) Gather() []Family {
+	r.mutex.RLock()
+	gathered := make(map[string]*Family, len(r.families))
+	for name, f := range r.families {
+		gathered[name] = f.gather()
+	}
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, collector := range r.collectors {
+		collectors = append(collectors, collector)
+	}
+	r.mutex.RUnlock()
+
+	for _, collector := range collectors {
+		for _, collected := range collector.Collect() {
+			if existing, ok := gathered[collected.Name]; ok && existing.Type == collected.Type {
+				existing.Samples = append(existing.Samples, collected.Samples...)
+				continue
+			}
+			collected := collected
+			gathered[collected.Name] = &collected
+		}
+	}
+
+	families := make([]Family, 0, len(gathered))
+	for _, f := range gathered {
+		if len(f.Samples) > 0 {
+			families = append(families, *f)
+		}
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
+
+// family looks up or creates a family, checking that it is used consistently
+func (r *Registry) family(name, help string, kind Type, buckets []float64, labelNames []string) *family {
+	if !metricNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, label := range labelNames {
+		if !labelNamePattern.MatchString(label) || label == "le" {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", label, name))
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || strings.Join(f.labelNames, ",") != strings.Join(labelNames, ",") {
+			panic(fmt.Sprintf("metrics: %s is already registered as a %s with labels %v", name, f.kind, f.labelNames))
+		}
+		return f
+	}
+
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: append([]string(nil), labelNames...),
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+	r.families[name] = f
+	return f
+}
+
+// family holds the series of one metric, keyed by their label values
+type family struct {
+	name       string
+	help       string
+	kind       Type
+	labelNames []string
+	buckets    []float64
+	series     map[string]*series
+	mutex      sync.RWMutex
+}
+
+// series is one combination of label values
+type series struct {
+	labelValues []string
+	value       atomicFloat
+	histogram   *Histogram
+}
+
+// with returns the series for labelValues, creating it on first use
+func (f *family) with(labelValues []string) *series {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	f.mutex.RLock()
+	s, ok := f.series[key]
+	f.mutex.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s
+	}
+	s = &series{labelValues: append([]string(nil), labelValues...)}
+	if f.kind == HistogramType {
+		s.histogram = &Histogram{bounds: f.buckets, counts: make([]uint64, len(f.buckets))}
+	}
+	f.series[key] = s
+	return s
+}
+
+// gather snapshots the family
+func (f *family) gather() *Family {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	gathered := &Family{Name: f.name, Help: f.help, Type: f.kind, Samples: make([]Sample, 0, len(f.series))}
+	for _, s := range f.series {
+		sample := Sample{Labels: make(map[string]string, len(f.labelNames))}
+		for i, name := range f.labelNames {
+			sample.Labels[name] = s.labelValues[i]
+		}
+		if s.histogram != nil {
+			sample.Buckets, sample.Count, sample.Sum = s.histogram.snapshot()
+		} else {
+			sample.Value = s.value.Load()
+		}
+		gathered.Samples = append(gathered.Samples, sample)
+	}
+	return gathered
+}
+
+// CounterVec is a counter family partitioned by labels
+type CounterVec struct {
+	family *family
+}
+
+// With returns the counter for the label values, in registration order
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	return &Counter{v.family.with(labelValues)}
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	series *series
+}
+
+// Inc adds one
+func (c *Counter) Inc() {
+	c.series.value.Add(1)
+}
+
+// Add adds delta, which must not be negative
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.series.value.Add(delta)
+}
+
+// GaugeVec is a gauge family partitioned by labels
+type GaugeVec struct {
+	family *family
+}
+
+// With returns the gauge for the label values, in registration order
+func (v *GaugeVec) With(labelValues ...string) *Gauge {
+	return &Gauge{v.family.with(labelValues)}
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	series *series
+}
+
+// Set replaces the value
+func (g *Gauge) Set(value float64) {
+	g.series.value.Store(value)
+}
+
+// Add adds delta, which may be negative
+func (g *Gauge) Add(delta float64) {
+	g.series.value.Add(delta)
+}
+
+// Inc adds one
+func (g *Gauge) Inc() {
+	g.series.value.Add(1)
+}
+
+// Dec subtracts one
+func (g *Gauge) Dec() {
+	g.series.value.Add(-1)
+}
+
+// HistogramVec is a histogram family partitioned by labels
+type HistogramVec struct {
+	family *family
+}
+
+// With returns the histogram for the label values, in registration order
+func (v *HistogramVec) With(labelValues ...string) *Histogram {
+	return v.family.with(labelValues).histogram
+}
+
+// Histogram counts observations into buckets
+type Histogram struct {
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    float64
+	mutex  sync.Mutex
+}
+
+// Observe records a value, e.g. a duration in seconds
+func (h *Histogram) Observe(value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if i := sort.SearchFloat64s(h.bounds, value); i < len(h.bounds) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += value
+}
+
+// snapshot returns the cumulative buckets, count and sum
+func (h *Histogram) snapshot() ([]Bucket, uint64, float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	buckets := make([]Bucket, len(h.bounds))
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	return buckets, h.count, h.sum
+}
+
+// atomicFloat is a float64 updated without locks
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *atomicFloat) Store(value float64) {
+	f.bits.Store(math.Float64bits(value))
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
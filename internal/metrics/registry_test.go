@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounter("http_requests_total", "Requests handled.", "method", "code")
+	requests.With("GET", "200").Inc()
+	requests.With("GET", "200").Add(2)
+	requests.With("POST", "500").Inc()
+
+	r.NewGauge("queue_depth", "Jobs waiting.\nPer queue.", "queue").With(`say "hi"`).Set(4)
+
+	latency := r.NewHistogram("latency_seconds", "", []float64{0.5, 0.1}, "route")
+	latency.With("/users/{id}").Observe(0.05)
+	latency.With("/users/{id}").Observe(0.3)
+	latency.With("/users/{id}").Observe(2)
+
+	r.Register("pool", CollectorFunc(func() []Family {
+		return []Family{{Name: "pool_open", Help: "Open connections.", Type: GaugeType, Samples: []Sample{
+			{Labels: map[string]string{"db": "main"}, Value: 3},
+		}}}
+	}))
+
+	// Families that were never used are left out
+	r.NewCounter("unused_total", "Never incremented.")
+
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+
+	expected := `# HELP http_requests_total Requests handled.
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="GET"} 3
+http_requests_total{code="500",method="POST"} 1
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/users/{id}",le="0.1"} 1
+latency_seconds_bucket{route="/users/{id}",le="0.5"} 2
+latency_seconds_bucket{route="/users/{id}",le="+Inf"} 3
+latency_seconds_sum{route="/users/{id}"} 2.35
+latency_seconds_count{route="/users/{id}"} 3
+# HELP pool_open Open connections.
+# TYPE pool_open gauge
+pool_open{db="main"} 3
+# HELP queue_depth Jobs waiting.\nPer queue.
+# TYPE queue_depth gauge
+queue_depth{queue="say \"hi\""} 4
+`
+	if out.String() != expected {
+		t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRegistry_ReusesFamilies(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("jobs_total", "Jobs.", "queue").With("emails").Inc()
+	r.NewCounter("jobs_total", "Jobs.", "queue").With("emails").Inc()
+
+	families := r.Gather()
+	if len(families) != 1 || families[0].Samples[0].Value != 2 {
+		t.Fatalf("expected one family counting 2, got %+v", families)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic when reusing a name with another type")
+		}
+	}()
+	r.NewGauge("jobs_total", "Jobs.", "queue")
+}
+
+func TestRegistry_MergesCollectors(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"main", "replica"} {
+		name := name
+		r.Register("db:"+name, CollectorFunc(func() []Family {
+			return []Family{{Name: "pool_open", Type: GaugeType, Samples: []Sample{
+				{Labels: map[string]string{"db": name}, Value: 1},
+			}}}
+		}))
+	}
+	r.Register("db:main", CollectorFunc(func() []Family {
+		return []Family{{Name: "pool_open", Type: GaugeType, Samples: []Sample{
+			{Labels: map[string]string{"db": "main"}, Value: 5},
+		}}}
+	}))
+
+	families := r.Gather()
+	if len(families) != 1 || len(families[0].Samples) != 2 {
+		t.Fatalf("expected one family with a sample per database, got %+v", families)
+	}
+
+	r.Unregister("db:replica")
+	if families := r.Gather(); len(families[0].Samples) != 1 || families[0].Samples[0].Value != 5 {
+		t.Errorf("expected only the replaced main collector, got %+v", families)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("hits_total", "Hits.").With().Inc()
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if w.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Content-Type = %q", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "hits_total 1\n") {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
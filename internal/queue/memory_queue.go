@@ -6,6 +6,8 @@ import (
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // MemoryQueue implements an in-memory queue
@@ -52,15 +54,24 @@ func (mq *MemoryQueue) LaterOn(ctx context.Context, queue string, delay time.Dur
 	default:
 	}
 
+	id := GenerateJobID()
+	ctx, span := tracing.Start(ctx, queue+" publish", tracing.WithKind(tracing.SpanKindProducer), tracing.WithAttributes(map[string]interface{}{
+		"messaging.system":           "onyx",
+		"messaging.operation.type":   "publish",
+		"messaging.destination.name": queue,
+		"messaging.message.id":       id,
+	}))
+	defer span.End()
+
 	payload := &JobPayload{
-		ID:          GenerateJobID(),
+		ID:          id,
 		DisplayName: fmt.Sprintf("%T", job),
 		Job:         fmt.Sprintf("%T", job),
 		MaxTries:    job.GetMaxTries(),
 		Timeout:     int(job.GetTimeout().Seconds()),
 		Priority:    job.GetPriority(),
 		Data:        job.GetPayload(),
-		Metadata:    job.GetMetadata(),
+		Metadata:    tracing.InjectMetadata(ctx, job.GetMetadata()),
 		Queue:       queue,
 		Attempts:    0,
 		CreatedAt:   time.Now(),
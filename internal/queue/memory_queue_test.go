@@ -2,8 +2,11 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 func TestMemoryQueue_PushPop(t *testing.T) {
@@ -460,4 +463,96 @@ func TestMemoryQueue_MultipleQueues(t *testing.T) {
 	if size != 3 {
 		t.Errorf("Expected queue2 size 3 after clearing queue1, got %d", size)
 	}
-}
\ No newline at end of file
+}
+
+func TestMemoryQueue_TraceContext(t *testing.T) {
+	queue := NewMemoryQueue()
+	defer queue.Close()
+
+	parent := tracing.NewSpanContext()
+	ctx := tracing.WithRequestID(context.Background(), "req-123")
+	ctx = tracing.WithSpanContext(ctx, parent)
+
+	job := NewCallbackJob("traced", func(ctx context.Context) error {
+		return nil
+	})
+	job.WithMetadata("source", "test")
+
+	if err := queue.Push(ctx, job); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if _, exists := job.GetMetadata()[tracing.TraceparentKey]; exists {
+		t.Error("Expected the queued job's own metadata to be left unchanged")
+	}
+
+	popped, err := queue.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to pop job: %v", err)
+	}
+
+	metadata := popped.GetMetadata()
+	if metadata["source"] != "test" || metadata[tracing.RequestIDKey] != "req-123" || metadata[tracing.TraceparentKey] != parent.Traceparent() {
+		t.Errorf("Expected request ID and traceparent in metadata, got %v", metadata)
+	}
+
+	// The worker restores them for the job
+	var handled context.Context
+	worker := NewWorker("trace")
+	worker.middleware = []Middleware{MiddlewareFunc(func(ctx context.Context, job Job, next func(ctx context.Context, job Job) error) error {
+		handled = ctx
+		return nil
+	})}
+	worker.processJob(context.Background(), popped)
+
+	if tracing.RequestID(handled) != "req-123" {
+		t.Errorf("Expected request ID to be restored, got %q", tracing.RequestID(handled))
+	}
+	sc, ok := tracing.SpanContextFrom(handled)
+	if !ok || sc.TraceID != parent.TraceID || sc.ParentID != parent.SpanID {
+		t.Errorf("Expected a child span of %+v, got %+v", parent, sc)
+	}
+}
+
+func TestMemoryQueue_Spans(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(exporter)
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	queue := NewMemoryQueue()
+	defer queue.Close()
+
+	job := NewCallbackJob("traced", func(ctx context.Context) error {
+		return errors.New("job failed")
+	})
+	if err := queue.PushOn(context.Background(), "emails", job); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	popped, err := queue.Pop(context.Background(), "emails")
+	if err != nil {
+		t.Fatalf("Failed to pop job: %v", err)
+	}
+	NewWorker("spans").processJob(context.Background(), popped)
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down tracer: %v", err)
+	}
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	publish, process := spans[0], spans[1]
+	if publish.Name != "emails publish" || publish.Kind != tracing.SpanKindProducer {
+		t.Errorf("Expected a producer span, got %q kind %d", publish.Name, publish.Kind)
+	}
+	if process.Name != "emails process" || process.Kind != tracing.SpanKindConsumer {
+		t.Errorf("Expected a consumer span, got %q kind %d", process.Name, process.Kind)
+	}
+	if process.SpanContext.TraceID != publish.SpanContext.TraceID || process.SpanContext.ParentID == "" {
+		t.Errorf("Expected the consumer span in the producer's trace, got %+v and %+v", publish.SpanContext, process.SpanContext)
+	}
+	if process.Status != tracing.StatusError {
+		t.Errorf("Expected the job failure to be recorded on the consumer span")
+	}
+}
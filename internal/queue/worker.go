@@ -5,6 +5,15 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/metrics"
+	"github.com/onyx-go/framework/internal/tracing"
+)
+
+// Job metrics reported to the metrics registry, summed over every worker
+var (
+	jobsTotal   = metrics.Default().NewCounter("onyx_queue_jobs_total", "Jobs handled by workers, by result.", "queue", "result")
+	jobDuration = metrics.Default().NewHistogram("onyx_queue_job_duration_seconds", "Time spent handling jobs.", nil, "queue")
 )
 
 // DefaultWorker implements the Worker interface
@@ -139,6 +148,15 @@ func (w *DefaultWorker) processNextJob(ctx context.Context) bool {
 func (w *DefaultWorker) processJob(ctx context.Context, job Job) bool {
 	startTime := time.Now()
 	
+	// Restore the request ID and trace context of whoever queued the job
+	ctx = tracing.ExtractMetadata(ctx, job.GetMetadata())
+	ctx, span := tracing.Start(ctx, job.GetQueue()+" process", tracing.WithKind(tracing.SpanKindConsumer), tracing.WithAttributes(map[string]interface{}{
+		"messaging.system":           "onyx",
+		"messaging.operation.type":   "process",
+		"messaging.destination.name": job.GetQueue(),
+	}))
+	defer span.End()
+	
 	// Update current job in stats
 	w.mutex.Lock()
 	if queueJob, ok := job.(*QueueJob); ok {
@@ -148,6 +166,7 @@ func (w *DefaultWorker) processJob(ctx context.Context, job Job) bool {
 
 	defer func() {
 		duration := time.Since(startTime)
+		jobDuration.With(job.GetQueue()).Observe(duration.Seconds())
 		
 		w.mutex.Lock()
 		w.stats.CurrentJob = nil
@@ -173,6 +192,7 @@ func (w *DefaultWorker) processJob(ctx context.Context, job Job) bool {
 	defer func() {
 		if r := recover(); r != nil {
 			err := fmt.Errorf("job panicked: %v", r)
+			span.RecordError(err)
 			w.handleJobFailure(ctx, job, err)
 			
 			if w.options.Logger != nil {
@@ -194,6 +214,7 @@ func (w *DefaultWorker) processJob(ctx context.Context, job Job) bool {
 	err := w.executeJobWithMiddleware(jobCtx, job)
 	
 	if err != nil {
+		span.RecordError(err)
 		w.handleJobFailure(ctx, job, err)
 		return false
 	}
@@ -202,6 +223,7 @@ func (w *DefaultWorker) processJob(ctx context.Context, job Job) bool {
 	w.mutex.Lock()
 	w.stats.ProcessedJobs++
 	w.mutex.Unlock()
+	jobsTotal.With(job.GetQueue(), "processed").Inc()
 
 	return true
 }
@@ -234,6 +256,7 @@ func (w *DefaultWorker) handleJobFailure(ctx context.Context, job Job, err error
 	w.mutex.Lock()
 	w.stats.FailedJobs++
 	w.mutex.Unlock()
+	jobsTotal.With(job.GetQueue(), "failed").Inc()
 
 	// Update queue stats if it's a memory queue
 	if memQueue, ok := w.queue.(*MemoryQueue); ok {
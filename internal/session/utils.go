@@ -84,6 +84,11 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	rw.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // ContextWithSession creates a new context with session
 func ContextWithSession(ctx context.Context, session Session) context.Context {
 	return context.WithValue(ctx, ContextKeySession, session)
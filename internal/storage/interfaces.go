@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"io"
+	"mime/multipart"
 	"os"
 	"time"
 )
@@ -250,6 +251,9 @@ type UploadedFile struct {
 	Content      io.Reader              `json:"-"`
 	Headers      map[string][]string    `json:"headers,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	
+	// header is the multipart header the file can be reopened from
+	header *multipart.FileHeader
 }
 
 // Stats holds storage statistics
@@ -334,6 +338,11 @@ type StoreOptions struct {
 // Upload options
 type UploadOption func(*UploadOptions)
 
+// UploadOptions configures DefaultUploader.Upload. Content is always
+// validated against its sniffed MIME type, so ValidateContent has no further
+// effect. Image processing, thumbnails and virus scanning are not provided by
+// DefaultUploader; requesting them fails with ErrUploadOptionUnsupported, and
+// they can be added with OnBeforeUpload and OnAfterUpload hooks instead.
 type UploadOptions struct {
 	StoreOptions
 	ProcessImage    bool
@@ -457,4 +466,16 @@ func WithContentValidation(validate bool) UploadOption {
 	return func(opts *UploadOptions) {
 		opts.ValidateContent = validate
 	}
-}
\ No newline at end of file
+}
+
+func WithUploadVisibility(visibility Visibility) UploadOption {
+	return func(opts *UploadOptions) {
+		opts.Visibility = visibility
+	}
+}
+
+func WithUploadMetadata(metadata map[string]string) UploadOption {
+	return func(opts *UploadOptions) {
+		opts.Metadata = metadata
+	}
+}
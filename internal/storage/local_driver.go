@@ -640,6 +640,11 @@ func (ld *LocalDriver) GetConfig() Config {
 	return ld.config
 }
 
+// Root returns the directory files are stored under
+func (ld *LocalDriver) Root() string {
+	return ld.rootPath
+}
+
 // HealthCheck checks if the driver is healthy
 func (ld *LocalDriver) HealthCheck(ctx context.Context) error {
 	select {
@@ -6,6 +6,8 @@ import (
 	"io"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // DefaultManager implements the Manager interface
@@ -255,9 +257,13 @@ type instrumentedDriver struct {
 
 // Override methods to collect statistics
 func (id *instrumentedDriver) Put(ctx context.Context, path string, contents []byte) error {
+	ctx, span := id.startSpan(ctx, "put", path)
+	defer span.End()
+	
 	start := time.Now()
 	err := id.Driver.Put(ctx, path, contents)
 	duration := time.Since(start)
+	span.RecordError(err)
 	
 	if err != nil {
 		id.stats.RecordError(id.name, "Put", duration)
@@ -269,9 +275,13 @@ func (id *instrumentedDriver) Put(ctx context.Context, path string, contents []b
 }
 
 func (id *instrumentedDriver) Get(ctx context.Context, path string) ([]byte, error) {
+	ctx, span := id.startSpan(ctx, "get", path)
+	defer span.End()
+	
 	start := time.Now()
 	data, err := id.Driver.Get(ctx, path)
 	duration := time.Since(start)
+	span.RecordError(err)
 	
 	if err != nil {
 		id.stats.RecordError(id.name, "Get", duration)
@@ -283,9 +293,13 @@ func (id *instrumentedDriver) Get(ctx context.Context, path string) ([]byte, err
 }
 
 func (id *instrumentedDriver) Delete(ctx context.Context, path string) error {
+	ctx, span := id.startSpan(ctx, "delete", path)
+	defer span.End()
+	
 	start := time.Now()
 	err := id.Driver.Delete(ctx, path)
 	duration := time.Since(start)
+	span.RecordError(err)
 	
 	if err != nil {
 		id.stats.RecordError(id.name, "Delete", duration)
@@ -296,6 +310,14 @@ func (id *instrumentedDriver) Delete(ctx context.Context, path string) error {
 	return err
 }
 
+// startSpan starts a span for a storage operation on path
+func (id *instrumentedDriver) startSpan(ctx context.Context, operation, path string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "storage."+operation, tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(map[string]interface{}{
+		"storage.disk": id.name,
+		"storage.path": path,
+	}))
+}
+
 // nullDriver is a driver that always returns errors
 type nullDriver struct {
 	name string
@@ -0,0 +1,459 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrFileTooLarge is returned when an upload exceeds the maximum file size
+	ErrFileTooLarge = errors.New("file too large")
+
+	// ErrFileTypeNotAllowed is returned when the sniffed MIME type is not allowed
+	ErrFileTypeNotAllowed = errors.New("file type not allowed")
+
+	// ErrExtensionNotAllowed is returned when the file extension is blocked or not allowed
+	ErrExtensionNotAllowed = errors.New("file extension not allowed")
+
+	// ErrContentMismatch is returned when the file content does not match its extension
+	ErrContentMismatch = errors.New("file content does not match its extension")
+
+	// ErrUploadOptionUnsupported is returned when an upload asks for
+	// processing the uploader does not provide
+	ErrUploadOptionUnsupported = errors.New("upload option not supported")
+)
+
+// sniffLength is how many bytes MIME type detection looks at
+const sniffLength = 512
+
+// sniffableTypes are the types whose content can be recognised reliably, so a
+// file claiming one of them by extension must actually contain it
+var sniffableTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/bmp":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"application/zip": true,
+	"audio/mpeg":      true,
+	"audio/wav":       true,
+	"video/mp4":       true,
+}
+
+// NewUploadedFile describes a file from a parsed multipart form. The content
+// is opened on demand, so the file can be validated and stored repeatedly.
+// MimeType holds the type the client declared, which is not trusted.
+func NewUploadedFile(fieldName string, header *multipart.FileHeader) *UploadedFile {
+	return &UploadedFile{
+		FieldName:    fieldName,
+		OriginalName: header.Filename,
+		Size:         header.Size,
+		MimeType:     header.Header.Get("Content-Type"),
+		Extension:    strings.ToLower(filepath.Ext(header.Filename)),
+		Headers:      header.Header,
+		Metadata:     make(map[string]interface{}),
+		header:       header,
+	}
+}
+
+// NewStreamedFile describes a file part read straight from a multipart
+// stream. Its size is unknown (-1) and its content can only be read once.
+func NewStreamedFile(part *multipart.Part) *UploadedFile {
+	return &UploadedFile{
+		FieldName:    part.FormName(),
+		OriginalName: part.FileName(),
+		Size:         -1,
+		MimeType:     part.Header.Get("Content-Type"),
+		Extension:    strings.ToLower(filepath.Ext(part.FileName())),
+		Content:      part,
+		Headers:      part.Header,
+		Metadata:     make(map[string]interface{}),
+	}
+}
+
+// Open returns a reader for the file's content
+func (f UploadedFile) Open() (io.ReadCloser, error) {
+	switch {
+	case f.header != nil:
+		return f.header.Open()
+	case f.TempPath != "":
+		return os.Open(f.TempPath)
+	case f.Content != nil:
+		if seeker, ok := f.Content.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		return io.NopCloser(f.Content), nil
+	}
+	return nil, errors.New("uploaded file has no content")
+}
+
+// rereadable reports whether the content can be read more than once
+func (f UploadedFile) rereadable() bool {
+	if f.header != nil || f.TempPath != "" {
+		return true
+	}
+	_, ok := f.Content.(io.Seeker)
+	return ok
+}
+
+// DetectMimeType sniffs the MIME type of r from its first bytes. The returned
+// reader yields the full content, including the bytes that were sniffed.
+func DetectMimeType(r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		mimeType = "application/octet-stream"
+	}
+
+	return mimeType, io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// DefaultUploader implements the Uploader interface on top of a Driver
+type DefaultUploader struct {
+	driver            Driver
+	maxFileSize       int64
+	allowedTypes      []string
+	allowedExtensions []string
+	blockedExtensions []string
+	validators        []UploadValidator
+	beforeHooks       []func(ctx context.Context, file UploadedFile) error
+	afterHooks        []func(ctx context.Context, file UploadedFile, info *FileInfo) error
+	mutex             sync.RWMutex
+}
+
+// NewUploader creates an uploader that stores files through driver
+func NewUploader(driver Driver) *DefaultUploader {
+	return &DefaultUploader{
+		driver:            driver,
+		maxFileSize:       10 << 20, // 10MB default
+		blockedExtensions: []string{".exe", ".bat", ".cmd", ".scr"},
+	}
+}
+
+// NewUploaderFromConfig creates an uploader using the upload settings of a manager config
+func NewUploaderFromConfig(driver Driver, config *ManagerConfig) *DefaultUploader {
+	uploader := NewUploader(driver)
+	if config.MaxFileSize > 0 {
+		uploader.maxFileSize = config.MaxFileSize
+	}
+	uploader.allowedTypes = config.AllowedTypes
+	uploader.allowedExtensions = config.AllowedExtensions
+	if config.BlockedExtensions != nil {
+		uploader.blockedExtensions = config.BlockedExtensions
+	}
+	return uploader
+}
+
+// Uploader returns an uploader for the named driver using the manager's upload settings
+func (m *DefaultManager) Uploader(driverName string) (*DefaultUploader, error) {
+	if driverName == "" {
+		driverName = m.GetDefaultDriver()
+	}
+
+	driver, err := m.GetDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUploaderFromConfig(driver, m.config), nil
+}
+
+// Upload validates file and stores it under destination with a unique name.
+// The content is streamed to the driver; its MIME type is sniffed from the
+// content rather than taken from the client, and the size limit is enforced
+// while streaming, so files of unknown size are safe to upload.
+func (u *DefaultUploader) Upload(ctx context.Context, file UploadedFile, destination string, options ...UploadOption) (*FileInfo, error) {
+	opts := &UploadOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	if err := checkUploadOptions(opts); err != nil {
+		return nil, err
+	}
+
+	if err := u.validate(ctx, file); err != nil {
+		return nil, err
+	}
+
+	u.mutex.RLock()
+	beforeHooks := u.beforeHooks
+	afterHooks := u.afterHooks
+	maxFileSize := u.maxFileSize
+	u.mutex.RUnlock()
+
+	for _, hook := range beforeHooks {
+		if err := hook(ctx, file); err != nil {
+			return nil, err
+		}
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer reader.Close()
+
+	mimeType, content, err := DetectMimeType(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	if err := u.checkMimeType(file.OriginalName, mimeType); err != nil {
+		return nil, err
+	}
+
+	name := GenerateUniqueFilename(SanitizeFilename(file.OriginalName))
+	storedPath := strings.TrimPrefix(path.Join(destination, name), "/")
+
+	limited := &limitedReader{reader: content, limit: maxFileSize}
+	hash := sha256.New()
+
+	metadata := make(map[string]string, len(opts.Metadata)+1)
+	for key, value := range opts.Metadata {
+		metadata[key] = value
+	}
+	metadata["original_name"] = file.OriginalName
+
+	if err := u.driver.PutFileWithMetadata(ctx, storedPath, io.TeeReader(limited, hash), metadata); err != nil {
+		u.driver.Delete(ctx, storedPath)
+		if errors.Is(err, ErrFileTooLarge) {
+			return nil, fmt.Errorf("%w: %s exceeds %s", ErrFileTooLarge, file.OriginalName, FormatSize(maxFileSize))
+		}
+		return nil, fmt.Errorf("failed to store uploaded file: %w", err)
+	}
+
+	if opts.Visibility != "" {
+		if err := u.driver.SetVisibility(ctx, storedPath, opts.Visibility); err != nil {
+			return nil, fmt.Errorf("failed to set visibility: %w", err)
+		}
+	}
+
+	if opts.ContentType != "" {
+		mimeType = opts.ContentType
+	}
+
+	info := &FileInfo{
+		Path:       storedPath,
+		Name:       name,
+		Size:       limited.read,
+		MimeType:   mimeType,
+		Extension:  strings.ToLower(filepath.Ext(name)),
+		ModTime:    time.Now(),
+		Visibility: opts.Visibility,
+		Checksum:   hex.EncodeToString(hash.Sum(nil)),
+		Metadata:   metadata,
+	}
+	if url, err := u.driver.URL(storedPath); err == nil {
+		info.URL = url
+	}
+
+	for _, hook := range afterHooks {
+		if err := hook(ctx, file, info); err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+// checkUploadOptions rejects options Upload cannot honour, rather than
+// storing the file as if they had been applied
+func checkUploadOptions(opts *UploadOptions) error {
+	switch {
+	case opts.ProcessImage:
+		return fmt.Errorf("%w: image processing", ErrUploadOptionUnsupported)
+	case opts.GenerateThumbnail:
+		return fmt.Errorf("%w: thumbnail generation", ErrUploadOptionUnsupported)
+	case opts.ScanVirus:
+		return fmt.Errorf("%w: virus scanning", ErrUploadOptionUnsupported)
+	}
+	return nil
+}
+
+// UploadMultiple validates every file before storing any of them. If one
+// upload fails, the files already stored are deleted again.
+func (u *DefaultUploader) UploadMultiple(ctx context.Context, files []UploadedFile, destination string, options ...UploadOption) ([]FileInfo, error) {
+	for _, file := range files {
+		if err := u.validate(ctx, file); err != nil {
+			return nil, fmt.Errorf("%s: %w", file.OriginalName, err)
+		}
+	}
+
+	infos := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		info, err := u.Upload(ctx, file, destination, options...)
+		if err != nil {
+			for _, stored := range infos {
+				u.driver.Delete(ctx, stored.Path)
+			}
+			return nil, fmt.Errorf("%s: %w", file.OriginalName, err)
+		}
+		infos = append(infos, *info)
+	}
+
+	return infos, nil
+}
+
+// Validate checks the file's size, extension and sniffed MIME type, and runs
+// the registered validators. Content that can only be read once is sniffed
+// during Upload instead.
+func (u *DefaultUploader) Validate(file UploadedFile) error {
+	return u.validate(context.Background(), file)
+}
+
+// ValidateMultiple validates each file, stopping at the first failure
+func (u *DefaultUploader) ValidateMultiple(files []UploadedFile) error {
+	for _, file := range files {
+		if err := u.Validate(file); err != nil {
+			return fmt.Errorf("%s: %w", file.OriginalName, err)
+		}
+	}
+	return nil
+}
+
+func (u *DefaultUploader) validate(ctx context.Context, file UploadedFile) error {
+	u.mutex.RLock()
+	maxFileSize := u.maxFileSize
+	allowedExtensions := u.allowedExtensions
+	blockedExtensions := u.blockedExtensions
+	validators := u.validators
+	u.mutex.RUnlock()
+
+	if maxFileSize > 0 && file.Size > maxFileSize {
+		return fmt.Errorf("%w: %s exceeds %s", ErrFileTooLarge, FormatSize(file.Size), FormatSize(maxFileSize))
+	}
+
+	extension := filepath.Ext(file.OriginalName)
+	if IsBlockedExtension(file.OriginalName, blockedExtensions) || !IsAllowedExtension(file.OriginalName, allowedExtensions) {
+		return fmt.Errorf("%w: %q", ErrExtensionNotAllowed, extension)
+	}
+
+	if file.rereadable() {
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open uploaded file: %w", err)
+		}
+		mimeType, _, err := DetectMimeType(reader)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read uploaded file: %w", err)
+		}
+		if err := u.checkMimeType(file.OriginalName, mimeType); err != nil {
+			return err
+		}
+	}
+
+	for _, validator := range validators {
+		if err := validator.Validate(ctx, file); err != nil {
+			return fmt.Errorf("%s: %w", validator.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkMimeType checks a sniffed MIME type against the allowed types and the
+// type the file's extension promises
+func (u *DefaultUploader) checkMimeType(filename, mimeType string) error {
+	u.mutex.RLock()
+	allowedTypes := u.allowedTypes
+	u.mutex.RUnlock()
+
+	if !IsAllowedMimeType(mimeType, allowedTypes) {
+		return fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, mimeType)
+	}
+
+	if expected := GetMimeTypeFromExtension(filename); sniffableTypes[expected] && expected != mimeType {
+		return fmt.Errorf("%w: %s contains %s", ErrContentMismatch, filepath.Ext(filename), mimeType)
+	}
+
+	return nil
+}
+
+// SetMaxFileSize sets the maximum file size in bytes; zero removes the limit
+func (u *DefaultUploader) SetMaxFileSize(size int64) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.maxFileSize = size
+}
+
+// SetAllowedTypes sets the allowed MIME types, such as "image/*"
+func (u *DefaultUploader) SetAllowedTypes(types []string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.allowedTypes = types
+}
+
+// SetAllowedExtensions sets the allowed file extensions, such as ".png"
+func (u *DefaultUploader) SetAllowedExtensions(extensions []string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.allowedExtensions = extensions
+}
+
+// SetBlockedExtensions sets the file extensions that are always rejected
+func (u *DefaultUploader) SetBlockedExtensions(extensions []string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.blockedExtensions = extensions
+}
+
+// AddValidator adds a custom validator run for every upload
+func (u *DefaultUploader) AddValidator(validator UploadValidator) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.validators = append(u.validators, validator)
+}
+
+// OnBeforeUpload registers a hook run after validation and before storing
+func (u *DefaultUploader) OnBeforeUpload(hook func(ctx context.Context, file UploadedFile) error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.beforeHooks = append(u.beforeHooks, hook)
+}
+
+// OnAfterUpload registers a hook run once a file has been stored
+func (u *DefaultUploader) OnAfterUpload(hook func(ctx context.Context, file UploadedFile, info *FileInfo) error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.afterHooks = append(u.afterHooks, hook)
+}
+
+// limitedReader counts what it reads and fails once more than limit bytes
+// have been read
+type limitedReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.limit > 0 && l.read > l.limit {
+		return n, ErrFileTooLarge
+	}
+	return n, err
+}
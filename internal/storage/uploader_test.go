@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestUploader(t *testing.T) (*DefaultUploader, *LocalDriver) {
+	t.Helper()
+
+	config := DefaultConfig()
+	config.LocalPath = t.TempDir()
+	config.BaseURL = "/files"
+
+	driver, err := NewLocalDriver("uploads", config)
+	if err != nil {
+		t.Fatalf("Failed to create local driver: %v", err)
+	}
+	return NewUploader(driver), driver
+}
+
+// multipartFiles parses a multipart form holding the given files under "upload"
+func multipartFiles(t *testing.T, files map[string][]byte) []*multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, _ := writer.CreateFormFile("upload", name)
+		part.Write(content)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("Failed to parse multipart form: %v", err)
+	}
+	return req.MultipartForm.File["upload"]
+}
+
+func TestDetectMimeType(t *testing.T) {
+	mimeType, reader, err := DetectMimeType(bytes.NewReader(append(pngHeader, "rest"...)))
+	if err != nil {
+		t.Fatalf("DetectMimeType failed: %v", err)
+	}
+	if mimeType != "image/png" {
+		t.Errorf("Expected image/png, got %s", mimeType)
+	}
+
+	var content bytes.Buffer
+	content.ReadFrom(reader)
+	if !bytes.HasSuffix(content.Bytes(), []byte("rest")) || content.Len() != len(pngHeader)+4 {
+		t.Errorf("Expected the full content to be readable after sniffing, got %q", content.String())
+	}
+
+	if mimeType, _, _ := DetectMimeType(strings.NewReader("hello")); mimeType != "text/plain" {
+		t.Errorf("Expected text/plain without parameters, got %s", mimeType)
+	}
+}
+
+func TestUploader_Upload(t *testing.T) {
+	uploader, driver := newTestUploader(t)
+	uploader.SetAllowedTypes([]string{"image/*"})
+
+	var before, after int
+	uploader.OnBeforeUpload(func(ctx context.Context, file UploadedFile) error {
+		before++
+		return nil
+	})
+	uploader.OnAfterUpload(func(ctx context.Context, file UploadedFile, info *FileInfo) error {
+		after++
+		return nil
+	})
+
+	header := multipartFiles(t, map[string][]byte{"my photo.png": pngHeader})[0]
+	file := NewUploadedFile("upload", header)
+	file.MimeType = "application/x-msdownload" // Declared types are ignored
+
+	info, err := uploader.Upload(context.Background(), *file, "avatars", WithUploadVisibility(VisibilityPublic))
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	if !strings.HasPrefix(info.Path, "avatars/my_photo_") || info.Extension != ".png" {
+		t.Errorf("Unexpected stored path %s", info.Path)
+	}
+	if info.MimeType != "image/png" || info.Size != int64(len(pngHeader)) || info.Checksum == "" {
+		t.Errorf("Unexpected file info %+v", info)
+	}
+	if info.URL != "/files/"+info.Path || info.Visibility != VisibilityPublic {
+		t.Errorf("Unexpected URL or visibility %s %s", info.URL, info.Visibility)
+	}
+	if before != 1 || after != 1 {
+		t.Errorf("Expected hooks to run once, got before=%d after=%d", before, after)
+	}
+
+	stored, err := driver.Get(context.Background(), info.Path)
+	if err != nil || !bytes.Equal(stored, pngHeader) {
+		t.Errorf("Expected stored content to match, got %q %v", stored, err)
+	}
+
+	// Processing the uploader cannot do is refused rather than skipped
+	if _, err := uploader.Upload(context.Background(), *file, "avatars", WithThumbnailGeneration(true)); !errors.Is(err, ErrUploadOptionUnsupported) {
+		t.Errorf("Expected ErrUploadOptionUnsupported, got %v", err)
+	}
+	if before != 1 {
+		t.Errorf("Expected the rejected upload not to run hooks, got before=%d", before)
+	}
+}
+
+func TestUploader_Validate(t *testing.T) {
+	uploader, _ := newTestUploader(t)
+	uploader.SetMaxFileSize(64)
+	uploader.SetAllowedTypes([]string{"image/png", "text/plain"})
+
+	headers := map[string]*multipart.FileHeader{}
+	for _, header := range multipartFiles(t, map[string][]byte{
+		"ok.png":      pngHeader,
+		"fake.png":    []byte("<html><script>alert(1)</script></html>"),
+		"notes.txt":   []byte("plain notes"),
+		"page.txt":    []byte("<!DOCTYPE html><html></html>"),
+		"big.txt":     bytes.Repeat([]byte("a"), 65),
+		"setup.exe":   []byte("MZ"),
+		"archive.zip": []byte("PK\x03\x04"),
+	}) {
+		headers[header.Filename] = header
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"ok.png", nil},
+		{"notes.txt", nil},
+		{"fake.png", ErrFileTypeNotAllowed},
+		{"page.txt", ErrFileTypeNotAllowed},
+		{"big.txt", ErrFileTooLarge},
+		{"setup.exe", ErrExtensionNotAllowed},
+		{"archive.zip", ErrFileTypeNotAllowed},
+	}
+
+	for _, tt := range tests {
+		err := uploader.Validate(*NewUploadedFile("upload", headers[tt.name]))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	// A PNG extension on other content is rejected even when the type is allowed
+	uploader.SetAllowedTypes(nil)
+	if err := uploader.Validate(*NewUploadedFile("upload", headers["fake.png"])); !errors.Is(err, ErrContentMismatch) {
+		t.Errorf("Expected ErrContentMismatch, got %v", err)
+	}
+}
+
+func TestUploader_StreamedFileSizeLimit(t *testing.T) {
+	uploader, driver := newTestUploader(t)
+	uploader.SetMaxFileSize(1024)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("upload", "large.txt")
+	part.Write(bytes.Repeat([]byte("a"), 4096))
+	writer.Close()
+
+	reader := multipart.NewReader(&body, writer.Boundary())
+	streamed, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read part: %v", err)
+	}
+
+	file := NewStreamedFile(streamed)
+	if file.Size != -1 || file.OriginalName != "large.txt" {
+		t.Errorf("Unexpected streamed file %+v", file)
+	}
+
+	if _, err := uploader.Upload(context.Background(), *file, "big"); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge, got %v", err)
+	}
+
+	files, _ := driver.AllFiles(context.Background(), "big")
+	if len(files) != 0 {
+		t.Errorf("Expected the partial upload to be removed, found %d files", len(files))
+	}
+}
+
+func TestUploader_UploadMultiple(t *testing.T) {
+	uploader, driver := newTestUploader(t)
+
+	var files []UploadedFile
+	for _, header := range multipartFiles(t, map[string][]byte{"a.txt": []byte("a"), "b.txt": []byte("b")}) {
+		files = append(files, *NewUploadedFile("upload", header))
+	}
+
+	infos, err := uploader.UploadMultiple(context.Background(), files, "docs")
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("UploadMultiple failed: %v", err)
+	}
+
+	// A failing hook on the second file removes the first one again
+	calls := 0
+	uploader.OnBeforeUpload(func(ctx context.Context, file UploadedFile) error {
+		calls++
+		if calls == 2 {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	if _, err := uploader.UploadMultiple(context.Background(), files, "rollback"); err == nil {
+		t.Error("Expected UploadMultiple to fail")
+	}
+	stored, _ := driver.AllFiles(context.Background(), "rollback")
+	if len(stored) != 0 {
+		t.Errorf("Expected earlier uploads to be rolled back, found %d files", len(stored))
+	}
+}
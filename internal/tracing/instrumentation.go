@@ -0,0 +1,34 @@
+package tracing
+
+import "context"
+
+// dbSystems maps database/sql driver names to OpenTelemetry db.system values
+var dbSystems = map[string]string{
+	"postgres": "postgresql",
+	"pgx":      "postgresql",
+	"mysql":    "mysql",
+	"sqlite3":  "sqlite",
+	"sqlite":   "sqlite",
+}
+
+// StartQuery starts a client span for a SQL statement, named like
+// "SELECT users". The statement text is recorded with its placeholders, never
+// with bound values.
+func StartQuery(ctx context.Context, driver, operation, table, query string) (context.Context, *Span) {
+	system, ok := dbSystems[driver]
+	if !ok {
+		system = driver
+	}
+
+	name := operation
+	if table != "" {
+		name += " " + table
+	}
+
+	return Start(ctx, name, WithKind(SpanKindClient), WithAttributes(map[string]interface{}{
+		"db.system":          system,
+		"db.operation.name":  operation,
+		"db.collection.name": table,
+		"db.query.text":      query,
+	}))
+}
@@ -0,0 +1,281 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultOTLPEndpoint is the traces endpoint of a collector on this machine
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// instrumentationScope names the framework as the source of its spans
+const instrumentationScope = "github.com/onyx-go/framework"
+
+// OTLPConfig configures an OTLPExporter
+type OTLPConfig struct {
+	// Endpoint is the collector URL. A URL without a path, such as
+	// "http://collector:4318", gets "/v1/traces" appended.
+	Endpoint string
+
+	// Headers are sent with every export, e.g. for authentication
+	Headers map[string]string
+
+	// ServiceName identifies this application in the tracing backend
+	ServiceName string
+
+	// ResourceAttributes describe the process, e.g. "deployment.environment"
+	ResourceAttributes map[string]interface{}
+
+	// Timeout bounds each export; 10 seconds by default
+	Timeout time.Duration
+
+	// Client sends the exports; a client with Timeout by default
+	Client *http.Client
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector using OTLP/HTTP
+// with JSON encoding
+type OTLPExporter struct {
+	endpoint string
+	headers  map[string]string
+	resource otlpResource
+	client   *http.Client
+}
+
+// NewOTLPExporter creates an exporter for the collector in config
+func NewOTLPExporter(config OTLPConfig) (*OTLPExporter, error) {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = "/v1/traces"
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+
+	attributes := make(map[string]interface{}, len(config.ResourceAttributes)+1)
+	for key, value := range config.ResourceAttributes {
+		attributes[key] = value
+	}
+	if config.ServiceName != "" {
+		attributes["service.name"] = config.ServiceName
+	}
+
+	return &OTLPExporter{
+		endpoint: parsed.String(),
+		headers:  config.Headers,
+		resource: otlpResource{Attributes: otlpAttributes(attributes)},
+		client:   client,
+	}, nil
+}
+
+// ExportSpans posts the spans to the collector
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector rejected %d spans: %s %s", len(spans), resp.Status, bytes.TrimSpace(message))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Shutdown does nothing; exports are not buffered by the exporter
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// request builds the ExportTraceServiceRequest body for spans
+func (e *OTLPExporter) request(spans []SpanData) otlpRequest {
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           span.SpanContext.TraceID,
+			SpanID:            span.SpanContext.SpanID,
+			ParentSpanID:      span.SpanContext.ParentID,
+			TraceState:        span.SpanContext.State,
+			Name:              span.Name,
+			Kind:              int(span.Kind),
+			StartTimeUnixNano: unixNano(span.StartTime),
+			EndTimeUnixNano:   unixNano(span.EndTime),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: int(span.Status), Message: span.StatusMessage},
+		}
+		for _, event := range span.Events {
+			encoded[i].Events = append(encoded[i].Events, otlpEvent{
+				Name:         event.Name,
+				TimeUnixNano: unixNano(event.Time),
+				Attributes:   otlpAttributes(event.Attributes),
+			})
+		}
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: e.resource,
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: instrumentationScope},
+			Spans: encoded,
+		}},
+	}}}
+}
+
+// The types below mirror the JSON encoding of the OTLP trace protobufs.
+// Trace and span IDs are hex strings and 64-bit integers are decimal strings.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	TraceState        string         `json:"traceState,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	Name         string         `json:"name"`
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// otlpAttributes encodes attributes sorted by key
+func otlpAttributes(attributes map[string]interface{}) []otlpKeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make([]otlpKeyValue, len(keys))
+	for i, key := range keys {
+		encoded[i] = otlpKeyValue{Key: key, Value: otlpValue(attributes[key])}
+	}
+	return encoded
+}
+
+// otlpValue encodes a single attribute value
+func otlpValue(value interface{}) otlpAnyValue {
+	var intValue string
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		intValue = strconv.FormatInt(int64(v), 10)
+	case int32:
+		intValue = strconv.FormatInt(int64(v), 10)
+	case int64:
+		intValue = strconv.FormatInt(v, 10)
+	case uint:
+		intValue = strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		intValue = strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		intValue = strconv.FormatUint(v, 10)
+	case float32:
+		f := float64(v)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+	return otlpAnyValue{IntValue: &intValue}
+}
+
+// unixNano formats t as OTLP nanoseconds since the epoch
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,300 @@
+// Package tracing carries request IDs and W3C trace context
+// (https://www.w3.org/TR/trace-context/) through context.Context and across
+// process boundaries: HTTP headers, queued job metadata and mail headers. It
+// also records spans and exports them to OpenTelemetry-compatible backends.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Header names used to propagate request IDs and trace context
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "Traceparent"
+	TracestateHeader  = "Tracestate"
+)
+
+// Metadata keys used to propagate request IDs and trace context through
+// queued jobs
+const (
+	RequestIDKey   = "request_id"
+	TraceparentKey = "traceparent"
+	TracestateKey  = "tracestate"
+)
+
+// maxRequestIDLength bounds the length of an accepted X-Request-ID
+const maxRequestIDLength = 128
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	// TraceID is the 32 hex digit ID shared by every span of the trace
+	TraceID string
+
+	// SpanID is the 16 hex digit ID of this span
+	SpanID string
+
+	// ParentID is the SpanID of the span that caused this one, if any
+	ParentID string
+
+	// Flags holds the W3C trace flags; bit 0 marks the trace as sampled
+	Flags byte
+
+	// State is the vendor-specific tracestate header, passed on unchanged
+	State string
+}
+
+// NewSpanContext starts a new sampled trace
+func NewSpanContext() SpanContext {
+	return SpanContext{TraceID: randomHex(16), SpanID: randomHex(8), Flags: 0x01}
+}
+
+// Child returns a new span in the same trace whose parent is sc. A zero
+// SpanContext starts a new trace.
+func (sc SpanContext) Child() SpanContext {
+	if !sc.IsValid() {
+		return NewSpanContext()
+	}
+	return SpanContext{
+		TraceID:  sc.TraceID,
+		SpanID:   randomHex(8),
+		ParentID: sc.SpanID,
+		Flags:    sc.Flags,
+		State:    sc.State,
+	}
+}
+
+// IsValid reports whether sc has non-zero trace and span IDs
+func (sc SpanContext) IsValid() bool {
+	return isHexID(sc.TraceID, 32) && isHexID(sc.SpanID, 16)
+}
+
+// IsSampled reports whether the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&0x01 != 0
+}
+
+// Traceparent formats sc as a version 00 traceparent header value
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	return "00-" + sc.TraceID + "-" + sc.SpanID + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent parses a traceparent header value. The returned
+// SpanContext describes the remote span, so its SpanID is the caller's.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+	// Version 00 has exactly four fields; later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || len(version) != 1 {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return SpanContext{}, false
+	}
+
+	sc := SpanContext{TraceID: parts[1], SpanID: parts[2], Flags: flags[0]}
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// NewRequestID generates a random request ID
+func NewRequestID() string {
+	return randomHex(16)
+}
+
+// ValidRequestID reports whether an incoming X-Request-ID is safe to reuse:
+// non-empty, at most 128 characters and limited to printable ASCII without
+// spaces, so it cannot inject anything into headers or log lines.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	spanContextKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, or ""
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithSpanContext returns a copy of ctx carrying sc as the current span
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey, sc)
+}
+
+// SpanContextFrom returns the current span carried by ctx
+func SpanContextFrom(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	sc, ok := ctx.Value(spanContextKey).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Extract reads the request ID and trace context from incoming headers. A
+// missing or malformed value yields "" or a zero SpanContext.
+func Extract(header http.Header) (string, SpanContext) {
+	requestID := header.Get(RequestIDHeader)
+	if !ValidRequestID(requestID) {
+		requestID = ""
+	}
+
+	sc, ok := ParseTraceparent(header.Get(TraceparentHeader))
+	if ok {
+		sc.State = header.Get(TracestateHeader)
+	}
+	return requestID, sc
+}
+
+// Inject writes the request ID and current span carried by ctx to outgoing
+// headers
+func Inject(ctx context.Context, header http.Header) {
+	for key, value := range Headers(ctx) {
+		header.Set(key, value)
+	}
+}
+
+// Headers returns the propagation headers for ctx, keyed by header name
+func Headers(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	if id := RequestID(ctx); id != "" {
+		headers[RequestIDHeader] = id
+	}
+	if sc, ok := SpanContextFrom(ctx); ok {
+		headers[TraceparentHeader] = sc.Traceparent()
+		if sc.State != "" {
+			headers[TracestateHeader] = sc.State
+		}
+	}
+	return headers
+}
+
+// InjectMetadata returns a copy of metadata with the request ID and current
+// span carried by ctx added, for storing alongside a queued job
+func InjectMetadata(ctx context.Context, metadata map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(metadata)+3)
+	for key, value := range metadata {
+		merged[key] = value
+	}
+
+	if id := RequestID(ctx); id != "" {
+		merged[RequestIDKey] = id
+	}
+	if sc, ok := SpanContextFrom(ctx); ok {
+		merged[TraceparentKey] = sc.Traceparent()
+		if sc.State != "" {
+			merged[TracestateKey] = sc.State
+		}
+	}
+	return merged
+}
+
+// ExtractMetadata restores the request ID and trace context stored by
+// InjectMetadata. The job runs in a child span of the one that queued it.
+func ExtractMetadata(ctx context.Context, metadata map[string]interface{}) context.Context {
+	if id, ok := metadata[RequestIDKey].(string); ok && ValidRequestID(id) {
+		ctx = WithRequestID(ctx, id)
+	}
+
+	traceparent, _ := metadata[TraceparentKey].(string)
+	if sc, ok := ParseTraceparent(traceparent); ok {
+		sc.State, _ = metadata[TracestateKey].(string)
+		ctx = WithSpanContext(ctx, sc.Child())
+	}
+	return ctx
+}
+
+// Transport is an http.RoundTripper that forwards the request ID and trace
+// context of each outgoing request's context
+type Transport struct {
+	// Base performs the request; http.DefaultTransport when nil
+	Base http.RoundTripper
+}
+
+// RoundTrip adds the propagation headers and sends the request
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers := Headers(req.Context())
+	if len(headers) > 0 {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// randomHex returns n random bytes hex-encoded, never all zero
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		rand.Read(b)
+		for _, c := range b {
+			if c != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
+
+// isHexID reports whether id is n lowercase hex digits, not all zero
+func isHexID(id string, n int) bool {
+	if len(id) != n {
+		return false
+	}
+	nonZero := false
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c == '0':
+		case c >= '1' && c <= '9', c >= 'a' && c <= 'f':
+			nonZero = true
+		default:
+			return false
+		}
+	}
+	return nonZero
+}
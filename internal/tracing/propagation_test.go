@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		sc, ok := ParseTraceparent(tt.value)
+		if ok != tt.valid {
+			t.Errorf("ParseTraceparent(%q) valid = %v, want %v", tt.value, ok, tt.valid)
+			continue
+		}
+		if ok && tt.value[:2] == "00" && sc.Traceparent() != tt.value {
+			t.Errorf("Traceparent() = %q, want %q", sc.Traceparent(), tt.value)
+		}
+	}
+}
+
+func TestSpanContextChild(t *testing.T) {
+	parent := NewSpanContext()
+	if !parent.IsValid() || !parent.IsSampled() {
+		t.Fatalf("Expected a valid sampled span, got %+v", parent)
+	}
+
+	child := parent.Child()
+	if child.TraceID != parent.TraceID || child.ParentID != parent.SpanID || child.SpanID == parent.SpanID {
+		t.Errorf("Expected a child span of %+v, got %+v", parent, child)
+	}
+
+	root := SpanContext{}.Child()
+	if !root.IsValid() || root.ParentID != "" {
+		t.Errorf("Expected a new trace from a zero span, got %+v", root)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	valid := []string{"abc-123", "0f8fad5b-d9cb-469f-a165-70867728950e"}
+	invalid := []string{"", "has space", "line\nbreak", strings.Repeat("a", 129)}
+
+	for _, id := range valid {
+		if !ValidRequestID(id) {
+			t.Errorf("Expected %q to be valid", id)
+		}
+	}
+	for _, id := range invalid {
+		if ValidRequestID(id) {
+			t.Errorf("Expected %q to be invalid", id)
+		}
+	}
+}
+
+func TestHeaderPropagation(t *testing.T) {
+	incoming := http.Header{}
+	incoming.Set("X-Request-ID", "req-123")
+	incoming.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	incoming.Set("tracestate", "vendor=value")
+
+	requestID, remote := Extract(incoming)
+	if requestID != "req-123" || remote.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || remote.State != "vendor=value" {
+		t.Fatalf("Unexpected extraction: %q %+v", requestID, remote)
+	}
+
+	span := remote.Child()
+	ctx := WithSpanContext(WithRequestID(context.Background(), requestID), span)
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	if outgoing.Get("X-Request-ID") != "req-123" || outgoing.Get("traceparent") != span.Traceparent() || outgoing.Get("tracestate") != "vendor=value" {
+		t.Errorf("Unexpected outgoing headers: %v", outgoing)
+	}
+
+	if headers := Headers(context.Background()); len(headers) != 0 {
+		t.Errorf("Expected no headers without trace context, got %v", headers)
+	}
+}
+
+func TestMetadataPropagation(t *testing.T) {
+	span := NewSpanContext()
+	ctx := WithSpanContext(WithRequestID(context.Background(), "req-123"), span)
+
+	original := map[string]interface{}{"source": "test"}
+	metadata := InjectMetadata(ctx, original)
+	if len(original) != 1 {
+		t.Error("Expected the original metadata to be left unchanged")
+	}
+	if metadata["source"] != "test" || metadata[RequestIDKey] != "req-123" || metadata[TraceparentKey] != span.Traceparent() {
+		t.Fatalf("Unexpected metadata: %v", metadata)
+	}
+
+	restored := ExtractMetadata(context.Background(), metadata)
+	if RequestID(restored) != "req-123" {
+		t.Errorf("Expected request ID to be restored, got %q", RequestID(restored))
+	}
+	sc, ok := SpanContextFrom(restored)
+	if !ok || sc.TraceID != span.TraceID || sc.ParentID != span.SpanID {
+		t.Errorf("Expected a child span of %+v, got %+v", span, sc)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	span := NewSpanContext()
+	ctx := WithSpanContext(WithRequestID(context.Background(), "req-123"), span)
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if received.Get("X-Request-ID") != "req-123" || received.Get("traceparent") != span.Traceparent() {
+		t.Errorf("Expected propagation headers, got %v", received)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+}
@@ -0,0 +1,432 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SpanKind describes the relationship of a span to its parent and children,
+// using the OpenTelemetry values
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota + 1
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// StatusCode is the outcome of a span, using the OpenTelemetry values
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Event is a timestamped annotation on a span
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// SpanData is the recorded state of an ended span, as handed to exporters
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]interface{}
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Exporter sends ended spans to a tracing backend
+type Exporter interface {
+	// ExportSpans sends a batch of spans
+	ExportSpans(ctx context.Context, spans []SpanData) error
+
+	// Shutdown releases the exporter's resources
+	Shutdown(ctx context.Context) error
+}
+
+// Span is an operation being timed. A nil *Span is valid and records
+// nothing, so instrumented code never has to check whether tracing is on.
+type Span struct {
+	tracer *Tracer
+	data   SpanData
+	ended  bool
+	mutex  sync.Mutex
+}
+
+// SpanContext returns the identity of the span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// IsRecording reports whether the span will be exported
+func (s *Span) IsRecording() bool {
+	return s != nil && s.tracer != nil && s.data.SpanContext.IsSampled()
+}
+
+// SetName replaces the span name, e.g. once the route of a request is known
+func (s *Span) SetName(name string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Name = name
+}
+
+// SetAttribute sets a string, bool, integer or float attribute. Other
+// values are exported in their fmt.Sprint form.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]interface{})
+	}
+	s.data.Attributes[key] = value
+}
+
+// AddEvent records a named event at the current time
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attributes})
+}
+
+// SetStatus sets the outcome of the span
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Status = code
+	s.data.StatusMessage = message
+}
+
+// RecordError records err as an exception event and marks the span as
+// failed. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil || !s.IsRecording() {
+		return
+	}
+	s.AddEvent("exception", map[string]interface{}{
+		"exception.type":    fmt.Sprintf("%T", err),
+		"exception.message": err.Error(),
+	})
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End finishes the span and queues it for export. Calls after the first
+// are ignored.
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mutex.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+// StartOption configures a span when it is started
+type StartOption func(*SpanData)
+
+// WithKind sets the kind of the span; spans are internal by default
+func WithKind(kind SpanKind) StartOption {
+	return func(data *SpanData) {
+		data.Kind = kind
+	}
+}
+
+// WithAttributes sets attributes on the span when it starts
+func WithAttributes(attributes map[string]interface{}) StartOption {
+	return func(data *SpanData) {
+		if data.Attributes == nil {
+			data.Attributes = make(map[string]interface{}, len(attributes))
+		}
+		for key, value := range attributes {
+			data.Attributes[key] = value
+		}
+	}
+}
+
+// TracerOption configures a Tracer
+type TracerOption func(*Tracer)
+
+// WithSampleRatio samples the given fraction of new traces, between 0 and 1.
+// Traces continued from a caller follow the caller's decision.
+func WithSampleRatio(ratio float64) TracerOption {
+	return func(t *Tracer) {
+		t.sampleRatio = ratio
+	}
+}
+
+// WithBatchSize sets how many ended spans trigger an export
+func WithBatchSize(size int) TracerOption {
+	return func(t *Tracer) {
+		if size > 0 {
+			t.batchSize = size
+		}
+	}
+}
+
+// WithFlushInterval sets how often pending spans are exported
+func WithFlushInterval(interval time.Duration) TracerOption {
+	return func(t *Tracer) {
+		if interval > 0 {
+			t.flushInterval = interval
+		}
+	}
+}
+
+// WithErrorHandler receives export errors, which are dropped by default
+func WithErrorHandler(handler func(error)) TracerOption {
+	return func(t *Tracer) {
+		t.onError = handler
+	}
+}
+
+// maxQueueSize bounds the spans held while the exporter is slow or failing
+const maxQueueSize = 2048
+
+// exportTimeout bounds a single background export
+const exportTimeout = 10 * time.Second
+
+// Tracer starts spans and exports them in batches in the background
+type Tracer struct {
+	exporter      Exporter
+	sampleRatio   float64
+	batchSize     int
+	flushInterval time.Duration
+	onError       func(error)
+
+	pending []SpanData
+	mutex   sync.Mutex
+	export  sync.Mutex
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	stopped bool
+}
+
+// NewTracer creates a tracer exporting to exporter and starts its
+// background export loop. Call Shutdown to export what is left.
+func NewTracer(exporter Exporter, options ...TracerOption) *Tracer {
+	t := &Tracer{
+		exporter:      exporter,
+		sampleRatio:   1,
+		batchSize:     512,
+		flushInterval: 5 * time.Second,
+		flushCh:       make(chan struct{}, 1),
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+	for _, option := range options {
+		option(t)
+	}
+
+	go t.run()
+	return t
+}
+
+// Start starts a span as a child of the span carried by ctx, or as the root
+// of a new trace, and returns a copy of ctx carrying it
+func (t *Tracer) Start(ctx context.Context, name string, options ...StartOption) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var sc SpanContext
+	if parent, ok := SpanContextFrom(ctx); ok {
+		sc = parent.Child()
+	} else {
+		sc = NewSpanContext()
+		if !t.sample(sc.TraceID) {
+			sc.Flags &^= 0x01
+		}
+	}
+
+	span := &Span{tracer: t, data: SpanData{
+		Name:        name,
+		Kind:        SpanKindInternal,
+		SpanContext: sc,
+		StartTime:   time.Now(),
+	}}
+	for _, option := range options {
+		option(&span.data)
+	}
+
+	return WithSpanContext(ctx, sc), span
+}
+
+// sample decides whether a new trace is recorded. The decision is derived
+// from the trace ID so every service sampling at the same ratio agrees.
+func (t *Tracer) sample(traceID string) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	if t.sampleRatio <= 0 {
+		return false
+	}
+	id, err := hex.DecodeString(traceID)
+	if err != nil || len(id) != 16 {
+		return false
+	}
+	return float64(binary.BigEndian.Uint64(id[8:])>>1) < t.sampleRatio*float64(1<<63)
+}
+
+// enqueue adds an ended span to the next batch
+func (t *Tracer) enqueue(data SpanData) {
+	t.mutex.Lock()
+	if t.stopped || len(t.pending) >= maxQueueSize {
+		t.mutex.Unlock()
+		return
+	}
+	t.pending = append(t.pending, data)
+	full := len(t.pending) >= t.batchSize
+	t.mutex.Unlock()
+
+	if full {
+		select {
+		case t.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports pending spans on every interval or when a batch fills
+func (t *Tracer) run() {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+		case <-t.flushCh:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		if err := t.ForceFlush(ctx); err != nil && t.onError != nil {
+			t.onError(err)
+		}
+		cancel()
+	}
+}
+
+// ForceFlush exports every span ended so far
+func (t *Tracer) ForceFlush(ctx context.Context) error {
+	t.export.Lock()
+	defer t.export.Unlock()
+
+	t.mutex.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mutex.Unlock()
+
+	var errs []error
+	for len(spans) > 0 {
+		n := min(len(spans), t.batchSize)
+		if err := t.exporter.ExportSpans(ctx, spans[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		spans = spans[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown stops the export loop, exports the remaining spans and shuts the
+// exporter down. Spans ended afterwards are dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.stopped {
+		t.mutex.Unlock()
+		return nil
+	}
+	t.stopped = true
+	t.mutex.Unlock()
+
+	close(t.stopCh)
+	<-t.doneCh
+
+	return errors.Join(t.ForceFlush(ctx), t.exporter.Shutdown(ctx))
+}
+
+var (
+	globalTracer *Tracer
+	globalMutex  sync.RWMutex
+)
+
+// SetTracer sets the tracer used by Start. A nil tracer turns tracing off;
+// trace context is still propagated.
+func SetTracer(t *Tracer) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+	globalTracer = t
+}
+
+// GetTracer returns the tracer set with SetTracer, or nil
+func GetTracer() *Tracer {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+	return globalTracer
+}
+
+// Start starts a span with the tracer set by SetTracer. Without one it
+// returns ctx unchanged and a nil span, which is safe to use.
+func Start(ctx context.Context, name string, options ...StartOption) (context.Context, *Span) {
+	t := GetTracer()
+	if t == nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return ctx, nil
+	}
+	return t.Start(ctx, name, options...)
+}
@@ -0,0 +1,217 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracer_ParentChild(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "GET /users/{id}", WithKind(SpanKindServer))
+	_, child := tracer.Start(ctx, "SELECT users", WithKind(SpanKindClient), WithAttributes(map[string]interface{}{
+		"db.system": "postgresql",
+	}))
+	child.RecordError(errors.New("connection reset"))
+	child.End()
+	parent.SetAttribute("http.response.status_code", 200)
+	parent.End()
+	parent.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	db, server := spans[0], spans[1]
+
+	if server.Name != "GET /users/{id}" || server.Kind != SpanKindServer || server.SpanContext.ParentID != "" {
+		t.Errorf("server span = %+v", server)
+	}
+	if server.Attributes["http.response.status_code"] != 200 {
+		t.Errorf("status attribute = %v", server.Attributes["http.response.status_code"])
+	}
+	if db.SpanContext.TraceID != server.SpanContext.TraceID || db.SpanContext.ParentID != server.SpanContext.SpanID {
+		t.Errorf("db span %+v is not a child of %+v", db.SpanContext, server.SpanContext)
+	}
+	if db.Status != StatusError || len(db.Events) != 1 || db.Events[0].Name != "exception" {
+		t.Errorf("db span error not recorded: status %v, events %+v", db.Status, db.Events)
+	}
+	if db.EndTime.Before(db.StartTime) {
+		t.Errorf("db span ends before it starts")
+	}
+}
+
+func TestTracer_ContinuesRemoteTrace(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := tracer.Start(WithSpanContext(context.Background(), remote), "job")
+	span.End()
+	tracer.Shutdown(context.Background())
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	if sc := spans[0].SpanContext; sc.TraceID != remote.TraceID || sc.ParentID != remote.SpanID {
+		t.Errorf("span context = %+v, want child of %+v", sc, remote)
+	}
+}
+
+func TestTracer_Sampling(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, WithSampleRatio(0))
+
+	ctx, span := tracer.Start(context.Background(), "dropped")
+	if span.IsRecording() {
+		t.Error("span is recording with a sample ratio of 0")
+	}
+	if sc, _ := SpanContextFrom(ctx); sc.IsSampled() || !sc.IsValid() {
+		t.Errorf("propagated span context = %+v, want valid and unsampled", sc)
+	}
+	span.SetAttribute("ignored", true)
+	span.End()
+
+	// A sampled parent decides for its children
+	sampled := SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Flags: 0x01}
+	_, child := tracer.Start(WithSpanContext(context.Background(), sampled), "kept")
+	child.End()
+
+	tracer.Shutdown(context.Background())
+	spans := exporter.Spans()
+	if len(spans) != 1 || spans[0].Name != "kept" {
+		t.Errorf("exported %+v, want only the sampled child", spans)
+	}
+}
+
+func TestTracer_FlushesFullBatches(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, WithBatchSize(2))
+	defer tracer.Shutdown(context.Background())
+
+	for i := 0; i < 5; i++ {
+		_, span := tracer.Start(context.Background(), "work")
+		span.End()
+	}
+	if err := tracer.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush: %v", err)
+	}
+	if n := len(exporter.Spans()); n != 5 {
+		t.Errorf("exported %d spans, want 5", n)
+	}
+}
+
+func TestStart_WithoutTracer(t *testing.T) {
+	SetTracer(nil)
+
+	ctx := context.Background()
+	got, span := Start(ctx, "nothing")
+	if got != ctx || span != nil {
+		t.Errorf("Start without a tracer = (%v, %v), want the context unchanged and a nil span", got, span)
+	}
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		body    map[string]interface{}
+		path    string
+		headers http.Header
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, headers = r.URL.Path, r.Header
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("collector received invalid JSON: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(OTLPConfig{
+		Endpoint:    collector.URL,
+		Headers:     map[string]string{"Authorization": "Bearer secret"},
+		ServiceName: "shop",
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter: %v", err)
+	}
+	tracer := NewTracer(exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "GET /orders", WithKind(SpanKindServer))
+	_, child := tracer.Start(ctx, "SELECT orders", WithKind(SpanKindClient), WithAttributes(map[string]interface{}{
+		"db.system": "postgresql",
+		"rows":      3,
+	}))
+	child.End()
+	parent.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if path != "/v1/traces" {
+		t.Errorf("path = %q, want /v1/traces", path)
+	}
+	if headers.Get("Content-Type") != "application/json" || headers.Get("Authorization") != "Bearer secret" {
+		t.Errorf("headers = %v", headers)
+	}
+
+	resourceSpans := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resource := resourceSpans["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	if resource["key"] != "service.name" || resource["value"].(map[string]interface{})["stringValue"] != "shop" {
+		t.Errorf("resource attribute = %v", resource)
+	}
+
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("collector received %d spans, want 2", len(spans))
+	}
+	db := spans[0].(map[string]interface{})
+	server := spans[1].(map[string]interface{})
+	if db["name"] != "SELECT orders" || db["kind"] != float64(SpanKindClient) {
+		t.Errorf("db span = %v", db)
+	}
+	if db["traceId"] != server["traceId"] || db["parentSpanId"] != server["spanId"] {
+		t.Errorf("db span is not a child of the server span: %v, %v", db, server)
+	}
+	if _, ok := db["startTimeUnixNano"].(string); !ok {
+		t.Errorf("startTimeUnixNano = %v, want a decimal string", db["startTimeUnixNano"])
+	}
+	rows := db["attributes"].([]interface{})[1].(map[string]interface{})
+	if rows["key"] != "rows" || rows["value"].(map[string]interface{})["intValue"] != "3" {
+		t.Errorf("rows attribute = %v", rows)
+	}
+}
+
+func TestOTLPExporter_Rejected(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(OTLPConfig{Endpoint: collector.URL + "/custom/traces"})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter: %v", err)
+	}
+	err = exporter.ExportSpans(context.Background(), []SpanData{{Name: "span"}})
+	if err == nil {
+		t.Fatal("expected an error when the collector rejects the export")
+	}
+
+	if _, err := NewOTLPExporter(OTLPConfig{Endpoint: "collector:4318"}); err == nil {
+		t.Error("expected an error for an endpoint without a scheme")
+	}
+}
@@ -800,6 +800,36 @@ func TestValidationErrors(t *testing.T) {
 	}
 }
 
+func TestValidateStruct(t *testing.T) {
+	type signup struct {
+		Name     string  `form:"name" validate:"required|min:3"`
+		Email    *string `json:"email_address" validate:"required|email"`
+		Nickname *string `form:"nickname" validate:"min:2"`
+	}
+	
+	email := "not-an-email"
+	validator := NewValidator(map[string]interface{}{"existing": "data"}, nil)
+	result := validator.ValidateStruct(context.Background(), &signup{Name: "Al", Email: &email})
+	
+	if !result.HasError("name") {
+		t.Errorf("expected name error under its form tag, got %v", result.GetErrors())
+	}
+	if !result.HasError("email_address") {
+		t.Errorf("expected email error through the pointer, got %v", result.GetErrors())
+	}
+	if result.HasError("nickname") {
+		t.Errorf("expected nil optional pointer to be skipped, got %v", result.GetErrors())
+	}
+	if validator.data["existing"] != "data" {
+		t.Error("ValidateStruct should not replace the validator's data")
+	}
+	
+	email = "al@example.com"
+	if result := validator.ValidateStruct(context.Background(), signup{Name: "Alan", Email: &email}); result.Failed() {
+		t.Errorf("expected valid struct, got %v", result.GetErrors())
+	}
+}
+
 func BenchmarkValidation(b *testing.B) {
 	data := map[string]interface{}{
 		"name":  "John Doe",
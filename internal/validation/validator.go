@@ -108,12 +108,12 @@ func (v *DefaultValidator) Validate(ctx context.Context) Result {
 
 // ValidateStruct validates a struct with validation tags
 func (v *DefaultValidator) ValidateStruct(ctx context.Context, s interface{}) Result {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
+	v.mutex.RLock()
 	
 	// Convert struct to map
 	structData, err := structToMap(s)
 	if err != nil {
+		v.mutex.RUnlock()
 		result := NewResult()
 		result.AddError("struct", fmt.Sprintf("failed to parse struct: %v", err))
 		return result
@@ -122,26 +122,27 @@ func (v *DefaultValidator) ValidateStruct(ctx context.Context, s interface{}) Re
 	// Parse validation tags
 	structRules, err := v.parseStructTags(reflect.TypeOf(s))
 	if err != nil {
+		v.mutex.RUnlock()
 		result := NewResult()
 		result.AddError("struct", fmt.Sprintf("failed to parse validation tags: %v", err))
 		return result
 	}
 	
-	// Update validator data and rules
-	oldData := v.data
-	oldRules := v.rules
-	
-	v.data = structData
-	v.rules = structRules
-	
-	// Validate
-	result := v.Validate(ctx)
-	
-	// Restore original data and rules
-	v.data = oldData
-	v.rules = oldRules
+	// Validate with a copy so the validator's own data and rules stay untouched
+	structValidator := &DefaultValidator{
+		data:             structData,
+		rules:            structRules,
+		conditionalRules: make(map[string][]ConditionalRule),
+		customMessages:   v.customMessages,
+		customAttributes: v.customAttributes,
+		options:          v.options,
+		ruleRegistry:     v.ruleRegistry,
+		transformers:     v.transformers,
+		eventListeners:   v.eventListeners,
+	}
+	v.mutex.RUnlock()
 	
-	return result
+	return structValidator.Validate(ctx)
 }
 
 // ValidateField validates a single field
@@ -517,15 +518,7 @@ func (v *DefaultValidator) parseStructTags(t reflect.Type) (map[string][]Rule, e
 			return nil, fmt.Errorf("error parsing tag for field %s: %v", field.Name, err)
 		}
 		
-		// Use json tag for field name if available
-		fieldName := field.Name
-		if jsonTag := field.Tag.Get("json"); jsonTag != "" {
-			if parts := strings.Split(jsonTag, ","); parts[0] != "" {
-				fieldName = parts[0]
-			}
-		}
-		
-		rules[fieldName] = fieldRules
+		rules[fieldName(field)] = fieldRules
 	}
 	
 	return rules, nil
@@ -653,20 +646,37 @@ func structToMap(s interface{}) (map[string]interface{}, error) {
 			continue
 		}
 		
-		// Use json tag for field name if available
-		fieldName := field.Name
-		if jsonTag := field.Tag.Get("json"); jsonTag != "" {
-			if parts := strings.Split(jsonTag, ","); parts[0] != "" {
-				fieldName = parts[0]
+		// Validate what a pointer points to; nil pointers count as empty
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				result[fieldName(field)] = nil
+				continue
 			}
+			fieldValue = fieldValue.Elem()
 		}
 		
-		result[fieldName] = fieldValue.Interface()
+		result[fieldName(field)] = fieldValue.Interface()
 	}
 	
 	return result, nil
 }
 
+// fieldNameTags are the struct tags that name a field, in order of precedence
+var fieldNameTags = []string{"json", "form", "query", "param", "header", "xml"}
+
+// fieldName returns the name a struct field is validated and reported under,
+// matching the name request binding uses for it
+func fieldName(field reflect.StructField) string {
+	for _, tagName := range fieldNameTags {
+		if tag := field.Tag.Get(tagName); tag != "" {
+			if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
 func getDefaultRules() map[string]Rule {
 	return map[string]Rule{
 		"required":  NewRequiredRule(),
@@ -0,0 +1,367 @@
+package onyx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	routerImpl "github.com/onyx-go/framework/internal/http/router"
+)
+
+// ServerConfig holds the HTTP server timeouts and limits used by Start and Run
+type ServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// H2C serves HTTP/2 without TLS, for use behind a TLS-terminating proxy.
+	// It has no effect when TLS is enabled.
+	H2C bool
+
+	// TLS enables HTTPS serving
+	TLS TLSConfig
+
+	// ShutdownTimeout bounds how long Run waits for in-flight requests,
+	// workers and stopping hooks after a shutdown signal
+	ShutdownTimeout time.Duration
+}
+
+// DefaultServerConfig returns the server settings used when nothing is configured
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+		ShutdownTimeout:   30 * time.Second,
+		TLS: TLSConfig{
+			MinVersion:     tls.VersionTLS12,
+			HTTP2:          true,
+			ReloadInterval: time.Minute,
+		},
+	}
+}
+
+// serverConfigFromConfig reads the app.server section, falling back to the defaults
+func serverConfigFromConfig(config *Config) ServerConfig {
+	defaults := DefaultServerConfig()
+
+	return ServerConfig{
+		ReadTimeout:       config.GetDuration("app.server.read_timeout", defaults.ReadTimeout),
+		ReadHeaderTimeout: config.GetDuration("app.server.read_header_timeout", defaults.ReadHeaderTimeout),
+		WriteTimeout:      config.GetDuration("app.server.write_timeout", defaults.WriteTimeout),
+		IdleTimeout:       config.GetDuration("app.server.idle_timeout", defaults.IdleTimeout),
+		MaxHeaderBytes:    config.GetInt("app.server.max_header_bytes", defaults.MaxHeaderBytes),
+		H2C:               config.GetBool("app.server.h2c", defaults.H2C),
+		TLS:               tlsConfigFromConfig(config, defaults.TLS),
+		ShutdownTimeout:   config.GetDuration("app.server.shutdown_timeout", defaults.ShutdownTimeout),
+	}
+}
+
+// SetServerConfig overrides the server settings read from configuration
+func (app *Application) SetServerConfig(config ServerConfig) {
+	app.serverConfig = &config
+}
+
+// ServerConfig returns the server settings Start and Run will use
+func (app *Application) ServerConfig() ServerConfig {
+	if app.serverConfig != nil {
+		return *app.serverConfig
+	}
+	return serverConfigFromConfig(app.config)
+}
+
+// SetTrustedProxies sets the reverse proxies, as CIDR ranges or addresses,
+// allowed to report the client IP, scheme and host through the Forwarded and
+// X-Forwarded-* headers. "loopback" and "private" name the usual ranges.
+// Requests from any other peer have those headers ignored.
+func (app *Application) SetTrustedProxies(proxies ...string) error {
+	return app.router.(*routerImpl.Router).SetTrustedProxies(proxies...)
+}
+
+// applyTrustedProxies reads app.server.trusted_proxies unless trusted proxies
+// were already set in code
+func (app *Application) applyTrustedProxies() error {
+	proxies := app.config.GetStringSlice("app.server.trusted_proxies")
+	if len(proxies) == 0 || app.router.(*routerImpl.Router).TrustedProxies() != nil {
+		return nil
+	}
+	if err := app.SetTrustedProxies(proxies...); err != nil {
+		return fmt.Errorf("app.server.trusted_proxies: %w", err)
+	}
+	return nil
+}
+
+// LifecycleHook runs while the application is starting or stopping
+type LifecycleHook func(ctx context.Context) error
+
+// LifecycleProvider is implemented by service providers that need to run code
+// when the application starts or stops
+type LifecycleProvider interface {
+	OnStarting(ctx context.Context) error
+	OnStopping(ctx context.Context) error
+}
+
+// OnStarting registers a hook that runs before the server starts listening.
+// Hooks run in registration order and the first error aborts the start.
+func (app *Application) OnStarting(hook LifecycleHook) {
+	app.lifecycleMutex.Lock()
+	defer app.lifecycleMutex.Unlock()
+	app.startingHooks = append(app.startingHooks, hook)
+}
+
+// OnStopping registers a hook that runs during Shutdown, after in-flight
+// requests have drained and background workers have stopped, but before the
+// database, cache, mail and storage managers are closed. Hooks run in reverse
+// registration order so that later services stop before the ones they use.
+func (app *Application) OnStopping(hook LifecycleHook) {
+	app.lifecycleMutex.Lock()
+	defer app.lifecycleMutex.Unlock()
+	app.stoppingHooks = append(app.stoppingHooks, hook)
+}
+
+// RegisterProvider registers and boots a service provider. Providers that
+// implement LifecycleProvider also get their starting and stopping hooks
+// registered, in the order the providers were registered.
+func (app *Application) RegisterProvider(provider ServiceProvider) {
+	app.container.RegisterProvider(provider)
+
+	if lifecycle, ok := provider.(LifecycleProvider); ok {
+		app.OnStarting(lifecycle.OnStarting)
+		app.OnStopping(lifecycle.OnStopping)
+	}
+}
+
+// Run starts the server on address and blocks until ctx is cancelled, SIGINT
+// or SIGTERM is received, or the server fails. It then shuts the application
+// down gracefully, bounded by ServerConfig.ShutdownTimeout.
+func (app *Application) Run(ctx context.Context, address string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := app.listen(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.serve(listener)
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			runErr = err
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.ServerConfig().ShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(runErr, app.Shutdown(shutdownCtx))
+}
+
+// listen validates the routes, runs the starting hooks and opens the listener
+func (app *Application) listen(ctx context.Context, address string) (net.Listener, error) {
+	if err := app.router.Err(); err != nil {
+		return nil, fmt.Errorf("invalid route configuration: %w", err)
+	}
+
+	app.lifecycleMutex.Lock()
+	hooks := append([]LifecycleHook(nil), app.startingHooks...)
+	app.lifecycleMutex.Unlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return nil, fmt.Errorf("starting hook failed: %w", err)
+		}
+	}
+
+	if err := app.applyTrustedProxies(); err != nil {
+		return nil, err
+	}
+
+	config := app.ServerConfig()
+	server := &http.Server{
+		Addr:              address,
+		Handler:           app.router,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+		Protocols:         serverProtocols(config),
+	}
+
+	var reloader *certReloader
+	if config.TLS.Enabled {
+		tlsConfig, certs, err := buildTLSConfig(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig = tlsConfig
+		reloader = certs
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	var redirectServer *http.Server
+	if config.TLS.Enabled && config.TLS.RedirectAddress != "" {
+		_, port, _ := net.SplitHostPort(listener.Addr().String())
+		redirectServer = &http.Server{
+			Addr:              config.TLS.RedirectAddress,
+			Handler:           httpsRedirectHandler(port),
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			IdleTimeout:       config.IdleTimeout,
+		}
+
+		redirectListener, err := net.Listen("tcp", config.TLS.RedirectAddress)
+		if err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("https redirect listener: %w", err)
+		}
+
+		go func() {
+			if err := redirectServer.Serve(redirectListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				Error("HTTPS redirect listener stopped", map[string]interface{}{
+					"address": config.TLS.RedirectAddress,
+					"error":   err.Error(),
+				})
+			}
+		}()
+	}
+
+	if reloader != nil && config.TLS.ReloadInterval > 0 {
+		go reloader.watch(config.TLS.ReloadInterval)
+	}
+
+	app.lifecycleMutex.Lock()
+	app.server = server
+	app.redirectServer = redirectServer
+	app.certReloader = reloader
+	app.lifecycleMutex.Unlock()
+
+	return listener, nil
+}
+
+// serve accepts connections on listener until the server is shut down
+func (app *Application) serve(listener net.Listener) error {
+	if app.server.TLSConfig != nil {
+		fmt.Printf("🚀 Onyx server starting on https://%s\n", listener.Addr())
+		return app.server.ServeTLS(listener, "", "")
+	}
+
+	fmt.Printf("🚀 Onyx server starting on %s\n", listener.Addr())
+	return app.server.Serve(listener)
+}
+
+// Shutdown stops the application gracefully. It stops accepting requests and
+// waits for in-flight ones, stops the scheduler and the queue workers
+// started through Workers (the "queue.workers" container service), runs the
+// stopping hooks, and finally closes the queue, mail, storage, cache and
+// database services registered in the container. Every step runs even if an
+// earlier one fails; the errors are joined. Calling Shutdown more than once
+// returns the result of the first call.
+func (app *Application) Shutdown(ctx context.Context) error {
+	app.shutdownOnce.Do(func() {
+		app.shutdownErr = app.shutdown(ctx)
+	})
+	return app.shutdownErr
+}
+
+// shutdownServices lists the container services closed after the stopping
+// hooks, in order. The database is last since the others may still use it.
+var shutdownServices = []string{"queue", "mail", "storage", "cache", "database"}
+
+func (app *Application) shutdown(ctx context.Context) error {
+	var errs []error
+
+	app.lifecycleMutex.Lock()
+	server := app.server
+	redirectServer := app.redirectServer
+	reloader := app.certReloader
+	stoppingHooks := append([]LifecycleHook(nil), app.stoppingHooks...)
+	app.lifecycleMutex.Unlock()
+
+	// Drain in-flight requests first so handlers can still use every service
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("http server: %w", err))
+		}
+	}
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("https redirect listener: %w", err))
+		}
+	}
+	if reloader != nil {
+		reloader.Close()
+	}
+
+	// Stop producing and consuming background work
+	for _, name := range []string{"scheduler", "queue.workers"} {
+		if err := app.stopService(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for i := len(stoppingHooks) - 1; i >= 0; i-- {
+		if err := stoppingHooks[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stopping hook failed: %w", err))
+		}
+	}
+
+	for _, name := range shutdownServices {
+		if err := app.stopService(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopService stops or closes a container service if it has been resolved.
+// Services that were never used are left alone rather than created just to
+// be closed.
+func (app *Application) stopService(ctx context.Context, name string) error {
+	service, ok := app.container.Resolved(name)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	switch s := service.(type) {
+	case interface{ StopAll(context.Context) error }:
+		err = s.StopAll(ctx)
+	case interface{ Shutdown(context.Context) error }:
+		err = s.Shutdown(ctx)
+	case interface{ Stop() error }:
+		err = s.Stop()
+	case interface{ Stop() }:
+		s.Stop()
+	case io.Closer:
+		err = s.Close()
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package onyx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingService struct {
+	name   string
+	events *[]string
+}
+
+func (s *recordingService) Close() error {
+	*s.events = append(*s.events, "close "+s.name)
+	return nil
+}
+
+type recordingWorkers struct {
+	events *[]string
+}
+
+func (w *recordingWorkers) StopAll(ctx context.Context) error {
+	*w.events = append(*w.events, "stop workers")
+	return nil
+}
+
+type recordingProvider struct {
+	name   string
+	events *[]string
+}
+
+func (p *recordingProvider) Register(c *Container) {}
+func (p *recordingProvider) Boot(c *Container)     {}
+
+func (p *recordingProvider) OnStarting(ctx context.Context) error {
+	*p.events = append(*p.events, "start "+p.name)
+	return nil
+}
+
+func (p *recordingProvider) OnStopping(ctx context.Context) error {
+	*p.events = append(*p.events, "stop "+p.name)
+	return nil
+}
+
+func TestServerConfigFromConfig(t *testing.T) {
+	app := New()
+
+	if got := app.ServerConfig(); got != DefaultServerConfig() {
+		t.Errorf("Expected default server config, got %+v", got)
+	}
+
+	app.Config().Set("app", map[string]interface{}{
+		"server": map[string]interface{}{
+			"read_timeout":     "5s",
+			"shutdown_timeout": "2s",
+			"max_header_bytes": float64(4096),
+		},
+	})
+
+	config := app.ServerConfig()
+	if config.ReadTimeout != 5*time.Second {
+		t.Errorf("Expected read timeout 5s, got %v", config.ReadTimeout)
+	}
+	if config.ShutdownTimeout != 2*time.Second {
+		t.Errorf("Expected shutdown timeout 2s, got %v", config.ShutdownTimeout)
+	}
+	if config.MaxHeaderBytes != 4096 {
+		t.Errorf("Expected max header bytes 4096, got %d", config.MaxHeaderBytes)
+	}
+	if config.IdleTimeout != DefaultServerConfig().IdleTimeout {
+		t.Errorf("Expected default idle timeout, got %v", config.IdleTimeout)
+	}
+
+	override := DefaultServerConfig()
+	override.WriteTimeout = time.Second
+	app.SetServerConfig(override)
+	if got := app.ServerConfig(); got != override {
+		t.Errorf("Expected explicit server config to win, got %+v", got)
+	}
+}
+
+func TestApplicationShutdownOrder(t *testing.T) {
+	app := New()
+	var events []string
+
+	app.RegisterProvider(&recordingProvider{name: "first", events: &events})
+	app.RegisterProvider(&recordingProvider{name: "second", events: &events})
+
+	app.Container().Instance("queue.workers", &recordingWorkers{events: &events})
+	app.Container().Instance("cache", &recordingService{name: "cache", events: &events})
+	app.Container().Instance("database", &recordingService{name: "database", events: &events})
+
+	// Bound but never resolved services must not be created during shutdown
+	app.Container().Singleton("mail", func() (*recordingService, error) {
+		t.Error("Shutdown should not resolve unused services")
+		return &recordingService{name: "mail", events: &events}, nil
+	})
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	expected := []string{"stop workers", "stop second", "stop first", "close cache", "close database"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected shutdown order %v, got %v", expected, events)
+	}
+
+	// A second call must not repeat the shutdown
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Errorf("Second Shutdown returned %v", err)
+	}
+	if len(events) != len(expected) {
+		t.Errorf("Shutdown ran twice: %v", events)
+	}
+}
+
+type blockingJob struct {
+	*BaseJob
+	started chan struct{}
+	release chan struct{}
+}
+
+func (j *blockingJob) Handle() error {
+	close(j.started)
+	<-j.release
+	return nil
+}
+
+// blockingQueue hands out a single blockingJob
+type blockingQueue struct {
+	*MemoryQueue
+	job *blockingJob
+}
+
+func (q *blockingQueue) Pop(queue ...string) (Job, error) {
+	if job := q.job; job != nil {
+		q.job = nil
+		return job, nil
+	}
+	return nil, errors.New("no jobs available")
+}
+
+func TestApplicationShutdownStopsWorkers(t *testing.T) {
+	app := New()
+	job := &blockingJob{BaseJob: NewBaseJob(), started: make(chan struct{}), release: make(chan struct{})}
+	worker := app.Workers().Work(&blockingQueue{MemoryQueue: NewMemoryQueue(), job: job}, WorkerOptions{
+		Sleep:   time.Millisecond,
+		Timeout: time.Minute,
+	})
+	<-job.started
+
+	// The job outlives the shutdown timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := app.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected shutdown to give up on the busy worker, got %v", err)
+	}
+	if worker.IsRunning() {
+		t.Error("Expected the worker to be stopped")
+	}
+
+	close(job.release)
+	if err := worker.Wait(context.Background()); err != nil {
+		t.Errorf("Expected the worker to finish its job, got %v", err)
+	}
+}
+
+func TestApplicationShutdownCollectsErrors(t *testing.T) {
+	app := New()
+	errHook := errors.New("hook failed")
+	var ran bool
+
+	app.OnStopping(func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	app.OnStopping(func(ctx context.Context) error {
+		return errHook
+	})
+
+	err := app.Shutdown(context.Background())
+	if !errors.Is(err, errHook) {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+	if !ran {
+		t.Error("A failing hook should not prevent the remaining hooks from running")
+	}
+}
+
+func TestApplicationStartingHookError(t *testing.T) {
+	app := New()
+	errHook := errors.New("not ready")
+
+	app.OnStarting(func(ctx context.Context) error {
+		return errHook
+	})
+
+	if err := app.Start("127.0.0.1:0"); !errors.Is(err, errHook) {
+		t.Errorf("Expected starting hook error, got %v", err)
+	}
+}
+
+func TestApplicationShutdownDrainsRequests(t *testing.T) {
+	app := New()
+	started := make(chan struct{})
+
+	app.GetHandler("/slow", func(c Context) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	listener, err := app.listen(context.Background(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.serve(listener)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	res := <-response
+	if res.err != nil || res.body != "done" {
+		t.Errorf("Expected in-flight request to complete, got %q, %v", res.body, res.err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+}
+
+func TestApplicationRunStopsOnCancel(t *testing.T) {
+	app := New()
+	var events []string
+
+	app.OnStarting(func(ctx context.Context) error {
+		events = append(events, "starting")
+		return nil
+	})
+	app.OnStopping(func(ctx context.Context) error {
+		events = append(events, "stopping")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx, "127.0.0.1:0")
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	if !reflect.DeepEqual(events, []string{"starting", "stopping"}) {
+		t.Errorf("Expected starting and stopping hooks, got %v", events)
+	}
+}
@@ -0,0 +1,36 @@
+package onyx
+
+import (
+	"time"
+
+	"github.com/onyx-go/framework/internal/http/middleware/limits"
+)
+
+// Timeout and body limit errors re-exported for application code
+var (
+	ErrRequestTimeout  = limits.ErrRequestTimeout
+	ErrUpstreamTimeout = limits.ErrUpstreamTimeout
+	ErrBodyTooLarge    = limits.ErrBodyTooLarge
+)
+
+// TimeoutMiddleware gives the rest of the chain a deadline through
+// c.Request().Context(), so database and queue calls made with it are
+// cancelled when it passes. A handler that runs out of time is answered with
+// 503, one failing because a call it made timed out with 504. Use the
+// route's Timeout option to set it for a single route.
+func TimeoutMiddleware(timeout time.Duration) MiddlewareFunc {
+	middleware := limits.Timeout(timeout)
+	return func(c Context) error {
+		return middleware(c)
+	}
+}
+
+// BodyLimitMiddleware rejects request bodies larger than n bytes with 413
+// before Bind or Body read them. Use the route's MaxBodyBytes option to set
+// it for a single route.
+func BodyLimitMiddleware(n int64) MiddlewareFunc {
+	middleware := limits.MaxBodyBytes(n)
+	return func(c Context) error {
+		return middleware(c)
+	}
+}
@@ -0,0 +1,52 @@
+package onyx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouteLimits(t *testing.T) {
+	app := New()
+
+	app.GetHandler("/slow", func(c Context) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	}).Timeout(10 * time.Millisecond)
+
+	app.PostHandler("/upload", func(c Context) error {
+		body, err := c.Body()
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(body))
+	}).MaxBodyBytes(8)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"timeout", "GET", "/slow", "", http.StatusServiceUnavailable},
+		{"body within limit", "POST", "/upload", "small", http.StatusOK},
+		{"body over limit", "POST", "/upload", "far too large", http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+
+			app.Router().ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
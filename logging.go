@@ -1,6 +1,7 @@
 package onyx
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // LogLevel represents the severity level of a log entry
@@ -574,8 +577,21 @@ func LogWithContext(c Context) Logger {
 			"user_agent": c.Header("User-Agent"),
 			"remote_ip":  c.RemoteIP(),
 		}
+		addTraceContext(requestContext, c.Request().Context())
 		
 		return globalLogManager.Default().WithContext(requestContext)
 	}
 	return &NullLogger{}
-}
\ No newline at end of file
+}
+
+// addTraceContext adds the request ID and trace identifiers carried by ctx
+// to a log context
+func addTraceContext(logContext map[string]interface{}, ctx context.Context) {
+	if requestID := tracing.RequestID(ctx); requestID != "" {
+		logContext["request_id"] = requestID
+	}
+	if sc, ok := tracing.SpanContextFrom(ctx); ok {
+		logContext["trace_id"] = sc.TraceID
+		logContext["span_id"] = sc.SpanID
+	}
+}
@@ -2,9 +2,12 @@ package onyx
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"mime"
 	"net/smtp"
@@ -13,6 +16,8 @@ import (
 	"strings"
 	texttemplate "text/template"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 // Mail interfaces and types
@@ -36,6 +41,7 @@ type MailManager struct {
 	config        *MailConfig
 	drivers       map[string]MailDriver
 	defaultDriver string
+	functions     texttemplate.FuncMap
 }
 
 // Mail configuration structures
@@ -538,13 +544,35 @@ func NewMailManager(config *MailConfig) *MailManager {
 		config:        config,
 		drivers:       make(map[string]MailDriver),
 		defaultDriver: config.DefaultMailer,
+		functions:     make(texttemplate.FuncMap),
 	}
 }
 
+// AddFunction registers a function available to mail templates
+func (mm *MailManager) AddFunction(name string, fn interface{}) {
+	if mm.functions == nil {
+		mm.functions = make(texttemplate.FuncMap)
+	}
+	mm.functions[name] = fn
+}
+
 func (mm *MailManager) RegisterDriver(name string, driver MailDriver) {
 	mm.drivers[name] = driver
 }
 
+// Close closes every driver that keeps a connection open
+func (mm *MailManager) Close() error {
+	var errs []error
+	for name, driver := range mm.drivers {
+		if closer, ok := driver.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("mail driver %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (mm *MailManager) GetDriver(name string) (MailDriver, error) {
 	if name == "" {
 		name = mm.defaultDriver
@@ -559,17 +587,45 @@ func (mm *MailManager) GetDriver(name string) (MailDriver, error) {
 }
 
 func (mm *MailManager) Send(mailable Mailable, driverName ...string) error {
+	return mm.SendContext(context.Background(), mailable, driverName...)
+}
+
+// SendContext sends mailable like Send, adding the X-Request-ID, traceparent
+// and tracestate headers of ctx so the message can be linked to the request
+// or job that sent it
+func (mm *MailManager) SendContext(ctx context.Context, mailable Mailable, driverName ...string) error {
 	driver, err := mm.getDriverToUse(driverName...)
 	if err != nil {
 		return err
 	}
 	
+	ctx, span := tracing.Start(ctx, "mail.send", tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(map[string]interface{}{
+		"mail.driver": driver.GetName(),
+	}))
+	defer span.End()
+	
 	message, err := mm.buildMessage(mailable)
 	if err != nil {
+		span.RecordError(err)
 		return fmt.Errorf("failed to build message: %w", err)
 	}
 	
-	return driver.Send(message)
+	// Copy the envelope rather than changing the mailable's headers
+	if headers := tracing.Headers(ctx); len(headers) > 0 {
+		envelope := *message.Envelope
+		envelope.Headers = make(map[string]string, len(envelope.Headers)+len(headers))
+		for key, value := range message.Envelope.Headers {
+			envelope.Headers[key] = value
+		}
+		for key, value := range headers {
+			envelope.Headers[key] = value
+		}
+		message.Envelope = &envelope
+	}
+	
+	err = driver.Send(message)
+	span.RecordError(err)
+	return err
 }
 
 func (mm *MailManager) getDriverToUse(driverName ...string) (MailDriver, error) {
@@ -645,7 +701,7 @@ func (mm *MailManager) renderTemplate(templateName string, data map[string]inter
 	// In a real implementation, you'd integrate with your template system
 	templateContent := "Hello {{.Name}}, this is a test email."
 	
-	tmpl, err := texttemplate.New("email").Parse(templateContent)
+	tmpl, err := texttemplate.New("email").Funcs(mm.functions).Parse(templateContent)
 	if err != nil {
 		return "", err
 	}
@@ -761,6 +817,12 @@ func SendMail(mailable Mailable, driverName ...string) error {
 	return Mail().Send(mailable, driverName...)
 }
 
+// SendMailWithContext sends mailable with the request ID and trace context of
+// the current request
+func SendMailWithContext(c Context, mailable Mailable, driverName ...string) error {
+	return Mail().SendContext(c.Request().Context(), mailable, driverName...)
+}
+
 // Context helper function for getting mail manager
 func GetMailFromContext(c Context) *MailManager {
 	// For now, use the global mail manager since Application interface doesn't expose Container
@@ -0,0 +1,32 @@
+package onyx
+
+import (
+	"github.com/onyx-go/framework/internal/http/middleware/maintenance"
+)
+
+// MaintenanceState describes a maintenance window, re-exported for
+// application code
+type MaintenanceState = maintenance.State
+
+// MaintenancePath is the file whose presence puts every process of the
+// application into maintenance mode, relative to the working directory.
+// "onyx down" and "onyx up" write and remove it.
+const MaintenancePath = maintenance.DefaultPath
+
+// Down puts the application into maintenance mode. Requests other than to
+// the HealthEndpoints are answered with 503 until Up is called, except from
+// state's allowed addresses and from browsers that visited "/<secret>".
+func (app *Application) Down(state MaintenanceState) error {
+	return maintenance.Down(MaintenancePath, state)
+}
+
+// Up ends maintenance mode
+func (app *Application) Up() error {
+	return maintenance.Up(MaintenancePath)
+}
+
+// IsDownForMaintenance reports whether the application is in maintenance mode
+func (app *Application) IsDownForMaintenance() bool {
+	state, err := maintenance.Read(MaintenancePath)
+	return state != nil || err != nil
+}
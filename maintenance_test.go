@@ -0,0 +1,53 @@
+package onyx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	app := New()
+	app.HealthEndpoints("")
+	app.GetHandler("/", func(c Context) error {
+		return c.String(200, "home")
+	})
+
+	request := func(path string) int {
+		w := httptest.NewRecorder()
+		app.Router().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	if app.IsDownForMaintenance() || request("/") != http.StatusOK {
+		t.Fatal("Expected the application to start up")
+	}
+
+	if err := app.Down(MaintenanceState{Retry: 30}); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if !app.IsDownForMaintenance() {
+		t.Error("Expected the application to be down")
+	}
+	if code := request("/"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while down, got %d", code)
+	}
+	if code := request("/health/live"); code != http.StatusOK {
+		t.Errorf("Expected health checks to be served while down, got %d", code)
+	}
+
+	// Health endpoints under a custom prefix are served too
+	app.HealthEndpoints("/status/")
+	if code := request("/status/ready"); code != http.StatusOK {
+		t.Errorf("Expected health checks under a custom prefix to be served while down, got %d", code)
+	}
+
+	if err := app.Up(); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if app.IsDownForMaintenance() || request("/") != http.StatusOK {
+		t.Error("Expected the application to be back up")
+	}
+}
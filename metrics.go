@@ -0,0 +1,88 @@
+package onyx
+
+import (
+	"github.com/onyx-go/framework/internal/metrics"
+)
+
+// Metrics types re-exported for application code
+type (
+	MetricsRegistry      = metrics.Registry
+	MetricFamily         = metrics.Family
+	MetricSample         = metrics.Sample
+	MetricsCollector     = metrics.Collector
+	MetricsCollectorFunc = metrics.CollectorFunc
+)
+
+// Metric family types
+const (
+	CounterMetric   = metrics.CounterType
+	GaugeMetric     = metrics.GaugeType
+	HistogramMetric = metrics.HistogramType
+)
+
+// Counters reported by the response cache and query profiler, summed over
+// every instance
+var (
+	responseCacheHits      = metrics.Default().NewCounter("onyx_response_cache_hits_total", "Requests answered from the response cache.")
+	responseCacheMisses    = metrics.Default().NewCounter("onyx_response_cache_misses_total", "Cacheable requests not found in the response cache.")
+	responseCacheStores    = metrics.Default().NewCounter("onyx_response_cache_stores_total", "Responses stored in the response cache.")
+	responseCacheEvictions = metrics.Default().NewCounter("onyx_response_cache_evictions_total", "Responses evicted to make room in the response cache.")
+
+	queryDuration = metrics.Default().NewHistogram("onyx_db_query_duration_seconds", "Time spent in profiled queries, by result.", nil, "result")
+	slowQueries   = metrics.Default().NewCounter("onyx_db_slow_queries_total", "Profiled queries slower than the profiler's threshold.")
+)
+
+// Metrics returns the registry that the router, cache, events, queue
+// workers, response cache and query profiler report into. Register
+// application counters, gauges and histograms on it to serve them alongside.
+func (app *Application) Metrics() *MetricsRegistry {
+	return metrics.Default()
+}
+
+// MetricsEndpoint serves the registry in the Prometheus text exposition
+// format at path, "/metrics" when empty. Pass middleware to restrict who may
+// scrape it.
+func (app *Application) MetricsEndpoint(path string, middleware ...MiddlewareFunc) {
+	if path == "" {
+		path = "/metrics"
+	}
+	handler := metrics.Default().Handler()
+	app.GetHandler(path, func(c Context) error {
+		handler.ServeHTTP(c.ResponseWriter(), c.Request())
+		return nil
+	}, middleware...)
+}
+
+// RegisterMetrics reports the connection pool statistics of db under the
+// given database label each time metrics are gathered. Registering another
+// pool under the same name replaces it.
+func (db *DB) RegisterMetrics(name string) {
+	metrics.Default().Register("db:"+name, metrics.CollectorFunc(func() []metrics.Family {
+		stats := db.GetDetailedPoolMetrics()
+		labels := map[string]string{"database": name}
+		with := func(key, value string) map[string]string {
+			return map[string]string{"database": name, key: value}
+		}
+
+		return []metrics.Family{
+			{Name: "onyx_db_connections", Help: "Open connections by state.", Type: metrics.GaugeType, Samples: []metrics.Sample{
+				{Labels: with("state", "in_use"), Value: float64(stats.InUse)},
+				{Labels: with("state", "idle"), Value: float64(stats.Idle)},
+			}},
+			{Name: "onyx_db_connections_max_open", Help: "Maximum open connections; 0 means unlimited.", Type: metrics.GaugeType, Samples: []metrics.Sample{
+				{Labels: labels, Value: float64(stats.MaxOpenConnections)},
+			}},
+			{Name: "onyx_db_connection_waits_total", Help: "Times a query waited for a free connection.", Type: metrics.CounterType, Samples: []metrics.Sample{
+				{Labels: labels, Value: float64(stats.WaitCount)},
+			}},
+			{Name: "onyx_db_connection_wait_seconds_total", Help: "Time spent waiting for a free connection.", Type: metrics.CounterType, Samples: []metrics.Sample{
+				{Labels: labels, Value: stats.WaitDuration.Seconds()},
+			}},
+			{Name: "onyx_db_connections_closed_total", Help: "Connections closed by the pool, by reason.", Type: metrics.CounterType, Samples: []metrics.Sample{
+				{Labels: with("reason", "max_idle"), Value: float64(stats.MaxIdleClosed)},
+				{Labels: with("reason", "max_idle_time"), Value: float64(stats.MaxIdleTimeClosed)},
+				{Labels: with("reason", "max_lifetime"), Value: float64(stats.MaxLifetimeClosed)},
+			}},
+		}
+	}))
+}
@@ -0,0 +1,46 @@
+package onyx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	app := New()
+	app.MetricsEndpoint("")
+
+	app.GetHandler("/orders/{id}", func(c Context) error {
+		return c.String(http.StatusOK, "order "+c.Param("id"))
+	})
+	app.GetHandler("/orders/{id}/fail", func(c Context) error {
+		return NewHTTPError(http.StatusInternalServerError, "boom")
+	})
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/orders/3/fail"} {
+		app.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	app.Router().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("expected Prometheus text output, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	body := w.Body.String()
+	for _, expected := range []string{
+		"# TYPE onyx_http_request_duration_seconds histogram\n",
+		`onyx_http_request_duration_seconds_count{method="GET",route="/orders/{id}",status="200"} 2` + "\n",
+		`onyx_http_request_duration_seconds_count{method="GET",route="/orders/{id}/fail",status="500"} 1` + "\n",
+		`onyx_http_request_duration_seconds_bucket{method="GET",route="/orders/{id}",status="200",le="+Inf"} 2` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, `route="/orders/1"`) {
+		t.Error("expected requests to be labelled by route pattern, not path")
+	}
+}
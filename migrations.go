@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/onyx-go/framework/internal/database/grammar"
 )
 
 // Migration interface defines the contract for database migrations
@@ -253,8 +255,18 @@ func (dsb *DefaultSchemaBuilder) Table(tableName string, callback func(table Tab
 	return nil
 }
 
+// wrap quotes a table or column name for the builder's driver
+func (dsb *DefaultSchemaBuilder) wrap(name string) string {
+	return grammar.For(dsb.driver).Wrap(name)
+}
+
+// rebind rewrites "?" placeholders for the builder's driver
+func (dsb *DefaultSchemaBuilder) rebind(query string) string {
+	return grammar.Rebind(grammar.For(dsb.driver), query)
+}
+
 func (dsb *DefaultSchemaBuilder) Drop(tableName string) error {
-	sql := fmt.Sprintf("DROP TABLE %s", tableName)
+	sql := fmt.Sprintf("DROP TABLE %s", dsb.wrap(tableName))
 	_, err := dsb.db.Exec(sql)
 	return err
 }
@@ -263,13 +275,13 @@ func (dsb *DefaultSchemaBuilder) DropIfExists(tableName string) error {
 	var sql string
 	switch dsb.driver {
 	case "mysql":
-		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s", dsb.wrap(tableName))
 	case "postgres":
-		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", tableName)
+		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", dsb.wrap(tableName))
 	case "sqlite3":
-		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s", dsb.wrap(tableName))
 	default:
-		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+		sql = fmt.Sprintf("DROP TABLE IF EXISTS %s", dsb.wrap(tableName))
 	}
 	
 	_, err := dsb.db.Exec(sql)
@@ -280,13 +292,13 @@ func (dsb *DefaultSchemaBuilder) Rename(from, to string) error {
 	var sql string
 	switch dsb.driver {
 	case "mysql":
-		sql = fmt.Sprintf("RENAME TABLE %s TO %s", from, to)
+		sql = fmt.Sprintf("RENAME TABLE %s TO %s", dsb.wrap(from), dsb.wrap(to))
 	case "postgres":
-		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from, to)
+		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", dsb.wrap(from), dsb.wrap(to))
 	case "sqlite3":
-		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from, to)
+		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", dsb.wrap(from), dsb.wrap(to))
 	default:
-		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from, to)
+		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", dsb.wrap(from), dsb.wrap(to))
 	}
 	
 	_, err := dsb.db.Exec(sql)
@@ -302,7 +314,7 @@ func (dsb *DefaultSchemaBuilder) HasTable(tableName string) (bool, error) {
 		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
 		args = []interface{}{tableName}
 	case "postgres":
-		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1"
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
 		args = []interface{}{tableName}
 	case "sqlite3":
 		query = "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = ?"
@@ -312,7 +324,7 @@ func (dsb *DefaultSchemaBuilder) HasTable(tableName string) (bool, error) {
 	}
 	
 	var count int
-	err := dsb.db.QueryRow(query, args...).Scan(&count)
+	err := dsb.db.QueryRow(dsb.rebind(query), args...).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -329,7 +341,7 @@ func (dsb *DefaultSchemaBuilder) HasColumn(tableName, columnName string) (bool,
 		query = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?"
 		args = []interface{}{tableName, columnName}
 	case "postgres":
-		query = "SELECT COUNT(*) FROM information_schema.columns WHERE table_name = $1 AND column_name = $2"
+		query = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?"
 		args = []interface{}{tableName, columnName}
 	case "sqlite3":
 		query = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
@@ -339,7 +351,7 @@ func (dsb *DefaultSchemaBuilder) HasColumn(tableName, columnName string) (bool,
 	}
 	
 	var count int
-	err := dsb.db.QueryRow(query, args...).Scan(&count)
+	err := dsb.db.QueryRow(dsb.rebind(query), args...).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -356,7 +368,7 @@ func (dsb *DefaultSchemaBuilder) GetColumnListing(tableName string) ([]string, e
 		query = "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position"
 		args = []interface{}{tableName}
 	case "postgres":
-		query = "SELECT column_name FROM information_schema.columns WHERE table_name = $1 ORDER BY ordinal_position"
+		query = "SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? ORDER BY ordinal_position"
 		args = []interface{}{tableName}
 	case "sqlite3":
 		query = "SELECT name FROM pragma_table_info(?)"
@@ -365,7 +377,7 @@ func (dsb *DefaultSchemaBuilder) GetColumnListing(tableName string) ([]string, e
 		return nil, fmt.Errorf("unsupported driver: %s", dsb.driver)
 	}
 	
-	rows, err := dsb.db.Query(query, args...)
+	rows, err := dsb.db.Query(dsb.rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -390,7 +402,7 @@ type TableBuilder struct {
 	columns      []*ColumnBuilder
 	indexes      []*IndexBuilder
 	foreignKeys  []*ForeignKeyBuilder
-	commands     []string
+	commands     []func(g Grammar) string
 }
 
 func NewTableBuilder(name, action string) TableBuilder {
@@ -400,7 +412,7 @@ func NewTableBuilder(name, action string) TableBuilder {
 		columns:     make([]*ColumnBuilder, 0),
 		indexes:     make([]*IndexBuilder, 0),
 		foreignKeys: make([]*ForeignKeyBuilder, 0),
-		commands:    make([]string, 0),
+		commands:    make([]func(g Grammar) string, 0),
 	}
 }
 
@@ -872,29 +884,41 @@ func (t *TableBuilder) Foreign(column string) ForeignKey {
 // Drop operations
 func (t *TableBuilder) DropColumn(columns ...string) {
 	for _, column := range columns {
-		t.commands = append(t.commands, fmt.Sprintf("DROP COLUMN %s", column))
+		t.command("DROP COLUMN %s", column)
 	}
 }
 
 func (t *TableBuilder) DropIndex(name string) {
-	t.commands = append(t.commands, fmt.Sprintf("DROP INDEX %s", name))
+	t.command("DROP INDEX %s", name)
 }
 
 func (t *TableBuilder) DropUnique(name string) {
-	t.commands = append(t.commands, fmt.Sprintf("DROP INDEX %s", name))
+	t.command("DROP INDEX %s", name)
 }
 
 func (t *TableBuilder) DropPrimary(name ...string) {
-	t.commands = append(t.commands, "DROP PRIMARY KEY")
+	t.command("DROP PRIMARY KEY")
 }
 
 func (t *TableBuilder) DropForeign(name string) {
-	t.commands = append(t.commands, fmt.Sprintf("DROP FOREIGN KEY %s", name))
+	t.command("DROP FOREIGN KEY %s", name)
 }
 
 // Modify operations
 func (t *TableBuilder) RenameColumn(from, to string) {
-	t.commands = append(t.commands, fmt.Sprintf("RENAME COLUMN %s TO %s", from, to))
+	t.command("RENAME COLUMN %s TO %s", from, to)
+}
+
+// command queues an ALTER TABLE clause whose names are quoted once the
+// driver is known
+func (t *TableBuilder) command(format string, names ...string) {
+	t.commands = append(t.commands, func(g Grammar) string {
+		wrapped := make([]interface{}, len(names))
+		for i, name := range names {
+			wrapped[i] = g.Wrap(name)
+		}
+		return fmt.Sprintf(format, wrapped...)
+	})
 }
 
 func (t *TableBuilder) ChangeColumn(name string) Column {
@@ -957,6 +981,7 @@ func (t *TableBuilder) ToSQL(driver string) []string {
 }
 
 func (t *TableBuilder) buildCreateTableSQL(driver string) []string {
+	g := grammar.For(driver)
 	var statements []string
 	var parts []string
 	
@@ -968,7 +993,7 @@ func (t *TableBuilder) buildCreateTableSQL(driver string) []string {
 	// Add primary key if not already added
 	for _, index := range t.indexes {
 		if index.type_ == "PRIMARY KEY" {
-			parts = append(parts, fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(index.columns, ", ")))
+			parts = append(parts, fmt.Sprintf("  PRIMARY KEY (%s)", grammar.Columnize(g, index.columns)))
 		}
 	}
 	
@@ -976,7 +1001,7 @@ func (t *TableBuilder) buildCreateTableSQL(driver string) []string {
 	if driver == "sqlite3" {
 		for _, fk := range t.foreignKeys {
 			if fk.referencedTable != "" && fk.referencedColumn != "" {
-				fkSQL := fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s)", fk.column, fk.referencedTable, fk.referencedColumn)
+				fkSQL := fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s)", g.Wrap(fk.column), g.Wrap(fk.referencedTable), g.Wrap(fk.referencedColumn))
 				if fk.onDelete != "" {
 					fkSQL += fmt.Sprintf(" ON DELETE %s", fk.onDelete)
 				}
@@ -989,7 +1014,7 @@ func (t *TableBuilder) buildCreateTableSQL(driver string) []string {
 	}
 	
 	// Create table statement
-	sql := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", t.name, strings.Join(parts, ",\n"))
+	sql := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", g.Wrap(t.name), strings.Join(parts, ",\n"))
 	statements = append(statements, sql)
 	
 	// Add indexes
@@ -1010,6 +1035,7 @@ func (t *TableBuilder) buildCreateTableSQL(driver string) []string {
 }
 
 func (t *TableBuilder) buildAlterTableSQL(driver string) []string {
+	g := grammar.For(driver)
 	var statements []string
 	
 	// Handle SQLite's limitation: it doesn't support multiple ADD COLUMN in one statement
@@ -1020,14 +1046,14 @@ func (t *TableBuilder) buildAlterTableSQL(driver string) []string {
 				// SQLite doesn't support MODIFY COLUMN, would need recreate table
 				statements = append(statements, fmt.Sprintf("-- WARNING: SQLite doesn't support MODIFY COLUMN for %s", column.name))
 			} else {
-				sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", t.name, column.ToSQL(driver))
+				sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", g.Wrap(t.name), column.ToSQL(driver))
 				statements = append(statements, sql)
 			}
 		}
 		
 		// Add commands one by one for SQLite
 		for _, command := range t.commands {
-			sql := fmt.Sprintf("ALTER TABLE %s %s", t.name, command)
+			sql := fmt.Sprintf("ALTER TABLE %s %s", g.Wrap(t.name), command(g))
 			statements = append(statements, sql)
 		}
 	} else {
@@ -1045,11 +1071,11 @@ func (t *TableBuilder) buildAlterTableSQL(driver string) []string {
 		
 		// Add commands
 		for _, command := range t.commands {
-			alterations = append(alterations, command)
+			alterations = append(alterations, command(g))
 		}
 		
 		if len(alterations) > 0 {
-			sql := fmt.Sprintf("ALTER TABLE %s %s", t.name, strings.Join(alterations, ", "))
+			sql := fmt.Sprintf("ALTER TABLE %s %s", g.Wrap(t.name), strings.Join(alterations, ", "))
 			statements = append(statements, sql)
 		}
 	}
@@ -1189,9 +1215,11 @@ func (c *ColumnBuilder) GetType() string {
 
 // ToSQL generates SQL for the column based on driver
 func (c *ColumnBuilder) ToSQL(driver string) string {
+	name := grammar.For(driver).Wrap(c.name)
+	
 	// Special handling for SQLite auto-increment primary keys
 	if driver == "sqlite3" && c.autoIncrement && c.primary {
-		sql := c.name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+		sql := name + " INTEGER PRIMARY KEY AUTOINCREMENT"
 		
 		if c.defaultValue != nil {
 			switch v := c.defaultValue.(type) {
@@ -1219,7 +1247,7 @@ func (c *ColumnBuilder) ToSQL(driver string) string {
 	
 	// Handle ENUM types for SQLite
 	if driver == "sqlite3" && c.dataType == "ENUM" && len(c.enumValues) > 0 {
-		sql := c.name + " TEXT"
+		sql := name + " TEXT"
 		
 		if !c.nullable {
 			sql += " NOT NULL"
@@ -1230,7 +1258,7 @@ func (c *ColumnBuilder) ToSQL(driver string) string {
 		for i, value := range c.enumValues {
 			quotedValues[i] = fmt.Sprintf("'%s'", value)
 		}
-		checkConstraint := fmt.Sprintf(" CHECK (%s IN (%s))", c.name, strings.Join(quotedValues, ", "))
+		checkConstraint := fmt.Sprintf(" CHECK (%s IN (%s))", name, strings.Join(quotedValues, ", "))
 		sql += checkConstraint
 		
 		if c.defaultValue != nil {
@@ -1245,7 +1273,7 @@ func (c *ColumnBuilder) ToSQL(driver string) string {
 		return sql
 	}
 	
-	sql := c.name + " " + c.getDataTypeSQL(driver)
+	sql := name + " " + c.getDataTypeSQL(driver)
 	
 	if c.unsigned && (driver == "mysql") {
 		sql += " UNSIGNED"
@@ -1262,9 +1290,9 @@ func (c *ColumnBuilder) ToSQL(driver string) string {
 		case "postgres":
 			// PostgreSQL uses SERIAL or BIGSERIAL
 			if c.dataType == "BIGINT" {
-				sql = c.name + " BIGSERIAL"
+				sql = name + " BIGSERIAL"
 			} else {
-				sql = c.name + " SERIAL"
+				sql = name + " SERIAL"
 			}
 		case "sqlite3":
 			// This case is handled above for primary keys
@@ -1405,8 +1433,9 @@ func (i *IndexBuilder) Where(condition string) Index {
 }
 
 func (i *IndexBuilder) ToSQL(tableName, driver string) string {
+	g := grammar.For(driver)
 	if i.type_ == "PRIMARY KEY" {
-		return fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", tableName, strings.Join(i.columns, ", "))
+		return fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", g.Wrap(tableName), grammar.Columnize(g, i.columns))
 	}
 	
 	indexType := ""
@@ -1414,7 +1443,7 @@ func (i *IndexBuilder) ToSQL(tableName, driver string) string {
 		indexType = "UNIQUE "
 	}
 	
-	sql := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", indexType, i.name, tableName, strings.Join(i.columns, ", "))
+	sql := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", indexType, g.Wrap(i.name), g.Wrap(tableName), grammar.Columnize(g, i.columns))
 	
 	if i.algorithm != "" && driver == "mysql" {
 		sql += fmt.Sprintf(" USING %s", i.algorithm)
@@ -1490,8 +1519,9 @@ func (fk *ForeignKeyBuilder) ToSQL(tableName, driver string) string {
 			tableName, fk.column, fk.referencedTable, fk.referencedColumn)
 	}
 	
+	g := grammar.For(driver)
 	sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
-		tableName, fk.name, fk.column, fk.referencedTable, fk.referencedColumn)
+		g.Wrap(tableName), g.Wrap(fk.name), g.Wrap(fk.column), g.Wrap(fk.referencedTable), g.Wrap(fk.referencedColumn))
 	
 	if fk.onDelete != "" {
 		sql += fmt.Sprintf(" ON DELETE %s", fk.onDelete)
@@ -1682,6 +1712,11 @@ func (m *Migrator) Status() ([]MigrationStatus, error) {
 	return statuses, nil
 }
 
+// wrap quotes a name for the migrator's driver
+func (m *Migrator) wrap(name string) string {
+	return grammar.For(m.driver).Wrap(name)
+}
+
 func (m *Migrator) createMigrationsTable() error {
 	exists, err := m.schema.HasTable(m.tableName)
 	if err != nil {
@@ -1731,7 +1766,7 @@ func (m *Migrator) getPendingMigrations() ([]Migration, error) {
 func (m *Migrator) getRanMigrations() ([]RanMigration, error) {
 	var ranMigrations []RanMigration
 	
-	query := fmt.Sprintf("SELECT migration, batch FROM %s ORDER BY batch, migration", m.tableName)
+	query := fmt.Sprintf("SELECT migration, batch FROM %s ORDER BY batch, migration", m.wrap(m.tableName))
 	rows, err := m.db.Query(query)
 	if err != nil {
 		return ranMigrations, err
@@ -1750,14 +1785,14 @@ func (m *Migrator) getRanMigrations() ([]RanMigration, error) {
 }
 
 func (m *Migrator) getNextBatchNumber() (int, error) {
-	query := fmt.Sprintf("SELECT COALESCE(MAX(batch), 0) + 1 FROM %s", m.tableName)
+	query := fmt.Sprintf("SELECT COALESCE(MAX(batch), 0) + 1 FROM %s", m.wrap(m.tableName))
 	var batch int
 	err := m.db.QueryRow(query).Scan(&batch)
 	return batch, err
 }
 
 func (m *Migrator) getLastBatches(count int) ([]int, error) {
-	query := fmt.Sprintf("SELECT DISTINCT batch FROM %s ORDER BY batch DESC LIMIT %d", m.tableName, count)
+	query := fmt.Sprintf("SELECT DISTINCT batch FROM %s ORDER BY batch DESC LIMIT %d", m.wrap(m.tableName), count)
 	rows, err := m.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -1777,7 +1812,7 @@ func (m *Migrator) getLastBatches(count int) ([]int, error) {
 }
 
 func (m *Migrator) getAllBatches() ([]int, error) {
-	query := fmt.Sprintf("SELECT DISTINCT batch FROM %s ORDER BY batch DESC", m.tableName)
+	query := fmt.Sprintf("SELECT DISTINCT batch FROM %s ORDER BY batch DESC", m.wrap(m.tableName))
 	rows, err := m.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -1797,15 +1832,8 @@ func (m *Migrator) getAllBatches() ([]int, error) {
 }
 
 func (m *Migrator) getMigrationsFromBatch(batch int) ([]Migration, error) {
-	query := fmt.Sprintf("SELECT migration FROM %s WHERE batch = ? ORDER BY migration", m.tableName)
-	var placeholder string
-	switch m.driver {
-	case "postgres":
-		placeholder = "$1"
-	default:
-		placeholder = "?"
-	}
-	query = strings.Replace(query, "?", placeholder, -1)
+	query := fmt.Sprintf("SELECT migration FROM %s WHERE batch = ? ORDER BY migration", m.wrap(m.tableName))
+	query = grammar.Rebind(grammar.For(m.driver), query)
 	
 	rows, err := m.db.Query(query, batch)
 	if err != nil {
@@ -1861,31 +1889,16 @@ func (m *Migrator) getAllTables() ([]string, error) {
 }
 
 func (m *Migrator) logMigration(migration Migration) error {
-	query := fmt.Sprintf("INSERT INTO %s (migration, batch) VALUES (?, ?)", m.tableName)
-	var placeholders []string
-	switch m.driver {
-	case "postgres":
-		placeholders = []string{"$1", "$2"}
-	default:
-		placeholders = []string{"?", "?"}
-	}
-	query = strings.Replace(query, "?", placeholders[0], 1)
-	query = strings.Replace(query, "?", placeholders[1], 1)
+	query := fmt.Sprintf("INSERT INTO %s (migration, batch) VALUES (?, ?)", m.wrap(m.tableName))
+	query = grammar.Rebind(grammar.For(m.driver), query)
 	
 	_, err := m.db.Exec(query, migration.GetName(), migration.GetBatch())
 	return err
 }
 
 func (m *Migrator) removeMigrationLog(migration Migration) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE migration = ?", m.tableName)
-	var placeholder string
-	switch m.driver {
-	case "postgres":
-		placeholder = "$1"
-	default:
-		placeholder = "?"
-	}
-	query = strings.Replace(query, "?", placeholder, -1)
+	query := fmt.Sprintf("DELETE FROM %s WHERE migration = ?", m.wrap(m.tableName))
+	query = grammar.Rebind(grammar.For(m.driver), query)
 	
 	_, err := m.db.Exec(query, migration.GetName())
 	return err
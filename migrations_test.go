@@ -620,10 +620,11 @@ func TestSQLGeneration(t *testing.T) {
 	tests := []struct {
 		name   string
 		driver string
+		create string
 	}{
-		{"MySQL", "mysql"},
-		{"PostgreSQL", "postgres"},
-		{"SQLite", "sqlite3"},
+		{"MySQL", "mysql", "CREATE TABLE `test_table`"},
+		{"PostgreSQL", "postgres", `CREATE TABLE "test_table"`},
+		{"SQLite", "sqlite3", `CREATE TABLE "test_table"`},
 	}
 	
 	for _, test := range tests {
@@ -642,7 +643,7 @@ func TestSQLGeneration(t *testing.T) {
 			}
 			
 			// Check that main CREATE TABLE statement is present
-			if !strings.Contains(sql[0], "CREATE TABLE test_table") {
+			if !strings.Contains(sql[0], test.create) {
 				t.Errorf("CREATE TABLE statement not found in SQL for driver %s", test.driver)
 			}
 			
@@ -657,6 +658,22 @@ func TestSQLGeneration(t *testing.T) {
 	}
 }
 
+func TestAlterCommandQuoting(t *testing.T) {
+	table := NewTableBuilder("orders", "alter")
+	table.RenameColumn("user", "customer")
+	table.DropColumn("group")
+
+	tests := map[string]string{
+		"mysql":    "ALTER TABLE `orders` RENAME COLUMN `user` TO `customer`, DROP COLUMN `group`",
+		"postgres": `ALTER TABLE "orders" RENAME COLUMN "user" TO "customer", DROP COLUMN "group"`,
+	}
+	for driver, expected := range tests {
+		if sql := table.ToSQL(driver); len(sql) != 1 || sql[0] != expected {
+			t.Errorf("%s: expected %q, got %q", driver, expected, sql)
+		}
+	}
+}
+
 func TestColumnSQLGeneration(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -673,7 +690,7 @@ func TestColumnSQLGeneration(t *testing.T) {
 				length:   255,
 				nullable: true,
 			},
-			expected: "test_col VARCHAR(255)",
+			expected: "`test_col` VARCHAR(255)",
 		},
 		{
 			name:   "INT with NOT NULL",
@@ -683,7 +700,7 @@ func TestColumnSQLGeneration(t *testing.T) {
 				dataType: "INT",
 				nullable: false,
 			},
-			expected: "test_col INT NOT NULL",
+			expected: "`test_col` INT NOT NULL",
 		},
 		{
 			name:   "AUTO_INCREMENT",
@@ -694,7 +711,17 @@ func TestColumnSQLGeneration(t *testing.T) {
 				autoIncrement: true,
 				nullable:      false,
 			},
-			expected: "test_col INT NOT NULL AUTO_INCREMENT",
+			expected: "`test_col` INT NOT NULL AUTO_INCREMENT",
+		},
+		{
+			name:   "Reserved word on PostgreSQL",
+			driver: "postgres",
+			column: &ColumnBuilder{
+				name:     "order",
+				dataType: "INT",
+				nullable: false,
+			},
+			expected: `"order" INT NOT NULL`,
 		},
 	}
 	
@@ -1107,7 +1134,7 @@ func TestSQLiteSpecificSQLGeneration(t *testing.T) {
 
 	for i, stmt := range sql {
 		t.Logf("SQLite ALTER statement %d: %s", i+1, stmt)
-		if !strings.Contains(stmt, "ALTER TABLE test_table ADD COLUMN") {
+		if !strings.Contains(stmt, `ALTER TABLE "test_table" ADD COLUMN`) {
 			t.Errorf("Statement %d doesn't contain proper ALTER TABLE syntax: %s", i+1, stmt)
 		}
 	}
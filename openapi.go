@@ -741,12 +741,18 @@ func CreatePaginatedResponse(itemSchema *OpenAPISchema) *OpenAPISchema {
 			"meta": &OpenAPISchema{
 				Type: "object",
 				Properties: map[string]*OpenAPISchema{
-					"current_page": &OpenAPISchema{Type: "integer"},
-					"per_page":     &OpenAPISchema{Type: "integer"},
-					"total":        &OpenAPISchema{Type: "integer"},
-					"total_pages":  &OpenAPISchema{Type: "integer"},
-					"has_next":     &OpenAPISchema{Type: "boolean"},
-					"has_prev":     &OpenAPISchema{Type: "boolean"},
+					"current_page":   &OpenAPISchema{Type: "integer"},
+					"per_page":       &OpenAPISchema{Type: "integer"},
+					"total":          &OpenAPISchema{Type: "integer"},
+					"total_pages":    &OpenAPISchema{Type: "integer"},
+					"has_next":       &OpenAPISchema{Type: "boolean"},
+					"has_prev":       &OpenAPISchema{Type: "boolean"},
+					"next_cursor":    &OpenAPISchema{Type: "string"},
+					"prev_cursor":    &OpenAPISchema{Type: "string"},
+					"first_page_url": &OpenAPISchema{Type: "string"},
+					"last_page_url":  &OpenAPISchema{Type: "string"},
+					"next_page_url":  &OpenAPISchema{Type: "string"},
+					"prev_page_url":  &OpenAPISchema{Type: "string"},
 				},
 			},
 		},
@@ -0,0 +1,497 @@
+package onyx
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query parameters the pagination helpers read and write
+const (
+	PageParam    = "page"
+	PerPageParam = "per_page"
+	CursorParam  = "cursor"
+)
+
+// ErrInvalidCursor is returned by CursorPaginate for a cursor it did not
+// issue for the query, which handlers usually answer with 400
+var ErrInvalidCursor = errors.New("onyx: invalid pagination cursor")
+
+// Paginator is a page of query results from Paginate or SimplePaginate
+type Paginator struct {
+	Items       interface{} // The page's rows: the slice dest points to
+	CurrentPage int
+	PerPage     int
+	Total       int64 // Rows the query matches; -1 from SimplePaginate
+	LastPage    int   // 0 from SimplePaginate
+	HasMore     bool  // Whether a page follows this one
+}
+
+// CursorPaginator is a page of query results from CursorPaginate
+type CursorPaginator struct {
+	Items      interface{} // The page's rows: the slice dest points to
+	PerPage    int
+	NextCursor string // "" on the last page
+	PrevCursor string // "" on the first page
+}
+
+// PaginationMeta is the "meta" object of a paginated list response, as
+// described by PaginationMetaSchema
+type PaginationMeta struct {
+	CurrentPage  int    `json:"current_page,omitempty"`
+	PerPage      int    `json:"per_page"`
+	Total        *int64 `json:"total,omitempty"`
+	TotalPages   *int   `json:"total_pages,omitempty"`
+	HasNext      bool   `json:"has_next"`
+	HasPrev      bool   `json:"has_prev"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
+	FirstPageURL string `json:"first_page_url,omitempty"`
+	LastPageURL  string `json:"last_page_url,omitempty"`
+	NextPageURL  string `json:"next_page_url,omitempty"`
+	PrevPageURL  string `json:"prev_page_url,omitempty"`
+}
+
+// Paginate counts the rows the query matches and scans page, counting from
+// 1, of perPage rows into dest, a pointer to a slice. Relationships requested
+// with WithEager are loaded for the page.
+func (qb *QueryBuilder) Paginate(page, perPage int, dest interface{}) (*Paginator, error) {
+	slice, err := pageDestination(perPage, dest)
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	total, err := qb.Count()
+	if err != nil {
+		return nil, err
+	}
+	lastPage := int((total + int64(perPage) - 1) / int64(perPage))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	if page <= lastPage {
+		query := qb.clone()
+		query.limit = perPage
+		query.offset = (page - 1) * perPage
+		if _, err := query.getChunk(slice, dest); err != nil {
+			return nil, err
+		}
+	} else {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	}
+
+	return &Paginator{
+		Items:       slice.Interface(),
+		CurrentPage: page,
+		PerPage:     perPage,
+		Total:       total,
+		LastPage:    lastPage,
+		HasMore:     page < lastPage,
+	}, nil
+}
+
+// SimplePaginate scans a page like Paginate without counting the matching
+// rows, reading one extra row to learn whether another page follows. It
+// suits large tables where the count is slow and a "next" link is enough.
+func (qb *QueryBuilder) SimplePaginate(page, perPage int, dest interface{}) (*Paginator, error) {
+	slice, err := pageDestination(perPage, dest)
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	query := qb.clone()
+	query.limit = perPage + 1
+	query.offset = (page - 1) * perPage
+	count, err := query.getChunk(slice, dest)
+	if err != nil {
+		return nil, err
+	}
+	if count > perPage {
+		slice.Set(slice.Slice(0, perPage))
+	}
+
+	return &Paginator{
+		Items:       slice.Interface(),
+		CurrentPage: page,
+		PerPage:     perPage,
+		Total:       -1,
+		HasMore:     count > perPage,
+	}, nil
+}
+
+// CursorPaginate scans perPage rows into dest, a pointer to a slice, starting
+// after the position cursor encodes, or at the start when it is "". Pages
+// follow the query's OrderBy columns, by id when it has none, and are found
+// with a WHERE on those columns rather than an OFFSET, so deep pages are as
+// fast as the first and rows inserted meanwhile do not shift them. The last
+// order column should be unique, such as id, so rows with equal values are
+// not skipped. Cursors are opaque tokens for the Next and Prev cursors of
+// earlier pages; anything else gives ErrInvalidCursor.
+func (qb *QueryBuilder) CursorPaginate(cursor string, perPage int, dest interface{}) (*CursorPaginator, error) {
+	slice, err := pageDestination(perPage, dest)
+	if err != nil {
+		return nil, err
+	}
+	position, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query := qb.clone()
+	if len(query.orderColumns) == 0 {
+		query.OrderBy("id", "asc")
+	}
+	columns := query.orderColumns
+	if position.Values != nil && len(position.Values) != len(columns) {
+		return nil, ErrInvalidCursor
+	}
+
+	if position.Values != nil {
+		query.groupWheres()
+		query.wheres = append(query.wheres, keysetWhere(query.grammar(), columns, position.Values, position.Before))
+	}
+	if position.Before {
+		// Read backwards from the cursor, then put the page back in order
+		query.orders, query.orderColumns = nil, nil
+		for _, column := range columns {
+			query.OrderBy(column.Column, reverseDirection(column.Direction))
+		}
+	}
+	query.limit = perPage + 1
+	query.offset = 0
+
+	count, err := query.getChunk(slice, dest)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := count > perPage
+	if hasMore {
+		slice.Set(slice.Slice(0, perPage))
+		count = perPage
+	}
+	if position.Before {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, count-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	paginator := &CursorPaginator{Items: slice.Interface(), PerPage: perPage}
+	if count == 0 {
+		return paginator, nil
+	}
+	first, last := slice.Index(0), slice.Index(count-1)
+	if position.Before || hasMore {
+		if paginator.NextCursor, err = qb.encodeCursor(columns, last, false); err != nil {
+			return nil, err
+		}
+	}
+	if (position.Values != nil && !position.Before) || (position.Before && hasMore) {
+		if paginator.PrevCursor, err = qb.encodeCursor(columns, first, true); err != nil {
+			return nil, err
+		}
+	}
+	return paginator, nil
+}
+
+// Respond writes the page as the standard list response, {"success", "data",
+// "meta"}, and sets a Link header to the first, previous, next and last pages
+func (p *Paginator) Respond(c Context) error {
+	meta := PaginationMeta{
+		CurrentPage:  p.CurrentPage,
+		PerPage:      p.PerPage,
+		HasNext:      p.HasMore,
+		HasPrev:      p.CurrentPage > 1,
+		FirstPageURL: pageURL(c, PageParam, "1"),
+	}
+	links := []string{linkValue(meta.FirstPageURL, "first")}
+
+	if meta.HasPrev {
+		meta.PrevPageURL = pageURL(c, PageParam, strconv.Itoa(p.CurrentPage-1))
+		links = append(links, linkValue(meta.PrevPageURL, "prev"))
+	}
+	if meta.HasNext {
+		meta.NextPageURL = pageURL(c, PageParam, strconv.Itoa(p.CurrentPage+1))
+		links = append(links, linkValue(meta.NextPageURL, "next"))
+	}
+	if p.Total >= 0 {
+		total, lastPage := p.Total, p.LastPage
+		meta.Total, meta.TotalPages = &total, &lastPage
+		meta.LastPageURL = pageURL(c, PageParam, strconv.Itoa(p.LastPage))
+		links = append(links, linkValue(meta.LastPageURL, "last"))
+	}
+
+	return respondPage(c, p.Items, meta, links)
+}
+
+// Respond writes the page as the standard list response, {"success", "data",
+// "meta"}, and sets a Link header to the first, previous and next pages
+func (p *CursorPaginator) Respond(c Context) error {
+	meta := PaginationMeta{
+		PerPage:      p.PerPage,
+		HasNext:      p.NextCursor != "",
+		HasPrev:      p.PrevCursor != "",
+		NextCursor:   p.NextCursor,
+		PrevCursor:   p.PrevCursor,
+		FirstPageURL: pageURL(c, CursorParam, ""),
+	}
+	links := []string{linkValue(meta.FirstPageURL, "first")}
+
+	if meta.HasPrev {
+		meta.PrevPageURL = pageURL(c, CursorParam, p.PrevCursor)
+		links = append(links, linkValue(meta.PrevPageURL, "prev"))
+	}
+	if meta.HasNext {
+		meta.NextPageURL = pageURL(c, CursorParam, p.NextCursor)
+		links = append(links, linkValue(meta.NextPageURL, "next"))
+	}
+
+	return respondPage(c, p.Items, meta, links)
+}
+
+// RequestPage returns the page number in the request's "page" query
+// parameter, or 1 when it is missing or not a positive number
+func RequestPage(c Context) int {
+	page, err := strconv.Atoi(c.Query(PageParam))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// RequestPerPage returns the page size in the request's "per_page" query
+// parameter, or fallback when it is missing or invalid, capped at limit
+func RequestPerPage(c Context, fallback, limit int) int {
+	perPage, err := strconv.Atoi(c.Query(PerPageParam))
+	if err != nil || perPage < 1 {
+		perPage = fallback
+	}
+	if limit > 0 && perPage > limit {
+		perPage = limit
+	}
+	return perPage
+}
+
+// RequestCursor returns the request's "cursor" query parameter, "" for the
+// first page
+func RequestCursor(c Context) string {
+	return c.Query(CursorParam)
+}
+
+// respondPage sets the Link header and writes the list envelope
+func respondPage(c Context, items interface{}, meta PaginationMeta, links []string) error {
+	c.SetHeader("Link", strings.Join(links, ", "))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    items,
+		"meta":    meta,
+	})
+}
+
+// pageURL returns the absolute URL of the request with the query parameter
+// key set to value, or removed when value is ""
+func pageURL(c Context, key, value string) string {
+	u := *c.Request().URL
+	query := u.Query()
+	if value == "" {
+		query.Del(key)
+	} else {
+		query.Set(key, value)
+	}
+	u.RawQuery = query.Encode()
+	u.Scheme = c.Scheme()
+	u.Host = c.Host()
+	return u.String()
+}
+
+// linkValue renders one RFC 8288 link-value
+func linkValue(target, rel string) string {
+	return fmt.Sprintf("<%s>; rel=%q", target, rel)
+}
+
+// pageDestination checks the arguments of the paginate methods and returns
+// the slice dest points to
+func pageDestination(perPage int, dest interface{}) (reflect.Value, error) {
+	if perPage <= 0 {
+		return reflect.Value{}, fmt.Errorf("per page must be positive, got %d", perPage)
+	}
+	return sliceDestination(dest)
+}
+
+// cursorPosition is what a pagination cursor encodes: the order column
+// values of the row to continue from, and which way to go
+type cursorPosition struct {
+	Values []interface{} `json:"v"`
+	Before bool          `json:"b,omitempty"`
+}
+
+// cursorTime marks a time value in a cursor, so it is bound as a time again
+type cursorTime struct {
+	Time time.Time `json:"t"`
+}
+
+// encodeCursor returns the cursor continuing after, or before, row
+func (qb *QueryBuilder) encodeCursor(columns []OrderByClause, row reflect.Value, before bool) (string, error) {
+	row = reflect.Indirect(row)
+	position := cursorPosition{Values: make([]interface{}, len(columns)), Before: before}
+
+	for i, column := range columns {
+		name := column.Column
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = name[dot+1:]
+		}
+		field := qb.findFieldValueByColumn(row, name)
+		if !field.IsValid() {
+			return "", fmt.Errorf("%s has no field for order column %s", row.Type(), name)
+		}
+
+		value := field.Interface()
+		if valuer, ok := value.(driver.Valuer); ok {
+			var err error
+			if value, err = valuer.Value(); err != nil {
+				return "", err
+			}
+		} else if field.Kind() == reflect.Ptr {
+			value = nil
+			if !field.IsNil() {
+				value = field.Elem().Interface()
+			}
+		}
+		if t, ok := value.(time.Time); ok {
+			value = cursorTime{Time: t}
+		}
+		position.Values[i] = value
+	}
+
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads a cursor made by encodeCursor; "" is the start
+func decodeCursor(cursor string) (cursorPosition, error) {
+	var position cursorPosition
+	if cursor == "" {
+		return position, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return position, ErrInvalidCursor
+	}
+	var raw struct {
+		Values []json.RawMessage `json:"v"`
+		Before bool              `json:"b"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw.Values) == 0 {
+		return position, ErrInvalidCursor
+	}
+
+	position.Before = raw.Before
+	position.Values = make([]interface{}, len(raw.Values))
+	for i, message := range raw.Values {
+		var t cursorTime
+		if json.Unmarshal(message, &t) == nil && !t.Time.IsZero() {
+			position.Values[i] = t.Time
+			continue
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(string(message)))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return position, ErrInvalidCursor
+		}
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Int64(); err == nil {
+				value = n
+			} else if f, err := number.Float64(); err == nil {
+				value = f
+			}
+		}
+		if _, ok := value.(map[string]interface{}); ok {
+			return position, ErrInvalidCursor
+		}
+		position.Values[i] = value
+	}
+	return position, nil
+}
+
+// keysetWhere returns the condition selecting rows after, or before, values
+// in the order of columns: (a > ?) OR (a = ? AND b > ?) ... NULL values use
+// IS NULL and IS NOT NULL, placed where the dialect sorts NULLs.
+func keysetWhere(g Grammar, columns []OrderByClause, values []interface{}, before bool) whereClause {
+	var alternatives []string
+	var args []interface{}
+
+	for i, column := range columns {
+		var terms []string
+		var termArgs []interface{}
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				terms = append(terms, g.Wrap(columns[j].Column)+" IS NULL")
+				continue
+			}
+			terms = append(terms, g.Wrap(columns[j].Column)+" = ?")
+			termArgs = append(termArgs, values[j])
+		}
+
+		operator := ">"
+		if (column.Direction == "DESC") != before {
+			operator = "<"
+		}
+		// Whether NULLs come before the other values in the direction read
+		nullsFirst := g.NullsFirst() == (operator == ">")
+		wrapped := g.Wrap(column.Column)
+		switch {
+		case values[i] == nil && nullsFirst:
+			terms = append(terms, wrapped+" IS NOT NULL")
+		case values[i] == nil:
+			// Nothing follows the trailing NULLs
+			continue
+		case nullsFirst:
+			terms = append(terms, fmt.Sprintf("%s %s ?", wrapped, operator))
+			termArgs = append(termArgs, values[i])
+		default:
+			terms = append(terms, fmt.Sprintf("(%s %s ? OR %s IS NULL)", wrapped, operator, wrapped))
+			termArgs = append(termArgs, values[i])
+		}
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		args = append(args, termArgs...)
+	}
+	if len(alternatives) == 0 {
+		alternatives = []string{"1 = 0"}
+	}
+
+	return whereClause{
+		column:   "(" + strings.Join(alternatives, " OR ") + ")",
+		operator: "RAW",
+		value:    args,
+		boolean:  "AND",
+	}
+}
+
+// reverseDirection flips an ORDER BY direction
+func reverseDirection(direction string) string {
+	if direction == "DESC" {
+		return "ASC"
+	}
+	return "DESC"
+}
@@ -0,0 +1,252 @@
+package onyx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onyx-go/framework/internal/database/grammar"
+)
+
+func postIDs(rows []chunkRow) string {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestPaginate(t *testing.T) {
+	db := newChunkDB(t, 5)
+
+	var rows []chunkRow
+	page, err := db.Table("posts").OrderBy("id", "asc").Paginate(2, 2, &rows)
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if postIDs(rows) != "[3 4]" || page.Total != 5 || page.LastPage != 3 || !page.HasMore {
+		t.Errorf("Expected page 2 of 3 holding [3 4], got %s %+v", postIDs(rows), page)
+	}
+
+	page, err = db.Table("posts").Paginate(4, 2, &rows)
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if len(rows) != 0 || page.HasMore {
+		t.Errorf("Expected an empty page past the end, got %s %+v", postIDs(rows), page)
+	}
+
+	page, err = db.Table("posts").SimplePaginate(2, 2, &rows)
+	if err != nil {
+		t.Fatalf("SimplePaginate failed: %v", err)
+	}
+	if postIDs(rows) != "[3 4]" || page.Total != -1 || !page.HasMore {
+		t.Errorf("Expected [3 4] with more to come and no count, got %s %+v", postIDs(rows), page)
+	}
+	if page, _ = db.Table("posts").SimplePaginate(3, 2, &rows); postIDs(rows) != "[5]" || page.HasMore {
+		t.Errorf("Expected the last page to hold [5], got %s %+v", postIDs(rows), page)
+	}
+
+	if count, err := db.Table("posts").Where("id", ">", 2).Limit(1).Count(); err != nil || count != 3 {
+		t.Errorf("Expected Count to ignore the limit and find 3 rows, got %d %v", count, err)
+	}
+	if count, err := db.Table("posts").Select("title").GroupBy("title").Count(); err != nil || count != 5 {
+		t.Errorf("Expected Count to count groups, got %d %v", count, err)
+	}
+}
+
+func TestCursorPaginate(t *testing.T) {
+	db := newChunkDB(t, 5)
+
+	// Titles repeat, so the id tiebreaker decides the order within a title
+	db.Table("posts").Where("id", "<=", 3).Update(map[string]interface{}{"title": "a"})
+	db.Table("posts").Where("id", ">", 3).Update(map[string]interface{}{"title": "b"})
+	query := func() *QueryBuilder {
+		return db.Table("posts").OrderBy("title", "desc").OrderBy("id", "asc")
+	}
+
+	var rows []chunkRow
+	var pages []string
+	var cursors []string
+	cursor := ""
+	for {
+		page, err := query().CursorPaginate(cursor, 2, &rows)
+		if err != nil {
+			t.Fatalf("CursorPaginate failed: %v", err)
+		}
+		pages = append(pages, postIDs(rows))
+		cursors = append(cursors, page.PrevCursor)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if fmt.Sprint(pages) != "[[4 5] [1 2] [3]]" {
+		t.Fatalf("Expected pages [[4 5] [1 2] [3]], got %v", pages)
+	}
+	if cursors[0] != "" {
+		t.Error("Expected no previous cursor on the first page")
+	}
+
+	// Walking back from the last page returns the same pages
+	page, err := query().CursorPaginate(cursors[2], 2, &rows)
+	if err != nil || postIDs(rows) != "[1 2]" || page.NextCursor == "" || page.PrevCursor == "" {
+		t.Fatalf("Expected the middle page going back, got %s %+v %v", postIDs(rows), page, err)
+	}
+	page, err = query().CursorPaginate(page.PrevCursor, 2, &rows)
+	if err != nil || postIDs(rows) != "[4 5]" || page.PrevCursor != "" {
+		t.Errorf("Expected the first page with no previous cursor, got %s %+v %v", postIDs(rows), page, err)
+	}
+
+	for _, bad := range []string{"not base64!", "e30", cursors[1] + "x"} {
+		if _, err := query().CursorPaginate(bad, 2, &rows); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", bad, err)
+		}
+	}
+	if _, err := db.Table("posts").OrderBy("id", "asc").CursorPaginate(cursors[1], 2, &rows); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for a cursor from another ordering, got %v", err)
+	}
+
+	// The cursor condition must apply to both sides of the OR
+	orQuery := db.Table("posts").Where("id", "=", 1).OrWhere("id", ">", 3).OrderBy("id", "asc")
+	page, err = orQuery.CursorPaginate("", 2, &rows)
+	if err != nil || postIDs(rows) != "[1 4]" {
+		t.Fatalf("Expected the first page [1 4], got %s %v", postIDs(rows), err)
+	}
+	page, err = orQuery.CursorPaginate(page.NextCursor, 2, &rows)
+	if err != nil || postIDs(rows) != "[5]" || page.NextCursor != "" {
+		t.Errorf("Expected the last page [5] with OrWhere, got %s %+v %v", postIDs(rows), page, err)
+	}
+}
+
+type publishedRow struct {
+	ID          int        `db:"id"`
+	PublishedAt *time.Time `db:"published_at"`
+}
+
+func TestCursorPaginateNulls(t *testing.T) {
+	db := newChunkDB(t, 6)
+	if _, err := db.Exec(`ALTER TABLE posts ADD COLUMN published_at DATETIME`); err != nil {
+		t.Fatalf("Failed to add column: %v", err)
+	}
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	db.Table("posts").Where("id", "=", 2).Update(map[string]interface{}{"published_at": published})
+	db.Table("posts").Where("id", "=", 4).Update(map[string]interface{}{"published_at": published.Add(time.Hour)})
+
+	walk := func(direction string) ([]string, []string) {
+		var rows []publishedRow
+		var pages, prev []string
+		cursor := ""
+		for len(pages) < 6 {
+			page, err := db.Table("posts").OrderBy("published_at", direction).OrderBy("id", "asc").CursorPaginate(cursor, 2, &rows)
+			if err != nil {
+				t.Fatalf("CursorPaginate failed: %v", err)
+			}
+			ids := make([]int, len(rows))
+			for i, row := range rows {
+				ids[i] = row.ID
+			}
+			pages = append(pages, fmt.Sprint(ids))
+			prev = append(prev, page.PrevCursor)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		return pages, prev
+	}
+
+	// SQLite sorts NULLs first going up and last going down
+	if pages, _ := walk("asc"); fmt.Sprint(pages) != "[[1 3] [5 6] [2 4]]" {
+		t.Errorf("Expected NULL rows first in ascending order, got %v", pages)
+	}
+	pages, prev := walk("desc")
+	if fmt.Sprint(pages) != "[[4 2] [1 3] [5 6]]" {
+		t.Fatalf("Expected NULL rows last in descending order, got %v", pages)
+	}
+
+	var rows []publishedRow
+	if _, err := db.Table("posts").OrderBy("published_at", "desc").OrderBy("id", "asc").CursorPaginate(prev[2], 2, &rows); err != nil ||
+		len(rows) != 2 || rows[0].ID != 1 || rows[1].ID != 3 {
+		t.Errorf("Expected to walk back from a NULL cursor to [1 3], got %+v %v", rows, err)
+	}
+	page, err := db.Table("posts").OrderBy("published_at", "desc").OrderBy("id", "asc").CursorPaginate(prev[1], 2, &rows)
+	if err != nil || len(rows) != 2 || rows[0].ID != 4 || rows[1].ID != 2 || page.PrevCursor != "" {
+		t.Errorf("Expected to walk back across the NULLs to [4 2], got %+v %+v %v", rows, page, err)
+	}
+	// PostgreSQL sorts NULLs last going up, so they follow every value
+	columns := []OrderByClause{{Column: "published_at", Direction: "ASC"}, {Column: "id", Direction: "ASC"}}
+	where := keysetWhere(grammar.Postgres, columns, []interface{}{published, int64(2)}, false)
+	want := `((("published_at" > ? OR "published_at" IS NULL)) OR ("published_at" = ? AND ("id" > ? OR "id" IS NULL)))`
+	if where.column != want || len(where.value.([]interface{})) != 3 {
+		t.Errorf("Unexpected keyset condition %s %v", where.column, where.value)
+	}
+	where = keysetWhere(grammar.Postgres, columns, []interface{}{nil, int64(5)}, false)
+	if want := `(("published_at" IS NULL AND ("id" > ? OR "id" IS NULL)))`; where.column != want {
+		t.Errorf("Unexpected keyset condition after a NULL %s", where.column)
+	}
+}
+
+func TestPaginatorRespond(t *testing.T) {
+	db := newChunkDB(t, 5)
+
+	req := httptest.NewRequest("GET", "http://api.example.com/posts?page=2&per_page=2&sort=id", nil)
+	w := httptest.NewRecorder()
+	c := NewContext(w, req, nil)
+	if RequestPage(c) != 2 || RequestPerPage(c, 20, 100) != 2 || RequestCursor(c) != "" {
+		t.Fatalf("Expected page 2 of size 2 from the query string")
+	}
+
+	var rows []chunkRow
+	page, err := db.Table("posts").Paginate(RequestPage(c), RequestPerPage(c, 20, 100), &rows)
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if err := page.Respond(c); err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+
+	want := `<http://api.example.com/posts?page=1&per_page=2&sort=id>; rel="first", ` +
+		`<http://api.example.com/posts?page=1&per_page=2&sort=id>; rel="prev", ` +
+		`<http://api.example.com/posts?page=3&per_page=2&sort=id>; rel="next", ` +
+		`<http://api.example.com/posts?page=3&per_page=2&sort=id>; rel="last"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Errorf("Unexpected Link header:\n got %s\nwant %s", got, want)
+	}
+
+	var body struct {
+		Success bool           `json:"success"`
+		Data    []chunkRow     `json:"data"`
+		Meta    PaginationMeta `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if !body.Success || len(body.Data) != 2 || body.Meta.CurrentPage != 2 || body.Meta.Total == nil || *body.Meta.Total != 5 ||
+		*body.Meta.TotalPages != 3 || !body.Meta.HasNext || !body.Meta.HasPrev || !strings.HasSuffix(body.Meta.NextPageURL, "page=3&per_page=2&sort=id") {
+		t.Errorf("Unexpected envelope: %s", w.Body.String())
+	}
+
+	// Cursor pages link by cursor and leave out the count
+	req = httptest.NewRequest("GET", "http://api.example.com/posts", nil)
+	w = httptest.NewRecorder()
+	c = NewContext(w, req, nil)
+	cursorPage, err := db.Table("posts").CursorPaginate(RequestCursor(c), 2, &rows)
+	if err != nil {
+		t.Fatalf("CursorPaginate failed: %v", err)
+	}
+	if err := cursorPage.Respond(c); err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	wantNext := "http://api.example.com/posts?cursor=" + cursorPage.NextCursor
+	if link := w.Header().Get("Link"); !strings.Contains(link, "<"+wantNext+`>; rel="next"`) || strings.Contains(link, `rel="prev"`) {
+		t.Errorf("Unexpected Link header: %s", link)
+	}
+	if body := w.Body.String(); strings.Contains(body, `"total"`) || !strings.Contains(body, `"next_cursor":"`+cursorPage.NextCursor+`"`) {
+		t.Errorf("Unexpected envelope: %s", body)
+	}
+}
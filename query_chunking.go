@@ -0,0 +1,274 @@
+package onyx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+
+	databaseInternal "github.com/onyx-go/framework/internal/database"
+	"github.com/onyx-go/framework/internal/tracing"
+)
+
+// ErrStopChunking can be returned from a Chunk or ChunkByID callback to stop
+// after the current chunk without reporting an error
+var ErrStopChunking = databaseInternal.ErrStopChunking
+
+// Chunk runs the query size rows at a time, scanning each chunk into dest, a
+// pointer to a slice, and calling fn. dest is replaced for every chunk, so
+// only one chunk is held in memory, and relationships requested with
+// WithEager are loaded for each chunk. Chunks are read with LIMIT and OFFSET
+// in the query's order, or by id when it has none, so rows changed meanwhile
+// may be skipped or read twice; ChunkByID avoids that.
+func (qb *QueryBuilder) Chunk(size int, dest interface{}, fn func() error) error {
+	slice, err := chunkDestination(size, dest)
+	if err != nil {
+		return err
+	}
+
+	base := qb.clone()
+	if len(base.orders) == 0 {
+		base.OrderBy("id", "asc")
+	}
+
+	for page := 0; ; page++ {
+		chunk := base.clone()
+		chunk.limit = size
+		chunk.offset = page * size
+
+		count, err := chunk.getChunk(slice, dest)
+		if err != nil || count == 0 {
+			return err
+		}
+		if err := fn(); err != nil {
+			if errors.Is(err, ErrStopChunking) {
+				return nil
+			}
+			return err
+		}
+		if count < size {
+			return nil
+		}
+	}
+}
+
+// ChunkByID runs the query size rows at a time like Chunk, but pages by
+// column instead of by offset: each chunk starts after the last value of
+// column seen, in ascending order. Rows inserted or updated while chunking,
+// as a nightly export runs, are neither skipped nor repeated as long as
+// column does not change. The query's own ordering is replaced.
+func (qb *QueryBuilder) ChunkByID(size int, column string, dest interface{}, fn func() error) error {
+	slice, err := chunkDestination(size, dest)
+	if err != nil {
+		return err
+	}
+
+	// The value is read back from the field named after the unqualified column
+	field := column
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		field = column[i+1:]
+	}
+
+	var last interface{}
+	for {
+		chunk := qb.clone()
+		chunk.orders, chunk.orderColumns = nil, nil
+		chunk.OrderBy(column, "asc")
+		chunk.limit = size
+		chunk.offset = 0
+		if last != nil {
+			chunk.groupWheres()
+			chunk.Where(column, ">", last)
+		}
+
+		count, err := chunk.getChunk(slice, dest)
+		if err != nil || count == 0 {
+			return err
+		}
+
+		row := reflect.Indirect(slice.Index(count - 1))
+		value := qb.findFieldValueByColumn(row, field)
+		if !value.IsValid() {
+			return fmt.Errorf("%s has no field for column %s", row.Type(), field)
+		}
+		last = value.Interface()
+
+		if err := fn(); err != nil {
+			if errors.Is(err, ErrStopChunking) {
+				return nil
+			}
+			return err
+		}
+		if count < size {
+			return nil
+		}
+	}
+}
+
+// getChunk scans one chunk into a fresh slice behind dest, eager loads its
+// relationships and returns its length
+func (qb *QueryBuilder) getChunk(slice reflect.Value, dest interface{}) (int, error) {
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, qb.limit))
+	if err := qb.Get(dest); err != nil {
+		return 0, err
+	}
+
+	count := slice.Len()
+	if qb.eagerLoadEngine != nil && count > 0 {
+		// Relationships are set on the elements themselves, so pass pointers
+		models := make([]interface{}, count)
+		for i := range models {
+			element := slice.Index(i)
+			if element.Kind() != reflect.Ptr {
+				element = element.Addr()
+			}
+			models[i] = element.Interface()
+		}
+		if err := qb.loadRelationships(models); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// chunkDestination checks the arguments of Chunk and ChunkByID and returns
+// the slice dest points to
+func chunkDestination(size int, dest interface{}) (reflect.Value, error) {
+	if size <= 0 {
+		return reflect.Value{}, fmt.Errorf("chunk size must be positive, got %d", size)
+	}
+	return sliceDestination(dest)
+}
+
+// sliceDestination returns the slice dest points to
+func sliceDestination(dest interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, fmt.Errorf("dest must be a pointer to a slice")
+	}
+	return value.Elem(), nil
+}
+
+// groupWheres replaces the builder's conditions with a single parenthesized
+// one, so a condition added afterwards applies to every OR branch rather than
+// only the last
+func (qb *QueryBuilder) groupWheres() {
+	if len(qb.wheres) == 0 {
+		return
+	}
+	clause, args := qb.buildWhereClause(qb.wheres)
+	qb.wheres = []whereClause{{column: "(" + clause + ")", operator: "RAW", value: args, boolean: "AND"}}
+}
+
+// clone returns a copy of the builder that can be changed without affecting
+// qb
+func (qb *QueryBuilder) clone() *QueryBuilder {
+	clone := *qb
+	clone.selects = append([]string(nil), qb.selects...)
+	clone.wheres = append([]whereClause(nil), qb.wheres...)
+	clone.orders = append([]string(nil), qb.orders...)
+	clone.orderColumns = append([]OrderByClause(nil), qb.orderColumns...)
+	clone.joins = append([]string(nil), qb.joins...)
+	clone.groupBy = append([]string(nil), qb.groupBy...)
+	clone.having = append([]whereClause(nil), qb.having...)
+	clone.bindings = append([]interface{}(nil), qb.bindings...)
+	return &clone
+}
+
+// Cursor reads the rows of a query one at a time, holding a connection until
+// it is closed. The query's timeout covers the cursor's whole life.
+type Cursor struct {
+	qb     *QueryBuilder
+	rows   *sql.Rows
+	ctx    context.Context
+	cancel context.CancelFunc
+	span   *tracing.Span
+}
+
+// Cursor runs the query and returns a cursor over its rows, for result sets
+// too large to load with Get. The cursor must be closed.
+func (qb *QueryBuilder) Cursor() (*Cursor, error) {
+	query, args := qb.buildSelectQuery()
+
+	ctx, cancel := qb.queryContext()
+	ctx, span := tracing.StartQuery(ctx, qb.db.driver, "SELECT", qb.table, query)
+
+	rows, err := qb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = databaseInternal.WrapCanceled(ctx, err)
+		span.RecordError(err)
+		span.End()
+		cancel()
+		return nil, err
+	}
+	return &Cursor{qb: qb, rows: rows, ctx: ctx, cancel: cancel, span: span}, nil
+}
+
+// Next advances to the next row, returning false when there are no more rows
+// or an error occurred, which Err reports
+func (c *Cursor) Next() bool {
+	return c.rows.Next()
+}
+
+// Scan scans the current row into dest, a pointer to a struct
+func (c *Cursor) Scan(dest interface{}) error {
+	return databaseInternal.WrapCanceled(c.ctx, c.qb.scanIntoStruct(c.rows, dest))
+}
+
+// Err returns the error, if any, that stopped Next
+func (c *Cursor) Err() error {
+	return databaseInternal.WrapCanceled(c.ctx, c.rows.Err())
+}
+
+// Close releases the cursor's connection. It is safe to call more than once.
+func (c *Cursor) Close() error {
+	err := c.rows.Close()
+	if c.cancel != nil {
+		c.span.RecordError(c.Err())
+		c.span.End()
+		c.cancel()
+		c.cancel = nil
+	}
+	return err
+}
+
+// Iterate runs query and yields its rows one at a time as T, a struct type,
+// for use with range:
+//
+//	for user, err := range onyx.Iterate[User](db.Table("users")) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Iteration stops after the first error, which is yielded with the zero T,
+// and the rows are released when the loop ends.
+func Iterate[T any](query *QueryBuilder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor, err := query.Cursor()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer cursor.Close()
+
+		for cursor.Next() {
+			var row T
+			if err := cursor.Scan(&row); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
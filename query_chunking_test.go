@@ -0,0 +1,180 @@
+package onyx
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type chunkRow struct {
+	ID    int    `db:"id"`
+	Title string `db:"title"`
+}
+
+func newChunkDB(t *testing.T, rows int) *DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if _, err := sqlDB.Exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, deleted_at DATETIME)`); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	db := &DB{DB: sqlDB, driver: "sqlite3"}
+	for i := 1; i <= rows; i++ {
+		if _, err := db.Table("posts").Insert(map[string]interface{}{"title": fmt.Sprintf("post %d", i)}); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	return db
+}
+
+func TestChunk(t *testing.T) {
+	db := newChunkDB(t, 5)
+
+	var chunk []chunkRow
+	var sizes []int
+	var ids []int
+	err := db.Table("posts").Chunk(2, &chunk, func() error {
+		sizes = append(sizes, len(chunk))
+		for _, row := range chunk {
+			ids = append(ids, row.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" || fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("Expected chunks of [2 2 1] covering every row, got %v with ids %v", sizes, ids)
+	}
+
+	calls := 0
+	err = db.Table("posts").Chunk(2, &chunk, func() error {
+		calls++
+		return ErrStopChunking
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("Expected ErrStopChunking to stop quietly after one chunk, got %v after %d", err, calls)
+	}
+
+	failure := errors.New("export failed")
+	if err := db.Table("posts").Chunk(2, &chunk, func() error { return failure }); !errors.Is(err, failure) {
+		t.Errorf("Expected the callback's error, got %v", err)
+	}
+	if err := db.Table("posts").Chunk(0, &chunk, func() error { return nil }); err == nil {
+		t.Error("Expected an error for a zero chunk size")
+	}
+}
+
+func TestChunkByID(t *testing.T) {
+	db := newChunkDB(t, 5)
+
+	// Deleting rows already read would make offset paging skip rows
+	var chunk []chunkRow
+	var ids []int
+	err := db.Table("posts").Where("id", ">", 1).ChunkByID(2, "id", &chunk, func() error {
+		for _, row := range chunk {
+			ids = append(ids, row.ID)
+			if _, err := db.Table("posts").Where("id", "=", row.ID).ForceDelete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChunkByID failed: %v", err)
+	}
+	if fmt.Sprint(ids) != "[2 3 4 5]" {
+		t.Errorf("Expected every matching row once, got %v", ids)
+	}
+
+	// The keyset condition must apply to both sides of the OR
+	db = newChunkDB(t, 5)
+	ids = nil
+	err = db.Table("posts").Where("id", "=", 1).OrWhere("id", ">", 3).ChunkByID(1, "id", &chunk, func() error {
+		if len(ids) > 5 {
+			return errors.New("chunking did not stop")
+		}
+		ids = append(ids, chunk[0].ID)
+		return nil
+	})
+	if err != nil || fmt.Sprint(ids) != "[1 4 5]" {
+		t.Errorf("Expected [1 4 5] with OrWhere, got %v %v", ids, err)
+	}
+}
+
+func TestChunkEagerLoading(t *testing.T) {
+	db := newChunkDB(t, 5)
+
+	loads := 0
+	RegisterRelationship("chunkRow", "comments", func() Relationship {
+		loads++
+		// No local key value, so loading finds nothing to query
+		return NewHasMany(&chunkRow{}, &RelComment{}, "post_id", "missing")
+	})
+
+	var chunk []chunkRow
+	err := db.Table("posts").WithEager("comments").Chunk(2, &chunk, func() error { return nil })
+	if err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	if loads != 3 {
+		t.Errorf("Expected relationships to be loaded once per chunk, got %d loads", loads)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	db := newChunkDB(t, 3)
+
+	cursor, err := db.Table("posts").OrderBy("id", "desc").Cursor()
+	if err != nil {
+		t.Fatalf("Cursor failed: %v", err)
+	}
+	var titles []string
+	for cursor.Next() {
+		var row chunkRow
+		if err := cursor.Scan(&row); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		titles = append(titles, row.Title)
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatalf("Cursor error: %v", err)
+	}
+	if err := cursor.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if fmt.Sprint(titles) != "[post 3 post 2 post 1]" {
+		t.Errorf("Expected rows in query order, got %v", titles)
+	}
+
+	// Breaking out of the loop releases the single connection
+	var seen []int
+	for row, err := range Iterate[chunkRow](db.Table("posts")) {
+		if err != nil {
+			t.Fatalf("Iterate failed: %v", err)
+		}
+		seen = append(seen, row.ID)
+		if len(seen) == 2 {
+			break
+		}
+	}
+	if fmt.Sprint(seen) != "[1 2]" {
+		t.Errorf("Expected the first two rows, got %v", seen)
+	}
+	var all []chunkRow
+	if err := db.Table("posts").Get(&all); err != nil || len(all) != 3 {
+		t.Errorf("Expected the connection to be free after breaking, got %v with %d rows", err, len(all))
+	}
+
+	for _, err := range Iterate[chunkRow](db.Table("missing")) {
+		if err == nil {
+			t.Error("Expected an error for a missing table")
+		}
+	}
+}
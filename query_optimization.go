@@ -6,6 +6,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	databaseInternal "github.com/onyx-go/framework/internal/database"
 )
 
 // QueryOptimizer provides query optimization features
@@ -125,7 +127,8 @@ func (oqb *OptimizedQueryBuilder) buildOptimizedQuery() (string, []interface{})
 	
 	// Add index hints for MySQL
 	if oqb.optimizer.enableIndexHints && oqb.optimizer.db.driver == "mysql" {
-		tablePart := oqb.table
+		table := oqb.grammar().Wrap(oqb.table)
+		tablePart := table
 		
 		if oqb.forceIndex != "" {
 			tablePart += fmt.Sprintf(" FORCE INDEX (%s)", oqb.forceIndex)
@@ -136,7 +139,7 @@ func (oqb *OptimizedQueryBuilder) buildOptimizedQuery() (string, []interface{})
 		}
 		
 		// Replace table name with table + index hint
-		query = strings.Replace(query, "FROM "+oqb.table, "FROM "+tablePart, 1)
+		query = strings.Replace(query, "FROM "+table, "FROM "+tablePart, 1)
 	}
 	
 	// Add custom query hints
@@ -169,20 +172,23 @@ func (oqb *OptimizedQueryBuilder) GetOptimized() ([]map[string]interface{}, erro
 	var rows *sql.Rows
 	var err error
 	
+	ctx, cancel := oqb.queryContext()
+	defer cancel()
+	
 	if oqb.optimizer.enablePreparedStmts {
 		// Use prepared statement
 		stmt := oqb.optimizer.getPreparedStatement(query)
 		if stmt != nil {
-			rows, err = stmt.Query(args...)
+			rows, err = stmt.QueryContext(ctx, args...)
 		} else {
-			rows, err = oqb.db.Query(query, args...)
+			rows, err = oqb.db.QueryContext(ctx, query, args...)
 		}
 	} else {
-		rows, err = oqb.db.Query(query, args...)
+		rows, err = oqb.db.QueryContext(ctx, query, args...)
 	}
 	
 	if err != nil {
-		return nil, err
+		return nil, databaseInternal.WrapCanceled(ctx, err)
 	}
 	defer rows.Close()
 	
@@ -439,8 +445,14 @@ func (qp *QueryProfiler) ProfileQuery(query string, params []interface{}, fn fun
 		Parameters: params,
 	}
 	
+	outcome := "success"
 	if err != nil {
 		profile.Error = err.Error()
+		outcome = "error"
+	}
+	queryDuration.With(outcome).Observe(duration.Seconds())
+	if duration > qp.slowQueryThreshold {
+		slowQueries.With().Inc()
 	}
 	
 	// Try to get rows affected if result supports it
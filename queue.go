@@ -1,9 +1,12 @@
 package onyx
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
 )
 
 type Job interface {
@@ -33,6 +36,16 @@ type Queue interface {
 	Clear(queue string) error
 }
 
+// MetadataJob is a job that carries metadata alongside its payload. Queues
+// store the metadata with the job so that the request ID and trace context of
+// the request that dispatched it reach the worker that handles it. BaseJob
+// implements it.
+type MetadataJob interface {
+	Job
+	GetMetadata() map[string]interface{}
+	SetMetadata(metadata map[string]interface{})
+}
+
 type QueueManager interface {
 	Connection(name ...string) Queue
 	Push(job Job) error
@@ -65,6 +78,7 @@ type JobPayload struct {
 	MaxTries    int                    `json:"maxTries"`
 	Timeout     int                    `json:"timeout"`
 	Data        map[string]interface{} `json:"data"`
+	Metadata    map[string]interface{} `json:"metadata"`
 	Queue       string                 `json:"queue"`
 	Attempts    int                    `json:"attempts"`
 	CreatedAt   time.Time              `json:"created_at"`
@@ -77,6 +91,8 @@ type BaseJob struct {
 	maxTries  int
 	timeout   time.Duration
 	payload   map[string]interface{}
+	metadata  map[string]interface{}
+	ctx       context.Context
 }
 
 func NewBaseJob() *BaseJob {
@@ -108,6 +124,30 @@ func (bj *BaseJob) GetPayload() map[string]interface{} {
 	return bj.payload
 }
 
+// GetMetadata returns the job's metadata
+func (bj *BaseJob) GetMetadata() map[string]interface{} {
+	return bj.metadata
+}
+
+// SetMetadata replaces the job's metadata
+func (bj *BaseJob) SetMetadata(metadata map[string]interface{}) {
+	bj.metadata = metadata
+}
+
+// Context returns the context the job is handled in. Inside a worker it
+// carries the request ID and trace context of the request that dispatched the
+// job; pass it on to logging, queries and HTTP calls made by Handle.
+func (bj *BaseJob) Context() context.Context {
+	if bj.ctx == nil {
+		return context.Background()
+	}
+	return bj.ctx
+}
+
+func (bj *BaseJob) setContext(ctx context.Context) {
+	bj.ctx = ctx
+}
+
 func (bj *BaseJob) OnQueue(queue string) QueueableJob {
 	bj.queue = queue
 	return bj
@@ -161,6 +201,7 @@ func (mq *MemoryQueue) LaterOn(queue string, delay time.Duration, job Job) error
 		MaxTries:    job.GetMaxTries(),
 		Timeout:     int(job.GetTimeout().Seconds()),
 		Data:        job.GetPayload(),
+		Metadata:    jobMetadata(job),
 		Queue:       queue,
 		Attempts:    0,
 		CreatedAt:   time.Now(),
@@ -233,6 +274,7 @@ func (mq *MemoryQueue) createJobFromPayload(payload *JobPayload) Job {
 			maxTries: payload.MaxTries,
 			timeout:  time.Duration(payload.Timeout) * time.Second,
 			payload:  payload.Data,
+			metadata: payload.Metadata,
 		},
 		id:       payload.ID,
 		attempts: payload.Attempts,
@@ -248,8 +290,11 @@ type QueueJob struct {
 
 func (qj *QueueJob) Handle() error {
 	if handler, exists := qj.payload["handler"]; exists {
-		if handlerFunc, ok := handler.(func() error); ok {
+		switch handlerFunc := handler.(type) {
+		case func() error:
 			return handlerFunc()
+		case func(context.Context) error:
+			return handlerFunc(qj.Context())
 		}
 	}
 	return fmt.Errorf("no handler found for job")
@@ -260,6 +305,7 @@ type QueueWorker struct {
 	options   WorkerOptions
 	running   bool
 	stopCh    chan struct{}
+	done      chan struct{}
 	mutex     sync.RWMutex
 }
 
@@ -274,6 +320,7 @@ func (qw *QueueWorker) Work(queue Queue, options WorkerOptions) {
 	qw.queue = queue
 	qw.options = options
 	qw.running = true
+	qw.done = make(chan struct{})
 	qw.mutex.Unlock()
 
 	go qw.runWorker()
@@ -295,7 +342,26 @@ func (qw *QueueWorker) IsRunning() bool {
 	return qw.running
 }
 
+// Wait blocks until the worker has stopped and finished its current job, or
+// until ctx is done
+func (qw *QueueWorker) Wait(ctx context.Context) error {
+	qw.mutex.RLock()
+	done := qw.done
+	qw.mutex.RUnlock()
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (qw *QueueWorker) runWorker() {
+	defer close(qw.done)
 	ticker := time.NewTicker(qw.options.Sleep)
 	defer ticker.Stop()
 
@@ -341,10 +407,24 @@ func (qw *QueueWorker) shouldStop(processedJobs int, startTime time.Time) bool {
 }
 
 func (qw *QueueWorker) processJob(job Job) bool {
+	// Restore the request ID and trace context of whoever queued the job
+	ctx := tracing.ExtractMetadata(context.Background(), jobMetadata(job))
+	ctx, span := tracing.Start(ctx, job.GetQueue()+" process", tracing.WithKind(tracing.SpanKindConsumer), tracing.WithAttributes(map[string]interface{}{
+		"messaging.system":           "onyx",
+		"messaging.operation.type":   "process",
+		"messaging.destination.name": job.GetQueue(),
+	}))
+	defer span.End()
+	if contextual, ok := job.(interface{ setContext(context.Context) }); ok {
+		contextual.setContext(ctx)
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Printf("Job panicked: %v\n", r)
-			job.Failed(fmt.Errorf("job panicked: %v", r))
+			err := fmt.Errorf("job panicked: %v", r)
+			span.RecordError(err)
+			job.Failed(err)
 		}
 	}()
 
@@ -363,17 +443,62 @@ func (qw *QueueWorker) processJob(job Job) bool {
 	case err := <-done:
 		if err != nil {
 			fmt.Printf("Job failed: %v\n", err)
+			span.RecordError(err)
 			job.Failed(err)
 			return false
 		}
 		return true
 	case <-time.After(timeout):
 		fmt.Printf("Job timed out after %v\n", timeout)
-		job.Failed(fmt.Errorf("job timed out"))
+		err := fmt.Errorf("job timed out")
+		span.RecordError(err)
+		job.Failed(err)
 		return false
 	}
 }
 
+// WorkerPool tracks the queue workers started by an application so they can
+// be stopped together on shutdown
+type WorkerPool struct {
+	workers []*QueueWorker
+	mutex   sync.Mutex
+}
+
+// NewWorkerPool creates an empty worker pool
+func NewWorkerPool() *WorkerPool {
+	return &WorkerPool{}
+}
+
+// Work starts a worker on queue and adds it to the pool
+func (wp *WorkerPool) Work(queue Queue, options WorkerOptions) *QueueWorker {
+	worker := NewQueueWorker()
+	worker.Work(queue, options)
+
+	wp.mutex.Lock()
+	wp.workers = append(wp.workers, worker)
+	wp.mutex.Unlock()
+	return worker
+}
+
+// StopAll stops every worker in the pool and waits for their current jobs
+// to finish, giving up when ctx is done
+func (wp *WorkerPool) StopAll(ctx context.Context) error {
+	wp.mutex.Lock()
+	workers := wp.workers
+	wp.workers = nil
+	wp.mutex.Unlock()
+
+	for _, worker := range workers {
+		worker.Stop()
+	}
+	for _, worker := range workers {
+		if err := worker.Wait(ctx); err != nil {
+			return fmt.Errorf("queue workers did not stop: %w", err)
+		}
+	}
+	return nil
+}
+
 type DefaultQueueManager struct {
 	connections map[string]Queue
 	defaultConn string
@@ -433,6 +558,15 @@ func (qm *DefaultQueueManager) RegisterConnection(name string, queue Queue) {
 	qm.connections[name] = queue
 }
 
+// jobMetadata returns the metadata of job, if it carries any
+func jobMetadata(job Job) map[string]interface{} {
+	if metadataJob, ok := job.(MetadataJob); ok {
+		return metadataJob.GetMetadata()
+	}
+	return nil
+}
+
+
 func generateJobID() string {
 	return fmt.Sprintf("job_%d", time.Now().UnixNano())
 }
@@ -486,27 +620,49 @@ func (pij *ProcessImageJob) Failed(err error) {
 }
 
 func DispatchJob(job Job) error {
-	queueManager, _ := globalApp.Container().Make("queue")
-	if qm, ok := queueManager.(QueueManager); ok {
+	return dispatch(context.Background(), job.GetQueue(), job, func(qm QueueManager) error {
 		return qm.Push(job)
-	}
-	return fmt.Errorf("queue manager not configured")
+	})
 }
 
 func DispatchJobOn(queue string, job Job) error {
-	queueManager, _ := globalApp.Container().Make("queue")
-	if qm, ok := queueManager.(QueueManager); ok {
+	return dispatch(context.Background(), queue, job, func(qm QueueManager) error {
 		return qm.PushOn(queue, job)
-	}
-	return fmt.Errorf("queue manager not configured")
+	})
 }
 
 func DispatchJobLater(delay time.Duration, job Job) error {
-	queueManager, _ := globalApp.Container().Make("queue")
-	if qm, ok := queueManager.(QueueManager); ok {
+	return dispatch(context.Background(), job.GetQueue(), job, func(qm QueueManager) error {
 		return qm.Later(delay, job)
+	})
+}
+
+// dispatch pushes job to the global queue manager in a publish span. The
+// request ID and trace context of ctx, with the span as the current one, are
+// stored in the job's metadata so the worker handling it continues the trace.
+func dispatch(ctx context.Context, queue string, job Job, push func(qm QueueManager) error) error {
+	ctx, span := tracing.Start(ctx, queue+" publish", tracing.WithKind(tracing.SpanKindProducer), tracing.WithAttributes(map[string]interface{}{
+		"messaging.system":           "onyx",
+		"messaging.operation.type":   "publish",
+		"messaging.destination.name": queue,
+	}))
+	defer span.End()
+
+	if metadataJob, ok := job.(MetadataJob); ok {
+		metadataJob.SetMetadata(tracing.InjectMetadata(ctx, metadataJob.GetMetadata()))
 	}
-	return fmt.Errorf("queue manager not configured")
+
+	queueManager, _ := globalApp.Container().Make("queue")
+	qm, ok := queueManager.(QueueManager)
+	if !ok {
+		err := fmt.Errorf("queue manager not configured")
+		span.RecordError(err)
+		return err
+	}
+
+	err := push(qm)
+	span.RecordError(err)
+	return err
 }
 
 var globalApp *Application
@@ -515,18 +671,27 @@ func (app *Application) SetGlobal() {
 	globalApp = app
 }
 
+// DispatchJobWithContext dispatches job carrying the request ID and trace
+// context of the current request, which the worker restores in the job's
+// Context
 func DispatchJobWithContext(c Context, job Job) error {
 	// Use global functions since Application interface doesn't expose Container
 	// TODO: Extend Application interface to provide access to Container/QueueManager
-	return DispatchJob(job)
+	return dispatch(c.Request().Context(), job.GetQueue(), job, func(qm QueueManager) error {
+		return qm.Push(job)
+	})
 }
 
 func DispatchJobOnWithContext(c Context, queue string, job Job) error {
 	// Use global functions since Application interface doesn't expose Container
-	return DispatchJobOn(queue, job)
+	return dispatch(c.Request().Context(), queue, job, func(qm QueueManager) error {
+		return qm.PushOn(queue, job)
+	})
 }
 
 func DispatchJobLaterWithContext(c Context, delay time.Duration, job Job) error {
 	// Use global functions since Application interface doesn't expose Container
-	return DispatchJobLater(delay, job)
+	return dispatch(c.Request().Context(), job.GetQueue(), job, func(qm QueueManager) error {
+		return qm.Later(delay, job)
+	})
 }
\ No newline at end of file
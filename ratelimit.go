@@ -69,13 +69,10 @@ type RateLimitHandler func(c Context, result *RateLimitResult)
 
 // Default key generators
 
-// IPKeyGenerator generates keys based on client IP
+// IPKeyGenerator generates keys based on client IP. Behind a proxy the
+// proxy must be trusted for the forwarded client address to be used.
 func IPKeyGenerator(c Context) string {
-	ip := c.Request().RemoteAddr
-	if colonIndex := strings.LastIndex(ip, ":"); colonIndex != -1 {
-		ip = ip[:colonIndex]
-	}
-	return fmt.Sprintf("rate_limit:ip:%s", ip)
+	return fmt.Sprintf("rate_limit:ip:%s", c.RemoteIP())
 }
 
 // UserKeyGenerator generates keys based on authenticated user ID
@@ -1,6 +1,7 @@
 package onyx
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -21,6 +22,13 @@ type Relationship interface {
 	GetLocalKey() string
 }
 
+// GetResultsContext gets the results of a relationship with its query
+// running under ctx, so cancelling ctx stops the query with ErrQueryCanceled
+func GetResultsContext(ctx context.Context, relationship Relationship) (interface{}, error) {
+	relationship.GetQuery().WithContext(ctx)
+	return relationship.GetResults()
+}
+
 // RelationshipType defines the type of relationship
 type RelationshipType string
 
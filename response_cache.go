@@ -131,8 +131,8 @@ func ResponseCacheMiddleware(config ...ResponseCacheConfig) MiddlewareFunc {
 		// Restore original writer
 		replaceResponseWriterForCache(c, originalWriter)
 		
-		// Cache the response if successful
-		if err == nil && recorder.statusCode >= 200 && recorder.statusCode < 400 {
+		// Cache the response if successful; streamed responses are never cached
+		if err == nil && !recorder.streaming && recorder.statusCode >= 200 && recorder.statusCode < 400 {
 			cache.store(cacheKey, recorder, cache.config.DefaultTTL)
 		}
 		
@@ -146,6 +146,7 @@ type ResponseRecorder struct {
 	body       []byte
 	statusCode int
 	headers    http.Header
+	streaming  bool
 }
 
 // WriteHeader captures the status code
@@ -157,6 +158,7 @@ func (rr *ResponseRecorder) WriteHeader(statusCode int) {
 		for k, v := range rr.ResponseWriter.Header() {
 			rr.headers[k] = v
 		}
+		rr.streaming = isStreamingContentType(rr.headers.Get("Content-Type"))
 	}
 	rr.ResponseWriter.WriteHeader(statusCode)
 }
@@ -170,11 +172,27 @@ func (rr *ResponseRecorder) Write(data []byte) (int, error) {
 		for k, v := range rr.ResponseWriter.Header() {
 			rr.headers[k] = v
 		}
+		rr.streaming = isStreamingContentType(rr.headers.Get("Content-Type"))
+	}
+	if !rr.streaming {
+		rr.body = append(rr.body, data...)
 	}
-	rr.body = append(rr.body, data...)
 	return rr.ResponseWriter.Write(data)
 }
 
+// Flush passes the data straight to the client. A flushed response is a
+// stream, so it stops being recorded and will not be cached.
+func (rr *ResponseRecorder) Flush() {
+	rr.streaming = true
+	rr.body = nil
+	http.NewResponseController(rr.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rr *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
 // shouldCache determines if a request should be cached
 func (rc *ResponseCache) shouldCache(req *http.Request) bool {
 	// Check method
@@ -244,6 +262,7 @@ func (rc *ResponseCache) get(key string) *CachedResponse {
 	cached, exists := rc.cache[key]
 	if !exists {
 		rc.metrics.Misses++
+		responseCacheMisses.With().Inc()
 		rc.metrics.LastActivity = time.Now()
 		return nil
 	}
@@ -252,11 +271,13 @@ func (rc *ResponseCache) get(key string) *CachedResponse {
 	if time.Now().After(cached.ExpiresAt) {
 		delete(rc.cache, key)
 		rc.metrics.Misses++
+		responseCacheMisses.With().Inc()
 		rc.metrics.LastActivity = time.Now()
 		return nil
 	}
 	
 	rc.metrics.Hits++
+	responseCacheHits.With().Inc()
 	rc.metrics.LastActivity = time.Now()
 	return cached
 }
@@ -297,6 +318,7 @@ func (rc *ResponseCache) store(key string, recorder *ResponseRecorder, ttl time.
 	
 	rc.cache[key] = cached
 	rc.metrics.Stores++
+	responseCacheStores.With().Inc()
 	rc.metrics.TotalSize = len(rc.cache)
 	rc.metrics.LastActivity = time.Now()
 }
@@ -318,6 +340,7 @@ func (rc *ResponseCache) evictOldest() {
 	if oldestKey != "" {
 		delete(rc.cache, oldestKey)
 		rc.metrics.Evictions++
+		responseCacheEvictions.With().Inc()
 	}
 }
 
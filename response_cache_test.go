@@ -279,4 +279,38 @@ func TestResponseCache_CacheInfo(t *testing.T) {
 	if info["hit_rate"].(float64) != 0 {
 		t.Error("Initial hit rate should be 0")
 	}
-}
\ No newline at end of file
+}
+
+func TestResponseCacheMiddleware_StreamingNotCached(t *testing.T) {
+	app := New()
+	
+	app.UseMiddleware(ResponseCacheMiddleware())
+	
+	callCount := 0
+	app.GetHandler("/stream-events", func(c Context) error {
+		callCount++
+		stream, err := c.SSE()
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return stream.Data(fmt.Sprintf("event %d", callCount))
+	})
+	
+	for i := 1; i <= 2; i++ {
+		req := httptest.NewRequest("GET", "/stream-events", nil)
+		w := httptest.NewRecorder()
+		app.Router().ServeHTTP(w, req)
+		
+		if !w.Flushed {
+			t.Error("Streaming response should be flushed through the cache recorder")
+		}
+		if w.Header().Get("X-Cache") == "HIT" {
+			t.Error("Streaming response should never be served from cache")
+		}
+	}
+	
+	if callCount != 2 {
+		t.Errorf("Expected handler to be called twice, was called %d times", callCount)
+	}
+}
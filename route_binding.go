@@ -0,0 +1,93 @@
+package onyx
+
+import (
+	"fmt"
+	"reflect"
+	"unicode"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	routerImpl "github.com/onyx-go/framework/internal/http/router"
+)
+
+// ModelBinder returns a route binder that loads a model through db by the
+// parameter value. The lookup column defaults to "id". Soft-deleted rows are
+// excluded unless the route was registered WithTrashed, and a missing row is
+// reported as a 404 HTTPError so the ErrorHandler renders it.
+func ModelBinder(db *DB, prototype Model, column ...string) routerImpl.BinderFunc {
+	key := "id"
+	if len(column) > 0 && column[0] != "" {
+		key = column[0]
+	}
+
+	modelType := reflect.TypeOf(prototype)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	return func(c httpInternal.Context, value string, withTrashed bool) (interface{}, error) {
+		// Load into a slice so columns are matched by name rather than position
+		results := reflect.New(reflect.SliceOf(modelType))
+
+		query := db.Model(prototype).Where(key, "=", value).Limit(1)
+		if withTrashed {
+			query.WithTrashed()
+		}
+
+		if err := query.Get(results.Interface()); err != nil {
+			return nil, err
+		}
+
+		if results.Elem().Len() == 0 {
+			return nil, NotFound(fmt.Sprintf("%s not found", modelType.Name()))
+		}
+
+		model := results.Elem().Index(0).Addr().Interface()
+		if baseModel := findBaseModel(model); baseModel != nil {
+			baseModel.MarkAsExisting()
+		}
+
+		return model, nil
+	}
+}
+
+// findBaseModel returns the embedded BaseModel of a model pointer, if any
+func findBaseModel(model interface{}) *BaseModel {
+	if eventable, ok := model.(EventableModel); ok {
+		return getBaseModel(eventable)
+	}
+	return nil
+}
+
+// Bind registers a custom binder for a route parameter
+func (app *Application) Bind(param string, binder routerImpl.BinderFunc) {
+	app.router.(*routerImpl.Router).Bind(param, binder)
+}
+
+// BindModel resolves the given route parameters to models of the prototype's
+// type through db. Without explicit parameter names the name is derived from
+// the model type, so *User binds {user} and *BlogPost binds {blogPost}.
+// Handlers read the model with c.Get(param).
+func (app *Application) BindModel(db *DB, prototype Model, params ...string) {
+	if len(params) == 0 {
+		params = []string{modelParamName(prototype)}
+	}
+
+	binder := ModelBinder(db, prototype)
+	for _, param := range params {
+		app.Bind(param, binder)
+	}
+}
+
+// modelParamName derives the default route parameter name for a model type
+func modelParamName(prototype Model) string {
+	modelType := reflect.TypeOf(prototype)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	name := []rune(modelType.Name())
+	if len(name) > 0 {
+		name[0] = unicode.ToLower(name[0])
+	}
+	return string(name)
+}
@@ -0,0 +1,99 @@
+package onyx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// BlogPost model for parameter name derivation
+type BlogPost struct {
+	BaseModel
+	Title string `db:"title" json:"title"`
+}
+
+func (p *BlogPost) TableName() string {
+	return "blog_posts"
+}
+
+func TestModelParamName(t *testing.T) {
+	tests := []struct {
+		model Model
+		want  string
+	}{
+		{&SoftDeleteUser{}, "softDeleteUser"},
+		{&BlogPost{}, "blogPost"},
+	}
+
+	for _, tt := range tests {
+		if got := modelParamName(tt.model); got != tt.want {
+			t.Errorf("modelParamName(%T) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestRouteModelBinding(t *testing.T) {
+	db, cleanup := setupSoftDeleteTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	active := &SoftDeleteUser{Name: "Active", Email: "active@example.com"}
+	if err := CreateModel(ctx, db, active); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	trashed := &SoftDeleteUser{Name: "Trashed", Email: "trashed@example.com"}
+	if err := CreateModel(ctx, db, trashed); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := DeleteModel(ctx, db, trashed); err != nil {
+		t.Fatalf("Failed to soft delete user: %v", err)
+	}
+
+	app := New()
+	app.BindModel(db, &SoftDeleteUser{}, "user")
+
+	handler := func(c Context) error {
+		value, _ := c.Get("user")
+		user, ok := value.(*SoftDeleteUser)
+		if !ok {
+			t.Errorf("Expected *SoftDeleteUser in context, got %T", value)
+			return c.String(http.StatusInternalServerError, "not bound")
+		}
+		return c.String(http.StatusOK, user.Name)
+	}
+
+	app.GetHandler("/users/{user}", handler)
+	app.GetHandler("/admin/users/{user}", handler).WithTrashed()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+	}{
+		{"found", "/users/" + strconv.Itoa(int(active.ID)), http.StatusOK, "Active"},
+		{"missing", "/users/999", http.StatusNotFound, ""},
+		{"soft deleted", "/users/" + strconv.Itoa(int(trashed.ID)), http.StatusNotFound, ""},
+		{"soft deleted with trashed", "/admin/users/" + strconv.Itoa(int(trashed.ID)), http.StatusOK, "Trashed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			app.Router().ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
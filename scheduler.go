@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -51,8 +52,14 @@ type Schedule struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	entryIds    map[string]cron.EntryID
+	heartbeatID cron.EntryID
+	heartbeat   atomic.Int64
 }
 
+// heartbeatInterval is how often a running scheduler records that its loop
+// is alive
+const heartbeatInterval = 10 * time.Second
+
 // Scheduler interface for Laravel-style task scheduling
 type Scheduler interface {
 	// Task registration
@@ -200,6 +207,9 @@ func (s *Schedule) Start() error {
 		}
 	}
 
+	s.beat()
+	s.heartbeatID = s.cron.Schedule(cron.Every(heartbeatInterval), cron.FuncJob(s.beat))
+	
 	s.cron.Start()
 	s.running = true
 
@@ -210,8 +220,16 @@ func (s *Schedule) Start() error {
 	return nil
 }
 
-// Stop the scheduler
+// Stop the scheduler, waiting up to 30 seconds for running jobs
 func (s *Schedule) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+// Shutdown stops the scheduler and waits for running jobs to finish until
+// ctx is done
+func (s *Schedule) Shutdown(ctx context.Context) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -219,18 +237,21 @@ func (s *Schedule) Stop() error {
 		return nil
 	}
 
-	ctx := s.cron.Stop()
+	var err error
+	stopped := s.cron.Stop()
 	select {
-	case <-ctx.Done():
+	case <-stopped.Done():
 		s.logger.Info("Task scheduler stopped gracefully", nil)
-	case <-time.After(30 * time.Second):
+	case <-ctx.Done():
 		s.logger.Warn("Task scheduler stop timeout, forcing shutdown", nil)
+		err = fmt.Errorf("scheduler did not stop: %w", ctx.Err())
 	}
 
+	s.cron.Remove(s.heartbeatID)
 	s.running = false
 	s.cancel()
 
-	return nil
+	return err
 }
 
 // IsRunning returns whether the scheduler is running
@@ -240,6 +261,21 @@ func (s *Schedule) IsRunning() bool {
 	return s.running
 }
 
+// LastHeartbeat returns when the running scheduler last recorded that its
+// loop is alive, or the zero time if it never ran. It is refreshed every ten
+// seconds.
+func (s *Schedule) LastHeartbeat() time.Time {
+	if nanos := s.heartbeat.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// beat records a heartbeat
+func (s *Schedule) beat() {
+	s.heartbeat.Store(time.Now().UnixNano())
+}
+
 // GetJobs returns all scheduled jobs
 func (s *Schedule) GetJobs() map[string]*ScheduledJob {
 	s.mutex.RLock()
@@ -0,0 +1,280 @@
+package onyx
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TLSConfig controls HTTPS serving. It is read from the app.tls section of
+// the configuration or set through SetServerConfig, StartTLS and StartTLSConfig.
+type TLSConfig struct {
+	Enabled bool
+
+	// CertFile and KeyFile are PEM files. They are watched for changes and
+	// reloaded without a restart, so renewed certificates are picked up.
+	CertFile string
+	KeyFile  string
+
+	// ReloadInterval is how often the certificate files are checked for
+	// changes. Zero disables reloading.
+	ReloadInterval time.Duration
+
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS12
+	MinVersion uint16
+
+	// HTTP2 enables HTTP/2 over TLS. It is on by default.
+	HTTP2 bool
+
+	// RedirectAddress, when set, starts a plain HTTP listener on that
+	// address which permanently redirects every request to HTTPS
+	RedirectAddress string
+
+	// Config is used as the base TLS configuration when set. Certificates
+	// from CertFile and KeyFile take precedence over its own certificates.
+	Config *tls.Config
+}
+
+// ErrNoCertificate is returned when TLS is enabled without any certificate source
+var ErrNoCertificate = errors.New("tls enabled but no certificate configured")
+
+// tlsVersions maps configuration values to TLS protocol versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsConfigFromConfig reads the app.tls section, falling back to the defaults
+func tlsConfigFromConfig(config *Config, defaults TLSConfig) TLSConfig {
+	minVersion := defaults.MinVersion
+	if version, ok := tlsVersions[config.GetString("app.tls.min_version")]; ok {
+		minVersion = version
+	}
+
+	return TLSConfig{
+		Enabled:         config.GetBool("app.tls.enabled", defaults.Enabled),
+		CertFile:        config.GetString("app.tls.cert_file", defaults.CertFile),
+		KeyFile:         config.GetString("app.tls.key_file", defaults.KeyFile),
+		ReloadInterval:  config.GetDuration("app.tls.reload_interval", defaults.ReloadInterval),
+		MinVersion:      minVersion,
+		HTTP2:           config.GetBool("app.tls.http2", defaults.HTTP2),
+		RedirectAddress: config.GetString("app.tls.redirect_address", defaults.RedirectAddress),
+	}
+}
+
+// StartTLS serves HTTPS on address using the given certificate and key files
+func (app *Application) StartTLS(address, certFile, keyFile string) error {
+	config := app.ServerConfig()
+	config.TLS.Enabled = true
+	config.TLS.CertFile = certFile
+	config.TLS.KeyFile = keyFile
+	app.SetServerConfig(config)
+
+	return app.Start(address)
+}
+
+// StartTLSConfig serves HTTPS on address using a prepared tls.Config
+func (app *Application) StartTLSConfig(address string, tlsConfig *tls.Config) error {
+	config := app.ServerConfig()
+	config.TLS.Enabled = true
+	config.TLS.CertFile = ""
+	config.TLS.KeyFile = ""
+	config.TLS.Config = tlsConfig
+	app.SetServerConfig(config)
+
+	return app.Start(address)
+}
+
+// serverProtocols returns the protocols the server accepts
+func serverProtocols(config ServerConfig) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+
+	if config.TLS.Enabled {
+		protocols.SetHTTP2(config.TLS.HTTP2)
+	} else {
+		// h2c serves HTTP/2 with prior knowledge on a plain listener, for
+		// deployments behind a proxy that terminates TLS
+		protocols.SetUnencryptedHTTP2(config.H2C)
+	}
+
+	return protocols
+}
+
+// buildTLSConfig prepares the server TLS configuration and, when certificate
+// files are used, the reloader that serves them
+func buildTLSConfig(config TLSConfig) (*tls.Config, *certReloader, error) {
+	tlsConfig := &tls.Config{}
+	if config.Config != nil {
+		tlsConfig = config.Config.Clone()
+	}
+
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = config.MinVersion
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		reloader, err := newCertReloader(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig.Certificates = nil
+		tlsConfig.GetCertificate = reloader.GetCertificate
+		return tlsConfig, reloader, nil
+	}
+
+	if len(tlsConfig.Certificates) == 0 && tlsConfig.GetCertificate == nil && tlsConfig.GetConfigForClient == nil {
+		return nil, nil, ErrNoCertificate
+	}
+
+	return tlsConfig, nil, nil
+}
+
+// httpsRedirectHandler redirects every request to the HTTPS listener on httpsPort
+func httpsRedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			// An IPv6 literal without a port keeps its brackets
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		}
+
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// certReloader serves a certificate loaded from disk and reloads it when
+// the certificate or key file changes
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex   sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	cr := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		stop:     make(chan struct{}),
+	}
+
+	modTime, err := cr.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := cr.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (cr *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+	return cr.cert, nil
+}
+
+// Reload reloads the certificate if either file changed since the last load.
+// On failure the current certificate stays in use.
+func (cr *certReloader) Reload() (bool, error) {
+	modTime, err := cr.latestModTime()
+	if err != nil {
+		return false, err
+	}
+
+	cr.mutex.RLock()
+	unchanged := !modTime.After(cr.modTime)
+	cr.mutex.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	if err := cr.load(modTime); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// watch polls the certificate files until Close is called
+func (cr *certReloader) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := cr.Reload()
+			if err != nil {
+				Error("Failed to reload TLS certificate", map[string]interface{}{
+					"cert_file": cr.certFile,
+					"error":     err.Error(),
+				})
+			} else if reloaded {
+				Info("Reloaded TLS certificate", map[string]interface{}{
+					"cert_file": cr.certFile,
+				})
+			}
+		case <-cr.stop:
+			return
+		}
+	}
+}
+
+// Close stops watching the certificate files
+func (cr *certReloader) Close() error {
+	cr.once.Do(func() { close(cr.stop) })
+	return nil
+}
+
+func (cr *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	cr.mutex.Lock()
+	cr.cert = &cert
+	cr.modTime = modTime
+	cr.mutex.Unlock()
+
+	return nil
+}
+
+func (cr *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read TLS certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
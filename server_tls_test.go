@@ -0,0 +1,278 @@
+package onyx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate for localhost and
+// returns the certificate and key paths
+func writeTestCertificate(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestTLSConfigFromConfig(t *testing.T) {
+	app := New()
+	app.Config().Set("app", map[string]interface{}{
+		"server": map[string]interface{}{
+			"h2c": true,
+		},
+		"tls": map[string]interface{}{
+			"enabled":          true,
+			"cert_file":        "cert.pem",
+			"key_file":         "key.pem",
+			"min_version":      "1.3",
+			"http2":            false,
+			"redirect_address": ":80",
+		},
+	})
+
+	config := app.ServerConfig()
+	if !config.H2C {
+		t.Error("Expected h2c to be enabled")
+	}
+
+	tlsConfig := config.TLS
+	if !tlsConfig.Enabled || tlsConfig.CertFile != "cert.pem" || tlsConfig.KeyFile != "key.pem" {
+		t.Errorf("Unexpected TLS files: %+v", tlsConfig)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3 minimum, got %x", tlsConfig.MinVersion)
+	}
+	if tlsConfig.HTTP2 {
+		t.Error("Expected HTTP/2 to be disabled")
+	}
+	if tlsConfig.RedirectAddress != ":80" {
+		t.Errorf("Expected redirect address :80, got %q", tlsConfig.RedirectAddress)
+	}
+	if tlsConfig.ReloadInterval != DefaultServerConfig().TLS.ReloadInterval {
+		t.Errorf("Expected default reload interval, got %v", tlsConfig.ReloadInterval)
+	}
+}
+
+func TestBuildTLSConfigRequiresCertificate(t *testing.T) {
+	if _, _, err := buildTLSConfig(TLSConfig{Enabled: true}); !errors.Is(err, ErrNoCertificate) {
+		t.Errorf("Expected ErrNoCertificate, got %v", err)
+	}
+
+	provided := &tls.Config{Certificates: []tls.Certificate{{}}}
+	config, reloader, err := buildTLSConfig(TLSConfig{Enabled: true, MinVersion: tls.VersionTLS12, Config: provided})
+	if err != nil {
+		t.Fatalf("Expected tls.Config certificates to be accepted, got %v", err)
+	}
+	if reloader != nil {
+		t.Error("Expected no reloader without certificate files")
+	}
+	if config == provided {
+		t.Error("Expected the provided tls.Config to be cloned")
+	}
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected minimum version to be applied, got %x", config.MinVersion)
+	}
+}
+
+func TestApplicationServesHTTP2OverTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir(), "onyx")
+
+	app := New()
+	app.GetHandler("/proto", func(c Context) error {
+		return c.String(http.StatusOK, c.Request().Proto)
+	})
+
+	config := DefaultServerConfig()
+	config.TLS.Enabled = true
+	config.TLS.CertFile = certFile
+	config.TLS.KeyFile = keyFile
+	config.TLS.RedirectAddress = "127.0.0.1:0"
+	app.SetServerConfig(config)
+
+	listener, err := app.listen(context.Background(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	go app.serve(listener)
+	defer app.Shutdown(context.Background())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		},
+	}
+
+	resp, err := client.Get("https://" + listener.Addr().String() + "/proto")
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2, got %s", resp.Proto)
+	}
+	if app.redirectServer == nil {
+		t.Error("Expected the redirect listener to be started")
+	}
+}
+
+func TestApplicationServesH2C(t *testing.T) {
+	app := New()
+	app.GetHandler("/proto", func(c Context) error {
+		return c.String(http.StatusOK, c.Request().Proto)
+	})
+
+	config := DefaultServerConfig()
+	config.H2C = true
+	app.SetServerConfig(config)
+
+	listener, err := app.listen(context.Background(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	go app.serve(listener)
+	defer app.Shutdown(context.Background())
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	resp, err := client.Get("http://" + listener.Addr().String() + "/proto")
+	if err != nil {
+		t.Fatalf("h2c request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2 with prior knowledge, got %s", resp.Proto)
+	}
+}
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		host     string
+		target   string
+		expected string
+	}{
+		{"default port", "443", "example.com", "/a?b=c", "https://example.com/a?b=c"},
+		{"strips http port", "443", "example.com:80", "/", "https://example.com/"},
+		{"custom port", "8443", "example.com:8080", "/x", "https://example.com:8443/x"},
+		{"ipv6", "443", "[::1]:80", "/", "https://[::1]/"},
+		{"ipv6 without port", "443", "[::1]", "/", "https://[::1]/"},
+		{"ipv6 without port to custom port", "8443", "[::1]", "/", "https://[::1]:8443/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+
+			httpsRedirectHandler(tt.port).ServeHTTP(w, req)
+
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("Expected status 308, got %d", w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.expected {
+				t.Errorf("Expected Location %q, got %q", tt.expected, location)
+			}
+		})
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "first")
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	defer reloader.Close()
+
+	reloaded, err := reloader.Reload()
+	if err != nil || reloaded {
+		t.Errorf("Expected no reload for unchanged files, got %v, %v", reloaded, err)
+	}
+
+	writeTestCertificate(t, dir, "second")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+
+	reloaded, err = reloader.Reload()
+	if err != nil || !reloaded {
+		t.Fatalf("Expected certificate to be reloaded, got %v, %v", reloaded, err)
+	}
+
+	cert, _ := reloader.GetCertificate(nil)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	if leaf.Subject.CommonName != "second" {
+		t.Errorf("Expected reloaded certificate, got %q", leaf.Subject.CommonName)
+	}
+
+	// A broken file keeps the current certificate in use
+	os.WriteFile(certFile, []byte("garbage"), 0600)
+	later = later.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+
+	if _, err := reloader.Reload(); err == nil {
+		t.Error("Expected an error for an invalid certificate")
+	}
+	if current, _ := reloader.GetCertificate(nil); current != cert {
+		t.Error("Expected the previous certificate to stay in use")
+	}
+}
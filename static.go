@@ -0,0 +1,27 @@
+package onyx
+
+import (
+	"io/fs"
+
+	httpInternal "github.com/onyx-go/framework/internal/http"
+)
+
+// Static file types re-exported for application code
+type (
+	StaticOptions = httpInternal.StaticOptions
+	StaticOption  = httpInternal.StaticOption
+	CacheRule     = httpInternal.CacheRule
+)
+
+// Static file options re-exported for application code
+var (
+	WithCacheControl     = httpInternal.WithCacheControl
+	WithIndexFile        = httpInternal.WithIndexFile
+	WithoutPrecompressed = httpInternal.WithoutPrecompressed
+)
+
+// Static serves the files of fsys below prefix, such as os.DirFS("public")
+// or an embed.FS
+func (app *Application) Static(prefix string, fsys fs.FS, options ...StaticOption) httpInternal.RouteDefinition {
+	return app.router.Static(prefix, fsys, options...)
+}
@@ -1,6 +1,7 @@
 package onyx
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -42,6 +43,11 @@ func NewLocalStorage(root string) *LocalStorage {
 	return &LocalStorage{root: root}
 }
 
+// Root returns the directory files are stored under
+func (ls *LocalStorage) Root() string {
+	return ls.root
+}
+
 func (ls *LocalStorage) Put(path string, contents []byte) error {
 	fullPath := ls.fullPath(path)
 	
@@ -276,6 +282,19 @@ func (sm *StorageManager) SetDefaultDisk(name string) {
 	sm.default_ = name
 }
 
+// Close closes every disk that holds resources, such as remote connections
+func (sm *StorageManager) Close() error {
+	var errs []error
+	for name, disk := range sm.disks {
+		if closer, ok := disk.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("storage disk %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 type UploadedFile struct {
 	Header   *multipart.FileHeader
 	Size     int64
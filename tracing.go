@@ -0,0 +1,76 @@
+package onyx
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/onyx-go/framework/internal/tracing"
+)
+
+// SpanContext is the W3C trace context of a request or job, re-exported for
+// application code
+type SpanContext = tracing.SpanContext
+
+// Request ID and trace context helpers re-exported for application code.
+// Every request handled by the application carries both on
+// c.Request().Context(); pass that context on to queue, mail and HTTP calls
+// to link them to the request.
+var (
+	RequestIDFrom    = tracing.RequestID
+	SpanContextFrom  = tracing.SpanContextFrom
+	WithRequestID    = tracing.WithRequestID
+	WithSpanContext  = tracing.WithSpanContext
+	ParseTraceparent = tracing.ParseTraceparent
+)
+
+// Span recording and export, re-exported for application code. Install a
+// tracer once at startup and shut it down before exiting so buffered spans
+// are exported:
+//
+//	exporter, err := onyx.NewOTLPExporter(onyx.OTLPConfig{ServiceName: "shop"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	tracer := onyx.NewTracer(exporter)
+//	onyx.SetTracer(tracer)
+//	defer tracer.Shutdown(context.Background())
+//
+// Requests, queries, cache and storage calls, queued jobs and mail then
+// record spans on their own; StartSpan adds spans of your own.
+type (
+	Tracer           = tracing.Tracer
+	Span             = tracing.Span
+	SpanData         = tracing.SpanData
+	SpanExporter     = tracing.Exporter
+	OTLPConfig       = tracing.OTLPConfig
+	OTLPExporter     = tracing.OTLPExporter
+	InMemoryExporter = tracing.InMemoryExporter
+)
+
+var (
+	NewTracer           = tracing.NewTracer
+	NewOTLPExporter     = tracing.NewOTLPExporter
+	NewInMemoryExporter = tracing.NewInMemoryExporter
+	SetTracer           = tracing.SetTracer
+	GetTracer           = tracing.GetTracer
+	StartSpan           = tracing.Start
+
+	WithSampleRatio   = tracing.WithSampleRatio
+	WithBatchSize     = tracing.WithBatchSize
+	WithFlushInterval = tracing.WithFlushInterval
+	WithErrorHandler  = tracing.WithErrorHandler
+)
+
+// TracingTransport wraps base, or http.DefaultTransport when nil, so that
+// outgoing requests forward the X-Request-ID, traceparent and tracestate of
+// their context
+func TracingTransport(base http.RoundTripper) http.RoundTripper {
+	return &tracing.Transport{Base: base}
+}
+
+// NewHTTPClient returns an http.Client with the given timeout that forwards
+// the request ID and trace context of each request's context. Build requests
+// with http.NewRequestWithContext(c.Request().Context(), ...).
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: TracingTransport(nil)}
+}
@@ -0,0 +1,179 @@
+package onyx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTracePropagation(t *testing.T) {
+	var forwarded http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	app := New()
+	client := NewHTTPClient(5 * time.Second)
+
+	app.GetHandler("/call", func(c Context) error {
+		req, err := http.NewRequestWithContext(c.Request().Context(), "GET", upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return c.String(http.StatusOK, c.RequestID())
+	})
+
+	req := httptest.NewRequest("GET", "/call", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	app.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "req-123" {
+		t.Fatalf("Expected the incoming request ID, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Request-ID") != "req-123" {
+		t.Errorf("Expected X-Request-ID to be echoed, got %q", w.Header().Get("X-Request-ID"))
+	}
+	if forwarded.Get("X-Request-ID") != "req-123" {
+		t.Errorf("Expected X-Request-ID to be forwarded, got %q", forwarded.Get("X-Request-ID"))
+	}
+
+	sc, ok := ParseTraceparent(forwarded.Get("traceparent"))
+	if !ok || sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID == "00f067aa0ba902b7" {
+		t.Errorf("Expected the request's own span in the same trace, got %q", forwarded.Get("traceparent"))
+	}
+}
+
+func TestJobAndMailTracePropagation(t *testing.T) {
+	app := New()
+	previous := globalApp
+	app.SetGlobal()
+	defer func() { globalApp = previous }()
+
+	manager := NewQueueManager()
+	app.Container().Instance("queue", manager)
+
+	mailManager := NewMailManager(&MailConfig{DefaultMailer: "mock"})
+	mailDriver := NewMockMailDriver("mock")
+	mailManager.RegisterDriver("mock", mailDriver)
+
+	app.GetHandler("/order", func(c Context) error {
+		job := NewBaseJob()
+		job.payload["handler"] = func(ctx context.Context) error {
+			// The worker hands the job the dispatching request's trace
+			return mailManager.SendContext(ctx, NewMail().To("a@example.com", "").Subject("Order"))
+		}
+		if err := DispatchJobWithContext(c, job); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "queued")
+	})
+
+	req := httptest.NewRequest("GET", "/order", nil)
+	req.Header.Set("X-Request-ID", "req-456")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	app.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+	job, err := manager.Connection().Pop()
+	if err != nil {
+		t.Fatalf("Expected a queued job, got %v", err)
+	}
+	metadata := job.(MetadataJob).GetMetadata()
+	if metadata["request_id"] != "req-456" {
+		t.Errorf("Expected the request ID in the job metadata, got %v", metadata)
+	}
+
+	worker := NewQueueWorker()
+	if !worker.processJob(job) {
+		t.Fatal("Expected the job to succeed")
+	}
+
+	sent := mailDriver.GetSentMessages()
+	if len(sent) != 1 {
+		t.Fatalf("Expected one message, got %d", len(sent))
+	}
+	headers := sent[0].Envelope.Headers
+	if headers["X-Request-ID"] != "req-456" {
+		t.Errorf("Expected the request ID on the message, got %v", headers)
+	}
+	sc, ok := ParseTraceparent(headers["Traceparent"])
+	if !ok || sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the message to continue the request's trace, got %q", headers["Traceparent"])
+	}
+}
+
+func TestCacheQueueAndMailSpans(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	app := New()
+	previous := globalApp
+	app.SetGlobal()
+	defer func() { globalApp = previous }()
+	manager := NewQueueManager()
+	app.Container().Instance("queue", manager)
+
+	mailManager := NewMailManager(&MailConfig{DefaultMailer: "mock"})
+	mailManager.RegisterDriver("mock", NewMockMailDriver("mock"))
+
+	ctx, parent := StartSpan(context.Background(), "request")
+	memory := NewMemoryCache()
+	defer memory.Close()
+	memory.GetContext(ctx, "user:1")
+	memory.PutContext(ctx, "user:1", "alice", time.Minute)
+	memory.GetContext(ctx, "user:1")
+	files := NewFileCache(t.TempDir())
+	defer files.Close()
+	files.PutContext(ctx, "user:2", "bob", time.Minute)
+
+	job := NewBaseJob()
+	job.payload["handler"] = func(ctx context.Context) error {
+		return mailManager.SendContext(ctx, NewMail().To("a@example.com", "").Subject("Hi"))
+	}
+	if err := dispatch(ctx, job.GetQueue(), job, func(qm QueueManager) error { return qm.Push(job) }); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+	parent.End()
+
+	queued, err := manager.Connection().Pop()
+	if err != nil {
+		t.Fatalf("Expected a queued job, got %v", err)
+	}
+	if !NewQueueWorker().processJob(queued) {
+		t.Fatal("Expected the job to succeed")
+	}
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	spans := make(map[string][]SpanData)
+	for _, span := range exporter.Spans() {
+		spans[span.Name] = append(spans[span.Name], span)
+		if span.SpanContext.TraceID != parent.SpanContext().TraceID {
+			t.Errorf("Expected %s to belong to the request's trace", span.Name)
+		}
+	}
+
+	gets := spans["cache.get"]
+	if len(gets) != 2 || gets[0].Attributes["cache.hit"] != false || gets[1].Attributes["cache.hit"] != true {
+		t.Errorf("Expected a cache miss and a hit, got %+v", gets)
+	}
+	if puts := spans["cache.put"]; len(puts) != 2 || puts[1].Attributes["cache.store"] != "file" {
+		t.Errorf("Expected memory and file cache puts, got %+v", puts)
+	}
+	if len(spans["default publish"]) != 1 || len(spans["default process"]) != 1 || len(spans["mail.send"]) != 1 {
+		t.Errorf("Expected publish, process and mail spans, got %v", exporter.Spans())
+	}
+}
@@ -0,0 +1,35 @@
+package onyx
+
+import (
+	routerImpl "github.com/onyx-go/framework/internal/http/router"
+	"github.com/onyx-go/framework/internal/storage"
+)
+
+// Upload types re-exported for application code
+type (
+	StorageDriver  = storage.Driver
+	FileUploader   = storage.DefaultUploader
+	UploadFile     = storage.UploadedFile
+	StoredFileInfo = storage.FileInfo
+	UploadOption   = storage.UploadOption
+)
+
+// Upload errors re-exported for application code
+var (
+	ErrFileTooLarge        = storage.ErrFileTooLarge
+	ErrFileTypeNotAllowed  = storage.ErrFileTypeNotAllowed
+	ErrExtensionNotAllowed = storage.ErrExtensionNotAllowed
+	ErrContentMismatch     = storage.ErrContentMismatch
+)
+
+// NewUploader creates an uploader that validates files and stores them
+// through driver
+func NewUploader(driver StorageDriver) *FileUploader {
+	return storage.NewUploader(driver)
+}
+
+// SetMaxMultipartMemory sets how much of a multipart form each request keeps
+// in memory before file parts spill to temporary files
+func (app *Application) SetMaxMultipartMemory(bytes int64) {
+	app.router.(*routerImpl.Router).SetMaxMultipartMemory(bytes)
+}
@@ -0,0 +1,60 @@
+package onyx
+
+import (
+	httpInternal "github.com/onyx-go/framework/internal/http"
+	contextImpl "github.com/onyx-go/framework/internal/http/context"
+	routerImpl "github.com/onyx-go/framework/internal/http/router"
+	"github.com/onyx-go/framework/internal/http/websocket"
+)
+
+// WebSocket types re-exported for application code
+type (
+	WebSocketConn       = websocket.Conn
+	WebSocketConfig     = websocket.Config
+	WebSocketCloseError = websocket.CloseError
+	WebSocketMessage    = websocket.MessageType
+)
+
+const (
+	WebSocketText   = websocket.TextMessage
+	WebSocketBinary = websocket.BinaryMessage
+)
+
+// WebSocketHandler handles an upgraded WebSocket connection
+type WebSocketHandler func(c Context, conn *WebSocketConn) error
+
+// DefaultWebSocketConfig returns the default upgrade and connection settings
+func DefaultWebSocketConfig() WebSocketConfig {
+	return websocket.DefaultConfig()
+}
+
+func convertWebSocketHandler(handler WebSocketHandler) httpInternal.WebSocketHandler {
+	return func(c httpInternal.Context, conn *websocket.Conn) error {
+		return handler(c.(*contextImpl.Context), conn)
+	}
+}
+
+// WebSocket registers a WebSocket endpoint; middleware runs before the upgrade
+func (r *Router) WebSocket(pattern string, handler WebSocketHandler, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return r.WS(pattern, convertWebSocketHandler(handler), convertMiddleware(middleware...)...)
+}
+
+// WebSocket registers a WebSocket endpoint in the group
+func (rg *RouteGroup) WebSocket(pattern string, handler WebSocketHandler, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return rg.RouteGroup.WS(pattern, convertWebSocketHandler(handler), convertMiddleware(middleware...)...)
+}
+
+// WS registers a WebSocket endpoint; middleware runs before the upgrade
+func (app *Application) WS(pattern string, handler httpInternal.WebSocketHandler, middleware ...httpInternal.MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.WS(pattern, handler, middleware...)
+}
+
+// WebSocket registers a WebSocket endpoint using the application's handler types
+func (app *Application) WebSocket(pattern string, handler WebSocketHandler, middleware ...MiddlewareFunc) httpInternal.RouteDefinition {
+	return app.router.WS(pattern, convertWebSocketHandler(handler), app.convertMiddleware(middleware...)...)
+}
+
+// SetWebSocketConfig sets the upgrade and connection settings for WebSocket routes
+func (app *Application) SetWebSocketConfig(config WebSocketConfig) {
+	app.router.(*routerImpl.Router).SetWebSocketConfig(config)
+}